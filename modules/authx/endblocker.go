@@ -25,12 +25,12 @@ func EndBlocker(ctx sdk.Context, aux AccountXKeeper, keeper ExpectedAccountKeepe
 }
 
 type NotificationUnlock struct {
-	Address     sdk.AccAddress `json:"address" yaml:"address"`
-	Unlocked    sdk.Coins      `json:"unlocked"`
-	LockedCoins LockedCoins    `json:"locked_coins"`
-	FrozenCoins sdk.Coins      `json:"frozen_coins"`
-	Coins       sdk.Coins      `json:"coins" yaml:"coins"`
-	Height      int64          `json:"height"`
+	Address     sdk.AccAddress `json:"address" yaml:"address" pb:"1"`
+	Unlocked    sdk.Coins      `json:"unlocked" pb:"2"`
+	LockedCoins LockedCoins    `json:"locked_coins" pb:"3"`
+	FrozenCoins sdk.Coins      `json:"frozen_coins" pb:"4"`
+	Coins       sdk.Coins      `json:"coins" yaml:"coins" pb:"5"`
+	Height      int64          `json:"height" pb:"6"`
 }
 
 func withdrawUnlockedCoins(accx *AccountX, time int64, ctx sdk.Context, kx AccountXKeeper, keeper ExpectedAccountKeeper, tk ExpectedTokenKeeper) {
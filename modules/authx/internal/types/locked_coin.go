@@ -11,11 +11,11 @@ import (
 // Locked Coin
 
 type LockedCoin struct {
-	Coin        sdk.Coin       `json:"coin"`
-	UnlockTime  int64          `json:"unlock_time"`
-	FromAddress sdk.AccAddress `json:"from_address,omitempty"`
-	Supervisor  sdk.AccAddress `json:"supervisor,omitempty"`
-	Reward      int64          `json:"reward,omitempty"`
+	Coin        sdk.Coin       `json:"coin" pb:"1"`
+	UnlockTime  int64          `json:"unlock_time" pb:"2"`
+	FromAddress sdk.AccAddress `json:"from_address,omitempty" pb:"3"`
+	Supervisor  sdk.AccAddress `json:"supervisor,omitempty" pb:"4"`
+	Reward      int64          `json:"reward,omitempty" pb:"5"`
 }
 
 func NewLockedCoin(denom string, amount sdk.Int, unlockTime int64) LockedCoin {
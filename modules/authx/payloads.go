@@ -0,0 +1,9 @@
+package authx
+
+import (
+	"github.com/coinexchain/cet-sdk/msgqueue"
+)
+
+func init() {
+	msgqueue.RegisterPayload(ModuleName, "notify_unlock", 1, NotificationUnlock{})
+}
@@ -116,19 +116,19 @@ func (bi *BancorInfo) IsConsistent() bool {
 }
 
 type BancorInfoDisplay struct {
-	Owner              string `json:"owner"`
-	Stock              string `json:"stock"`
-	Money              string `json:"money"`
-	InitPrice          string `json:"init_price"`
-	MaxSupply          string `json:"max_supply"`
-	StockPrecision     string `json:"stock_precision"`
-	MaxPrice           string `json:"max_price"`
-	MaxMoney           string `json:"max_money"`
-	AR                 string `json:"ar"`
-	CurrentPrice       string `json:"current_price"`
-	StockInPool        string `json:"stock_in_pool"`
-	MoneyInPool        string `json:"money_in_pool"`
-	EarliestCancelTime int64  `json:"earliest_cancel_time"`
+	Owner              string `json:"owner" pb:"1"`
+	Stock              string `json:"stock" pb:"2"`
+	Money              string `json:"money" pb:"3"`
+	InitPrice          string `json:"init_price" pb:"4"`
+	MaxSupply          string `json:"max_supply" pb:"5"`
+	StockPrecision     string `json:"stock_precision" pb:"6"`
+	MaxPrice           string `json:"max_price" pb:"7"`
+	MaxMoney           string `json:"max_money" pb:"8"`
+	AR                 string `json:"ar" pb:"9"`
+	CurrentPrice       string `json:"current_price" pb:"10"`
+	StockInPool        string `json:"stock_in_pool" pb:"11"`
+	MoneyInPool        string `json:"money_in_pool" pb:"12"`
+	EarliestCancelTime int64  `json:"earliest_cancel_time" pb:"13"`
 }
 
 func NewBancorInfoDisplay(bi *BancorInfo) BancorInfoDisplay {
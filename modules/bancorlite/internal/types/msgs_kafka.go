@@ -4,7 +4,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
-//kafka msg
+// kafka msg
 type MsgBancorCreateForKafka struct {
 	Owner              sdk.AccAddress `json:"owner"`
 	Stock              string         `json:"stock"`
@@ -31,17 +31,17 @@ type MsgBancorInfoForKafka struct {
 }
 
 type MsgBancorTradeInfoForKafka struct {
-	Sender            sdk.AccAddress `json:"sender"`
-	Stock             string         `json:"stock"`
-	Money             string         `json:"money"`
-	Amount            int64          `json:"amount"`
-	Side              byte           `json:"side"`
-	MoneyLimit        int64          `json:"money_limit"`
-	TxPrice           sdk.Dec        `json:"transaction_price"`
-	UsedCommission    int64          `json:"used_commission"`
-	RebateAmount      int64          `json:"rebate_amount"`
-	RebateRefereeAddr sdk.AccAddress `json:"rebate_referee_addr"`
-	BlockHeight       int64          `json:"block_height"`
+	Sender            sdk.AccAddress `json:"sender" pb:"1"`
+	Stock             string         `json:"stock" pb:"2"`
+	Money             string         `json:"money" pb:"3"`
+	Amount            int64          `json:"amount" pb:"4"`
+	Side              byte           `json:"side" pb:"5"`
+	MoneyLimit        int64          `json:"money_limit" pb:"6"`
+	TxPrice           sdk.Dec        `json:"transaction_price" pb:"7"`
+	UsedCommission    int64          `json:"used_commission" pb:"8"`
+	RebateAmount      int64          `json:"rebate_amount" pb:"9"`
+	RebateRefereeAddr sdk.AccAddress `json:"rebate_referee_addr" pb:"10"`
+	BlockHeight       int64          `json:"block_height" pb:"11"`
 }
 
 type MsgBancorCancelForKafka struct {
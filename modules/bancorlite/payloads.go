@@ -0,0 +1,13 @@
+package bancorlite
+
+import (
+	"github.com/coinexchain/cet-sdk/modules/bancorlite/internal/keepers"
+	"github.com/coinexchain/cet-sdk/modules/bancorlite/internal/types"
+	"github.com/coinexchain/cet-sdk/msgqueue"
+)
+
+func init() {
+	msgqueue.RegisterPayload(ModuleName, KafkaBancorCreate, 1, keepers.BancorInfoDisplay{})
+	msgqueue.RegisterPayload(ModuleName, KafkaBancorTrade, 1, types.MsgBancorTradeInfoForKafka{})
+	msgqueue.RegisterPayload(ModuleName, KafkaBancorInfo, 1, keepers.BancorInfoDisplay{})
+}
@@ -5,12 +5,12 @@ import (
 )
 
 type LockedSendMsg struct {
-	FromAddress sdk.AccAddress `json:"from_address"`
-	ToAddress   sdk.AccAddress `json:"to_address"`
-	Amount      sdk.Coins      `json:"amount"`
-	UnlockTime  int64          `json:"unlock_time"`
-	Supervisor  sdk.AccAddress `json:"supervisor,omitempty"`
-	Reward      int64          `json:"reward,omitempty"`
+	FromAddress sdk.AccAddress `json:"from_address" pb:"1"`
+	ToAddress   sdk.AccAddress `json:"to_address" pb:"2"`
+	Amount      sdk.Coins      `json:"amount" pb:"3"`
+	UnlockTime  int64          `json:"unlock_time" pb:"4"`
+	Supervisor  sdk.AccAddress `json:"supervisor,omitempty" pb:"5"`
+	Reward      int64          `json:"reward,omitempty" pb:"6"`
 }
 
 func NewLockedSendMsg(fromAddr sdk.AccAddress, toAddr sdk.AccAddress, amount sdk.Coins, unlockTime int64) LockedSendMsg {
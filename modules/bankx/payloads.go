@@ -0,0 +1,11 @@
+package bankx
+
+import (
+	"github.com/coinexchain/cet-sdk/modules/bankx/internal/types"
+	"github.com/coinexchain/cet-sdk/msgqueue"
+)
+
+// "notify_unlock" is also sent by bankx, but it is registered by authx who owns its type
+func init() {
+	msgqueue.RegisterPayload(ModuleName, "send_lock_coins", 1, types.LockedSendMsg{})
+}
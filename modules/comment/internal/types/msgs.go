@@ -97,11 +97,11 @@ func ParseAttitude(a string) int32 {
 }
 
 type CommentRef struct {
-	ID           uint64         `json:"id"`
-	RewardTarget sdk.AccAddress `json:"reward_target"`
-	RewardToken  string         `json:"reward_token"`
-	RewardAmount int64          `json:"reward_amount"`
-	Attitudes    []int32        `json:"attitudes"`
+	ID           uint64         `json:"id" pb:"1"`
+	RewardTarget sdk.AccAddress `json:"reward_target" pb:"2"`
+	RewardToken  string         `json:"reward_token" pb:"3"`
+	RewardAmount int64          `json:"reward_amount" pb:"4"`
+	Attitudes    []int32        `json:"attitudes" pb:"5"`
 }
 
 type MsgCommentToken struct {
@@ -115,15 +115,15 @@ type MsgCommentToken struct {
 }
 
 type TokenComment struct {
-	ID          uint64         `json:"id"`
-	Height      int64          `json:"height"`
-	Sender      sdk.AccAddress `json:"sender"`
-	Token       string         `json:"token"`
-	Donation    int64          `json:"donation"`
-	Title       string         `json:"title"`
-	Content     string         `json:"content"`
-	ContentType int8           `json:"content_type"`
-	References  []CommentRef   `json:"references"`
+	ID          uint64         `json:"id" pb:"1"`
+	Height      int64          `json:"height" pb:"2"`
+	Sender      sdk.AccAddress `json:"sender" pb:"3"`
+	Token       string         `json:"token" pb:"4"`
+	Donation    int64          `json:"donation" pb:"5"`
+	Title       string         `json:"title" pb:"6"`
+	Content     string         `json:"content" pb:"7"`
+	ContentType int8           `json:"content_type" pb:"8"`
+	References  []CommentRef   `json:"references" pb:"9"`
 }
 
 func NewMsgCommentToken(
@@ -0,0 +1,10 @@
+package comment
+
+import (
+	"github.com/coinexchain/cet-sdk/modules/comment/internal/types"
+	"github.com/coinexchain/cet-sdk/msgqueue"
+)
+
+func init() {
+	msgqueue.RegisterPayload(ModuleName, types.TokenCommentKey, 1, types.TokenComment{})
+}
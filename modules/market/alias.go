@@ -15,17 +15,36 @@ const (
 	IntegrationNetSubString = types.IntegrationNetSubString
 	OrderIDPartsNum         = types.OrderIDPartsNum
 	SymbolSeparator         = types.SymbolSeparator
+	MarketOrder             = types.MarketOrder
 	LimitOrder              = types.LimitOrder
+	StopLimitOrder          = types.StopLimitOrder
+	StopMarketOrder         = types.StopMarketOrder
 	GTE                     = types.GTE
+	PostOnly                = types.PostOnly
+	FOK                     = types.FOK
 	BID                     = types.BID
 	ASK                     = types.ASK
 	BUY                     = types.BUY
 	SELL                    = types.SELL
+	CallAuction             = types.CallAuction
+	ContinuousMatching      = types.ContinuousMatching
+	ProRataMatching         = types.ProRataMatching
+	MarketOpen              = types.MarketOpen
+	MarketHalted            = types.MarketHalted
+	MarketCancelOnly        = types.MarketCancelOnly
+	MarketAuctionOnly       = types.MarketAuctionOnly
+
+	SelfTradeAllowed            = types.SelfTradeAllowed
+	SelfTradeCancelNewest       = types.SelfTradeCancelNewest
+	SelfTradeCancelOldest       = types.SelfTradeCancelOldest
+	SelfTradeCancelBoth         = types.SelfTradeCancelBoth
+	SelfTradeDecrementAndCancel = types.SelfTradeDecrementAndCancel
 )
 
 var (
 	NewBaseKeeper       = keepers.NewKeeper
 	DefaultParams       = types.DefaultParams
+	IsUpgradedParamKey  = types.IsUpgradedParamKey
 	DecToBigEndianBytes = types.DecToBigEndianBytes
 	ValidateOrderID     = types.ValidateOrderID
 	IsValidTradingPair  = types.IsValidTradingPair
@@ -41,10 +60,22 @@ type (
 	Params                  = types.Params
 	MsgCreateOrder          = types.MsgCreateOrder
 	MsgCreateTradingPair    = types.MsgCreateTradingPair
+	MsgCreateOrders         = types.MsgCreateOrders
+	OrderItem               = types.OrderItem
 	MsgCancelOrder          = types.MsgCancelOrder
+	MsgCancelAllOrders      = types.MsgCancelAllOrders
+	MsgModifyOrder          = types.MsgModifyOrder
 	MsgCancelTradingPair    = types.MsgCancelTradingPair
 	MsgModifyPricePrecision = types.MsgModifyPricePrecision
+	MsgModifyMarketParams   = types.MsgModifyMarketParams
+	MarketParams            = types.MarketParams
+	MsgHaltMarket           = types.MsgHaltMarket
+	MsgResumeMarket         = types.MsgResumeMarket
+	MarketStatus            = types.MarketStatus
+	MarketStatusInfo        = types.MarketStatusInfo
 	CreateOrderInfo         = types.CreateOrderInfo
 	FillOrderInfo           = types.FillOrderInfo
 	CancelOrderInfo         = types.CancelOrderInfo
+	Candle                  = types.Candle
+	Ticker                  = types.Ticker
 )
@@ -28,8 +28,14 @@ func GetQueryCmd(cdc *codec.Codec) *cobra.Command {
 		QueryMarketCmd(cdc),
 		QueryMarketListCmd(cdc),
 		QueryOrderbookCmd(cdc),
+		QueryDepthCmd(cdc),
+		QueryCandlesCmd(cdc),
+		QueryTickerCmd(cdc),
 		QueryOrderCmd(cdc),
-		QueryUserOrderList(cdc))...)
+		QueryUserOrderList(cdc),
+		QueryTradesCmd(cdc),
+		QueryUserTradesCmd(cdc),
+		QueryOrderTradesCmd(cdc))...)
 	return mktQueryCmd
 }
 
@@ -82,20 +88,144 @@ Example :
 }
 
 func QueryOrderbookCmd(cdc *codec.Codec) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "orderbook",
 		Short: "query the orders in a market",
-		Long: `query the orders in a market. 
+		Long: `query the orders in a market from the newest to the oldest.
+They are returned page by page if any filter or page flag is given,
+and the next page starts from the 'next_cursor' of the current page.
 
 Example : 
 	cetcli query market orderbook \
-	eth/cet --trust-node=true --chain-id=coinexdex`,
+	eth/cet --side=1 --min-height=1000 --limit=50 --trust-node=true --chain-id=coinexdex`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(strings.Split(args[0], types.SymbolSeparator)) != 2 {
 				return errors.Errorf("trading-pair illegal : %s, For example : eth/cet.", args[0])
 			}
+			filter, page := getOrderPageFlags(cmd)
 			query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryOrdersInMarket)
+			return cliutil.CliQuery(cdc, query, keepers.NewQueryOrdersInMarketParam(args[0], filter, page))
+		},
+	}
+	addOrderPageFlags(cmd, false)
+	return cmd
+}
+
+func addOrderPageFlags(cmd *cobra.Command, withTradingPair bool) {
+	if withTradingPair {
+		cmd.Flags().String(FlagSymbol, "", "Only query the orders in this trading pair")
+	}
+	cmd.Flags().Int(FlagSide, 0, "Only query the orders in this direction.(buy : 1; sell : 2; both : 0)")
+	cmd.Flags().Int64(FlagTimeInForce, 0, "Only query the orders with this time in force.(GTE : 3; IOC : 4; PostOnly : 5; FOK : 6; all : 0)")
+	cmd.Flags().Int64(FlagMinHeight, 0, "Only query the orders created at or after this height")
+	cmd.Flags().Int64(FlagMaxHeight, 0, "Only query the orders created at or before this height, 0 means no limit")
+	// all the orders are returned without paging if no filter or page flag is given
+	addPageFlags(cmd, 0)
+}
+
+func addPageFlags(cmd *cobra.Command, defaultLimit int) {
+	cmd.Flags().Int(FlagOffset, 0, "The number of the matched entries to skip")
+	cmd.Flags().Int(FlagLimit, defaultLimit, fmt.Sprintf("The max number of the entries in a page, 0 means %d", keepers.DefaultOrderPageLimit))
+	cmd.Flags().String(FlagCursor, "", "The next_cursor returned with the previous page")
+}
+
+func getOrderPageFlags(cmd *cobra.Command) (keepers.OrderFilter, keepers.PageRequest) {
+	var filter keepers.OrderFilter
+	if cmd.Flags().Lookup(FlagSymbol) != nil {
+		filter.TradingPair, _ = cmd.Flags().GetString(FlagSymbol)
+	}
+	side, _ := cmd.Flags().GetInt(FlagSide)
+	filter.Side = byte(side)
+	filter.TimeInForce, _ = cmd.Flags().GetInt64(FlagTimeInForce)
+	filter.MinHeight, _ = cmd.Flags().GetInt64(FlagMinHeight)
+	filter.MaxHeight, _ = cmd.Flags().GetInt64(FlagMaxHeight)
+	return filter, getPageFlags(cmd)
+}
+
+func getPageFlags(cmd *cobra.Command) keepers.PageRequest {
+	var page keepers.PageRequest
+	page.Offset, _ = cmd.Flags().GetInt(FlagOffset)
+	page.Limit, _ = cmd.Flags().GetInt(FlagLimit)
+	page.Cursor, _ = cmd.Flags().GetString(FlagCursor)
+	return page
+}
+
+func QueryDepthCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "depth",
+		Short: "query the aggregated price levels of the orders in a market",
+		Long: `query the aggregated price levels of the orders in a market.
+The prices are merged to at most 'merge' decimal places, which is limited by
+the price precision of the market.
+
+Example :
+	cetcli query market depth \
+	eth/cet --levels=20 --merge=2 --trust-node=true --chain-id=coinexdex`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(strings.Split(args[0], types.SymbolSeparator)) != 2 {
+				return errors.Errorf("trading-pair illegal : %s, For example : eth/cet.", args[0])
+			}
+			levels, _ := cmd.Flags().GetInt(FlagLevels)
+			merge, _ := cmd.Flags().GetInt(FlagMerge)
+			if merge < types.MinTokenPricePrecision || merge > types.MaxTokenPricePrecision {
+				return errors.Errorf("merge precision illegal : %d", merge)
+			}
+			query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryDepth)
+			return cliutil.CliQuery(cdc, query, keepers.NewQueryDepthParam(args[0], levels, byte(merge)))
+		},
+	}
+	cmd.Flags().Int(FlagLevels, keepers.DefaultDepthLevels, "The number of price levels on each side")
+	cmd.Flags().Int(FlagMerge, types.MaxTokenPricePrecision, "The number of decimal places of the merged prices")
+	return cmd
+}
+
+func QueryCandlesCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "candles",
+		Short: "query the latest OHLCV candles of a market",
+		Long: `query the latest OHLCV candles of a market. The span of the candles
+can be block, minute, hour or day.
+
+Example :
+	cetcli query market candles \
+	eth/cet --span=hour --count=24 --trust-node=true --chain-id=coinexdex`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(strings.Split(args[0], types.SymbolSeparator)) != 2 {
+				return errors.Errorf("trading-pair illegal : %s, For example : eth/cet.", args[0])
+			}
+			spanName, _ := cmd.Flags().GetString(FlagSpan)
+			span, ok := types.ParseCandleSpan(spanName)
+			if !ok {
+				return errors.Errorf("span illegal : %s, it should be block, minute, hour or day.", spanName)
+			}
+			count, _ := cmd.Flags().GetInt(FlagCount)
+			query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryCandles)
+			return cliutil.CliQuery(cdc, query, keepers.NewQueryCandlesParam(args[0], span, count))
+		},
+	}
+	cmd.Flags().String(FlagSpan, "minute", "The span of the candles (block, minute, hour or day)")
+	cmd.Flags().Int(FlagCount, keepers.DefaultCandleCount, "The number of the candles")
+	return cmd
+}
+
+func QueryTickerCmd(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ticker",
+		Short: "query the trade statistics of a market in the last 24 hours",
+		Long: `query the trade statistics of a market in the last 24 hours.
+
+Example :
+	cetcli query market ticker \
+	eth/cet --trust-node=true --chain-id=coinexdex`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(strings.Split(args[0], types.SymbolSeparator)) != 2 {
+				return errors.Errorf("trading-pair illegal : %s, For example : eth/cet.", args[0])
+			}
+			query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryTicker)
 			return cliutil.CliQuery(cdc, query, keepers.NewQueryMarketParam(args[0]))
 		},
 	}
@@ -128,20 +258,91 @@ func QueryUserOrderList(cdc *codec.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "order-list [userAddress]",
 		Short: "Query user order list in blockchain",
-		Long: `Query user order list in blockchain. 
+		Long: `Query user order list in blockchain.
+The orders are returned page by page if any filter or page flag is given,
+and the next page starts from the 'next_cursor' of the current page.
 
 Example:
 	cetcli query market order-list [userAddress] \
-	--trust-node=true --chain-id=coinexdex`,
+	--trading-pair=eth/cet --limit=50 --trust-node=true --chain-id=coinexdex`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if _, err := sdk.AccAddressFromBech32(args[0]); err != nil {
 				return err
 			}
+			filter, page := getOrderPageFlags(cmd)
 			route := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryUserOrders)
-			return cliutil.CliQuery(cdc, route, keepers.QueryUserOrderList{User: args[0]})
+			return cliutil.CliQuery(cdc, route, keepers.QueryUserOrderList{User: args[0], Filter: filter, Page: page})
+		},
+	}
+	addOrderPageFlags(cmd, true)
+	return cmd
+}
+
+func QueryTradesCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trades",
+		Short: "query the recent trades in a market",
+		Long: `query the recent trades in a market from the newest to the oldest, page by page.
+Only the trades in the last 'trade_retention' blocks are kept.
+
+Example :
+	cetcli query market trades \
+	eth/cet --limit=50 --trust-node=true --chain-id=coinexdex`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(strings.Split(args[0], types.SymbolSeparator)) != 2 {
+				return errors.Errorf("trading-pair illegal : %s, For example : eth/cet.", args[0])
+			}
+			query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryTrades)
+			return cliutil.CliQuery(cdc, query, keepers.QueryTradesParam{TradingPair: args[0], Page: getPageFlags(cmd)})
 		},
 	}
+	addPageFlags(cmd, keepers.DefaultOrderPageLimit)
+	return cmd
+}
 
+func QueryUserTradesCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user-trades [userAddress]",
+		Short: "query the recent trades of a user",
+		Long: `query the recent trades of a user in all the markets from the newest to the oldest, page by page.
+
+Example :
+	cetcli query market user-trades [userAddress] \
+	--limit=50 --trust-node=true --chain-id=coinexdex`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := sdk.AccAddressFromBech32(args[0]); err != nil {
+				return err
+			}
+			query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryUserTrades)
+			return cliutil.CliQuery(cdc, query, keepers.QueryUserTradesParam{User: args[0], Page: getPageFlags(cmd)})
+		},
+	}
+	addPageFlags(cmd, keepers.DefaultOrderPageLimit)
+	return cmd
+}
+
+func QueryOrderTradesCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "order-trades [orderID]",
+		Short: "query the recent trades of an order",
+		Long: `query the recent trades of an order from the newest to the oldest, page by page.
+The trades are kept after the order is filled or cancelled.
+
+Example :
+	cetcli query market order-trades [orderID] \
+	--trust-node=true --chain-id=coinexdex`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := types.ValidateOrderID(args[0]); err != nil {
+				return err
+			}
+			query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryOrderTrades)
+			return cliutil.CliQuery(cdc, query, keepers.QueryOrderTradesParam{OrderID: args[0], Page: getPageFlags(cmd)})
+		},
+	}
+	addPageFlags(cmd, keepers.DefaultOrderPageLimit)
 	return cmd
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/coinexchain/cet-sdk/modules/market/internal/keepers"
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
 	"github.com/coinexchain/cosmos-utils/client/cliutil"
 )
 
@@ -82,6 +83,60 @@ func TestQuery(t *testing.T) {
 	err = cmd.Execute()
 	assert.Equal(t, nil, err)
 	assert.Equal(t, "custom/market/orders-in-market", ResultPath)
+	assert.Equal(t, keepers.QueryOrdersInMarketParam{TradingPair: "eth/cet"}, ResultParam)
+
+	args = []string{
+		"orderbook",
+		"eth/cet",
+		"--side=2",
+		"--time-in-force=3",
+		"--min-height=100",
+		"--max-height=200",
+		"--offset=10",
+		"--limit=50",
+		"--cursor=abcd",
+	}
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, keepers.QueryOrdersInMarketParam{
+		TradingPair: "eth/cet",
+		Filter:      keepers.OrderFilter{Side: types.SELL, TimeInForce: types.GTE, MinHeight: 100, MaxHeight: 200},
+		Page:        keepers.PageRequest{Offset: 10, Limit: 50, Cursor: "abcd"},
+	}, ResultParam)
+
+	args = []string{
+		"depth",
+		"eth/cet",
+		"--levels=5",
+		"--merge=2",
+	}
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "custom/market/depth", ResultPath)
+	assert.Equal(t, keepers.QueryDepthParam{TradingPair: "eth/cet", Levels: 5, Merge: 2}, ResultParam)
+
+	args = []string{
+		"candles",
+		"eth/cet",
+		"--span=day",
+		"--count=7",
+	}
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "custom/market/candles", ResultPath)
+	assert.Equal(t, keepers.QueryCandlesParam{TradingPair: "eth/cet", Span: types.CandleSpanDay, Count: 7}, ResultParam)
+
+	args = []string{
+		"ticker",
+		"eth/cet",
+	}
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "custom/market/ticker", ResultPath)
 	assert.Equal(t, keepers.QueryMarketParam{TradingPair: "eth/cet"}, ResultParam)
 
 	args = []string{
@@ -94,6 +149,20 @@ func TestQuery(t *testing.T) {
 	assert.Equal(t, "custom/market/user-order-list", ResultPath)
 	assert.Equal(t, keepers.QueryUserOrderList{User: user}, ResultParam)
 
+	args = []string{
+		"order-list",
+		user,
+		"--trading-pair=eth/cet",
+		"--side=1",
+		"--limit=20",
+	}
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, keepers.QueryUserOrderList{User: user,
+		Filter: keepers.OrderFilter{TradingPair: "eth/cet", Side: types.BUY},
+		Page:   keepers.PageRequest{Limit: 20}}, ResultParam)
+
 	args = []string{
 		"order-list",
 		"coinex1px8alypku5j84qlwzdpy",
@@ -103,4 +172,38 @@ func TestQuery(t *testing.T) {
 	assert.Equal(t, "decoding bech32 failed: checksum failed. Expected 026624, got lwzdpy.", err.Error())
 	assert.Equal(t, "custom/market/user-order-list", ResultPath)
 
+	args = []string{
+		"trades",
+		"eth/cet",
+		"--limit=20",
+	}
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "custom/market/trades", ResultPath)
+	assert.Equal(t, keepers.QueryTradesParam{TradingPair: "eth/cet", Page: keepers.PageRequest{Limit: 20}}, ResultParam)
+
+	args = []string{
+		"user-trades",
+		user,
+		"--cursor=abcd",
+	}
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "custom/market/user-trades", ResultPath)
+	assert.Equal(t, keepers.QueryUserTradesParam{User: user,
+		Page: keepers.PageRequest{Limit: keepers.DefaultOrderPageLimit, Cursor: "abcd"}}, ResultParam)
+
+	args = []string{
+		"order-trades",
+		user + "-1025",
+		"--offset=2",
+	}
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "custom/market/order-trades", ResultPath)
+	assert.Equal(t, keepers.QueryOrderTradesParam{OrderID: user + "-1025",
+		Page: keepers.PageRequest{Offset: 2, Limit: keepers.DefaultOrderPageLimit}}, ResultParam)
 }
@@ -20,9 +20,15 @@ func GetTxCmd(cdc *codec.Codec) *cobra.Command {
 		CreateMarketCmd(cdc),
 		CreateGTEOrderTxCmd(cdc),
 		CreateIOCOrderTxCmd(cdc),
+		CreateOrdersTxCmd(cdc),
 		CancelOrder(cdc),
+		CancelAllOrders(cdc),
+		ModifyOrder(cdc),
 		CancelMarket(cdc),
 		ModifyTradingPairPricePrecision(cdc),
+		ModifyMarketParams(cdc),
+		HaltMarket(cdc),
+		ResumeMarket(cdc),
 	)...)
 
 	return mktTxCmd
@@ -18,6 +18,15 @@ const (
 	FlagMoney          = "money"
 	FlagPricePrecision = "price-precision"
 	FlagOrderPrecision = "order-precision"
+	FlagMatching       = "matching-algorithm"
+
+	FlagMaxPriceChangeRatio = "max-price-change-ratio"
+	FlagMarketFeeRate       = "market-fee-rate"
+	FlagGTEOrderLifetime    = "gte-order-lifetime"
+	FlagMinOrderQuantity    = "min-order-quantity"
+	FlagTickSize            = "tick-size"
+
+	FlagMarketStatus = "status"
 )
 
 var createMarketFlags = []string{
@@ -36,7 +45,8 @@ Example :
 	cetcli tx market create-trading-pair  \
 	--from bob --chain-id=coinexdex  \
 	--stock=eth --money=cet --order-precision=8 \
-	--price-precision=8 --gas 20000 --fees=1000cet`,
+	--price-precision=8 --matching-algorithm=continuous \
+	--gas 20000 --fees=1000cet`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			msg, err := getCreateMarketMsg()
 			if err != nil {
@@ -52,6 +62,8 @@ Example :
 		" control the price accuracy of the order when token trades")
 	cmd.Flags().Int(FlagOrderPrecision, 0, "To control the granularity of token trade, "+
 		"the token amount of trade must be a multiple of granularity.")
+	cmd.Flags().String(FlagMatching, "call-auction", "The algorithm to match the orders, "+
+		"which can be call-auction, continuous or pro-rata")
 	for _, flag := range createMarketFlags {
 		cmd.MarkFlagRequired(flag)
 	}
@@ -75,11 +87,20 @@ func parseCreateMarketFlags() (*types.MsgCreateTradingPair, error) {
 		}
 	}
 
+	algorithm := types.CallAuction
+	if name := viper.GetString(FlagMatching); len(name) != 0 {
+		var ok bool
+		if algorithm, ok = types.ParseMatchingAlgorithm(name); !ok {
+			return nil, fmt.Errorf("unknown matching algorithm : %s", name)
+		}
+	}
+
 	msg := &types.MsgCreateTradingPair{
-		Stock:          viper.GetString(FlagStock),
-		Money:          viper.GetString(FlagMoney),
-		PricePrecision: byte(viper.GetInt(FlagPricePrecision)),
-		OrderPrecision: byte(viper.GetInt(FlagOrderPrecision)),
+		Stock:             viper.GetString(FlagStock),
+		Money:             viper.GetString(FlagMoney),
+		PricePrecision:    byte(viper.GetInt(FlagPricePrecision)),
+		OrderPrecision:    byte(viper.GetInt(FlagOrderPrecision)),
+		MatchingAlgorithm: algorithm,
 	}
 	return msg, nil
 }
@@ -152,3 +173,108 @@ func getModifyTradingPairPricePrecisionMsg(cdc *codec.Codec) (*types.MsgModifyPr
 	}
 	return &msg, nil
 }
+
+func ModifyMarketParams(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "modify-market-params",
+		Short: "Override the global market params for the trading pair",
+		Long: `Override the global market params for the trading pair in the dex. All the overrides
+are replaced, and the omitted or zero values mean the global params are used.
+
+Example: 
+	cetcli tx market modify-market-params --trading-pair=etc/cet \
+	--market-fee-rate=20 --min-order-quantity=100000000 --tick-size=100 \
+	--from=bob --chain-id=coinexdex --gas=10000000 --fees=10000cet`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			msg, err := getModifyMarketParamsMsg()
+			if err != nil {
+				return err
+			}
+			return cliutil.CliRunCommand(cdc, msg)
+		},
+	}
+
+	cmd.Flags().String(FlagSymbol, "btc/cet", "The market trading-pair")
+	cmd.Flags().Int64(FlagMaxPriceChangeRatio, 0, "The max percent of the executed price change in one block")
+	cmd.Flags().Int64(FlagMarketFeeRate, 0, "The commission rate in the unit of 0.01%")
+	cmd.Flags().Int64(FlagGTEOrderLifetime, 0, "The blocks that a GTE order can stay in the order book for free")
+	cmd.Flags().Int64(FlagMinOrderQuantity, 0, "The min quantity of an order")
+	cmd.Flags().Int64(FlagTickSize, 0, "The price of an order must be a multiple of tick size, "+
+		"which is in the unit of the price precision of the trading pair")
+	cmd.MarkFlagRequired(FlagSymbol)
+	return cmd
+}
+
+func getModifyMarketParamsMsg() (*types.MsgModifyMarketParams, error) {
+	msg := types.MsgModifyMarketParams{
+		TradingPair: viper.GetString(FlagSymbol),
+		Params: types.MarketParams{
+			MaxExecutedPriceChangeRatio: viper.GetInt64(FlagMaxPriceChangeRatio),
+			MarketFeeRate:               viper.GetInt64(FlagMarketFeeRate),
+			GTEOrderLifetime:            viper.GetInt64(FlagGTEOrderLifetime),
+			MinOrderQuantity:            viper.GetInt64(FlagMinOrderQuantity),
+			TickSize:                    viper.GetInt64(FlagTickSize),
+		},
+	}
+	return &msg, nil
+}
+
+func HaltMarket(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "halt-market",
+		Short: "Halt the trading of the trading pair",
+		Long: `Halt the trading of the trading pair in the dex. In the halted status, the orders
+are accepted but not matched. In the cancel-only status, the orders can only be cancelled.
+In the auction-only status, the orders are matched by call auction.
+
+Example: 
+	cetcli tx market halt-market --trading-pair=etc/cet --status=cancel-only \
+	--from=bob --chain-id=coinexdex --gas=10000000 --fees=10000cet`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			msg, err := getHaltMarketMsg()
+			if err != nil {
+				return err
+			}
+			return cliutil.CliRunCommand(cdc, msg)
+		},
+	}
+
+	cmd.Flags().String(FlagSymbol, "btc/cet", "The market trading-pair")
+	cmd.Flags().String(FlagMarketStatus, "halted", "The new status, which can be halted, cancel-only or auction-only")
+	cmd.MarkFlagRequired(FlagSymbol)
+	return cmd
+}
+
+func getHaltMarketMsg() (*types.MsgHaltMarket, error) {
+	status, ok := types.ParseMarketStatus(viper.GetString(FlagMarketStatus))
+	if !ok || status == types.MarketOpen {
+		return nil, fmt.Errorf("unknown market status : %s", viper.GetString(FlagMarketStatus))
+	}
+	msg := types.MsgHaltMarket{
+		TradingPair: viper.GetString(FlagSymbol),
+		Status:      status,
+	}
+	return &msg, nil
+}
+
+func ResumeMarket(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume-market",
+		Short: "Resume the trading of the halted trading pair",
+		Long: `Resume the trading of the trading pair which is halted manually or by the circuit breaker.
+
+Example: 
+	cetcli tx market resume-market --trading-pair=etc/cet \
+	--from=bob --chain-id=coinexdex --gas=10000000 --fees=10000cet`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			msg := &types.MsgResumeMarket{
+				TradingPair: viper.GetString(FlagSymbol),
+			}
+			return cliutil.CliRunCommand(cdc, msg)
+		},
+	}
+
+	cmd.Flags().String(FlagSymbol, "btc/cet", "The market trading-pair")
+	cmd.MarkFlagRequired(FlagSymbol)
+	return cmd
+}
@@ -2,6 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"io/ioutil"
+	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -23,6 +26,25 @@ const (
 	FlagBlocks    = "blocks"
 	FlagTime      = "time"
 	FlagIdentify  = "identify"
+
+	FlagTriggerPrice = "trigger-price"
+	FlagPostOnly     = "post-only"
+	FlagFillOrKill   = "fill-or-kill"
+	FlagExpireTime   = "expire-time"
+	FlagSelfTrade    = "self-trade-prevention"
+	FlagDisplayQty   = "display-quantity"
+
+	FlagLevels = "levels"
+	FlagMerge  = "merge"
+	FlagSpan   = "span"
+	FlagCount  = "count"
+
+	FlagTimeInForce = "time-in-force"
+	FlagMinHeight   = "min-height"
+	FlagMaxHeight   = "max-height"
+	FlagOffset      = "offset"
+	FlagLimit       = "limit"
+	FlagCursor      = "cursor"
 )
 
 var createOrderFlags = []string{
@@ -51,6 +73,7 @@ Example:
 		},
 	}
 	markCreateOrderFlags(cmd)
+	cmd.Flags().Bool(FlagFillOrKill, false, "the order will be cancelled unless it is fully filled in the current block")
 	return cmd
 }
 
@@ -71,6 +94,8 @@ Example:
 	}
 	markCreateOrderFlags(cmd)
 	cmd.Flags().Int(FlagBlocks, 10000, "the gte order will exist at least blocks in blockChain")
+	cmd.Flags().Bool(FlagPostOnly, false, "the order will be cancelled if it would take liquidity from the order book")
+	cmd.Flags().Int64(FlagDisplayQty, 0, "only show this quantity in the order book, and the hidden rest replenishes it after it is dealt")
 	return cmd
 }
 
@@ -102,21 +127,62 @@ func parseCreateOrderFlags(isGTE bool) (*types.MsgCreateOrder, error) {
 		PricePrecision: byte(viper.GetInt(FlagPricePrecision)),
 		Quantity:       viper.GetInt64(FlagQuantity),
 		ExistBlocks:    viper.GetInt64(FlagBlocks),
+		TriggerPrice:   viper.GetInt64(FlagTriggerPrice),
 		TimeInForce:    types.IOC,
 	}
+	expireTime, err := parseExpireTime(viper.GetString(FlagExpireTime))
+	if err != nil {
+		return nil, err
+	}
+	msg.ExpireTime = expireTime
+	if name := viper.GetString(FlagSelfTrade); name != "" {
+		mode, ok := types.ParseSelfTradePrevention(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown self-trade prevention mode : %s", name)
+		}
+		msg.SelfTradePrevention = mode
+	}
+	if viper.GetBool(FlagFillOrKill) {
+		msg.TimeInForce = types.FOK
+	}
 	if isGTE {
 		msg.TimeInForce = types.GTE
+		if viper.GetBool(FlagPostOnly) {
+			msg.TimeInForce = types.PostOnly
+		}
+		msg.DisplayQuantity = viper.GetInt64(FlagDisplayQty)
 	}
 	return msg, nil
 }
 
+// the expire time can be a unix timestamp in seconds or a RFC3339 time, such as 2020-01-02T18:00:00Z
+func parseExpireTime(str string) (int64, error) {
+	if str == "" {
+		return 0, nil
+	}
+	if unixTime, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return unixTime, nil
+	}
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expire time : %s", str)
+	}
+	return t.Unix(), nil
+}
+
 func markCreateOrderFlags(cmd *cobra.Command) {
 	cmd.Flags().String(FlagSymbol, "", "The trading pair symbol")
-	cmd.Flags().Int(FlagOrderType, 2, "The identify of the price limit : 2; (Currently, only price limit orders are supported)")
+	cmd.Flags().Int(FlagOrderType, 2, "The type of the order (market : 1; limit : 2; stop-limit : 3; stop-market : 4). "+
+		"Market orders and stop-market orders must be IOC orders with zero price")
 	cmd.Flags().Int(FlagPrice, 100, "The price of the order")
+	cmd.Flags().Int(FlagTriggerPrice, 0, "The trigger price of a stop order, which uses the same price precision as the price")
 	cmd.Flags().Int(FlagQuantity, 100, "The number of tokens will be trade in the order ")
 	cmd.Flags().Int(FlagSide, 1, "The buying or selling direction of an order.(buy : 1; sell : 2)")
 	cmd.Flags().Int(FlagPricePrecision, 8, "The price precision in the order")
+	cmd.Flags().String(FlagExpireTime, "", "The time when the order will be cancelled, "+
+		"in unix seconds or RFC3339 format (e.g. 2020-01-02T18:00:00Z). Only GTE orders and stop orders can expire by time")
+	cmd.Flags().String(FlagSelfTrade, "none", "What to do when the order would deal with another order of the same sender, "+
+		"which can be none, cancel-newest, cancel-oldest, cancel-both or decrement-and-cancel")
 	cmd.Flags().Int(FlagIdentify, 0, "A transaction can contain multiple order "+
 		"creation messages, the identify field was added to the order creation message to give each "+
 		"order a unique ID. So the order ID consists of user address, user sequence, identify.")
@@ -146,6 +212,93 @@ Examples:
 	return cmd
 }
 
+func ModifyOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "modify-order",
+		Short: "modify the price and quantity of an order in blockchain",
+		Long: `modify the price and quantity of an order in blockchain. The quantity can only be decreased,
+and the order keeps its time priority if its price is not changed.
+
+Examples:
+	cetcli tx market modify-order --order-id=[id] \
+	--price=520 --price-precision=10 --quantity=5000000 \
+	--trust-node=true --from=bob --chain-id=coinexdex`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			msg := &types.MsgModifyOrder{
+				OrderID:        viper.GetString(FlagOrderID),
+				Price:          viper.GetInt64(FlagPrice),
+				PricePrecision: byte(viper.GetInt(FlagPricePrecision)),
+				Quantity:       viper.GetInt64(FlagQuantity),
+			}
+			return cliutil.CliRunCommand(cdc, msg)
+		},
+	}
+	markQueryOrDelCmd(cmd)
+	cmd.Flags().Int(FlagPrice, 0, "The new price of the order")
+	cmd.Flags().Int(FlagPricePrecision, 8, "The price precision of the new price")
+	cmd.Flags().Int(FlagQuantity, 0, "The new total quantity of the order, which can not be larger than the old one")
+	cmd.MarkFlagRequired(FlagPrice)
+	cmd.MarkFlagRequired(FlagQuantity)
+	return cmd
+}
+
+func CreateOrdersTxCmd(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create-orders [orders-file]",
+		Short: "Create a batch of orders in one trading pair and sign tx",
+		Long: `Create a batch of orders in one trading pair and sign tx, broadcast to nodes.
+The orders are read from a JSON file, which contains an array of orders like:
+
+[{"identify":1,"order_type":2,"price_precision":10,"price":"520","quantity":"10000000","side":1,"time_in_force":3,"exist_blocks":"0"}]
+
+Each order in the batch must have a unique identify.
+
+Example:
+	 cetcli tx market create-orders orders.json --trading-pair=btc/cet \
+	--from=bob --chain-id=coinexdex --gas=100000 --fees=10000cet`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			contents, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			msg := &types.MsgCreateOrders{
+				TradingPair: viper.GetString(FlagSymbol),
+			}
+			if err := cdc.UnmarshalJSON(contents, &msg.Orders); err != nil {
+				return err
+			}
+			return cliutil.CliRunCommand(cdc, msg)
+		},
+	}
+	cmd.Flags().String(FlagSymbol, "", "The trading pair symbol")
+	cmd.MarkFlagRequired(FlagSymbol)
+	return cmd
+}
+
+func CancelAllOrders(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel-all-orders",
+		Short: "cancel all the orders of the sender in a trading pair",
+		Long: `cancel all the orders of the sender in a trading pair.
+
+Examples:
+	cetcli tx market cancel-all-orders --trading-pair=btc/cet --side=1 \
+	--trust-node=true --from=bob --chain-id=coinexdex`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			msg := &types.MsgCancelAllOrders{
+				TradingPair: viper.GetString(FlagSymbol),
+				Side:        byte(viper.GetInt(FlagSide)),
+			}
+			return cliutil.CliRunCommand(cdc, msg)
+		},
+	}
+	cmd.Flags().String(FlagSymbol, "", "The trading pair symbol")
+	cmd.Flags().Int(FlagSide, 0, "Only cancel the orders in this direction.(buy : 1; sell : 2; both : 0)")
+	cmd.MarkFlagRequired(FlagSymbol)
+	return cmd
+}
+
 func markQueryOrDelCmd(cmd *cobra.Command) {
 	cmd.Flags().String(FlagOrderID, "", "The order id")
 	cmd.MarkFlagRequired(FlagOrderID)
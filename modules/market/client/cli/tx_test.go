@@ -130,6 +130,57 @@ func TestCmd(t *testing.T) {
 		PricePrecision: byte(9),
 	}, ResultMsg)
 
+	args = []string{
+		"modify-market-params",
+		"--trading-pair=etc/cet",
+		"--market-fee-rate=20",
+		"--min-order-quantity=1000",
+		"--tick-size=5",
+		"--from=" + addrStr,
+		"--generate-only",
+	}
+	cmd.SetArgs(args)
+	cliutil.SetViperWithArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &types.MsgModifyMarketParams{
+		Sender:      addr,
+		TradingPair: "etc/cet",
+		Params:      types.MarketParams{MarketFeeRate: 20, MinOrderQuantity: 1000, TickSize: 5},
+	}, ResultMsg)
+
+	args = []string{
+		"halt-market",
+		"--trading-pair=etc/cet",
+		"--status=cancel-only",
+		"--from=" + addrStr,
+		"--generate-only",
+	}
+	cmd.SetArgs(args)
+	cliutil.SetViperWithArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &types.MsgHaltMarket{
+		Sender:      addr,
+		TradingPair: "etc/cet",
+		Status:      types.MarketCancelOnly,
+	}, ResultMsg)
+
+	args = []string{
+		"resume-market",
+		"--trading-pair=etc/cet",
+		"--from=" + addrStr,
+		"--generate-only",
+	}
+	cmd.SetArgs(args)
+	cliutil.SetViperWithArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &types.MsgResumeMarket{
+		Sender:      addr,
+		TradingPair: "etc/cet",
+	}, ResultMsg)
+
 	args = []string{
 		"create-gte-order",
 		"--trading-pair=btc/cet",
@@ -205,4 +256,62 @@ func TestCmd(t *testing.T) {
 		Sender:  addr,
 		OrderID: "coinex1px8alypku5j84qlwzdpynhn4nyrkagaytu5u4a-1025",
 	}, ResultMsg)
+
+	args = []string{
+		"create-gte-order",
+		"--trading-pair=btc/cet",
+		"--order-type=2",
+		"--price=520",
+		"--quantity=12345678",
+		"--side=1",
+		"--price-precision=10",
+		"--identify=2",
+		"--blocks=40000",
+		"--expire-time=2020-01-02T18:00:00Z",
+		"--from=" + addrStr,
+		"--generate-only",
+	}
+	cmd.SetArgs(args)
+	cliutil.SetViperWithArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &types.MsgCreateOrder{
+		Sender:         addr,
+		Identify:       2,
+		TradingPair:    "btc/cet",
+		OrderType:      types.LIMIT,
+		Side:           types.BUY,
+		Price:          520,
+		PricePrecision: 10,
+		Quantity:       12345678,
+		ExistBlocks:    40000,
+		TimeInForce:    types.GTE,
+		ExpireTime:     1577988000,
+	}, ResultMsg)
+
+	args[9] = "--expire-time=1577988001"
+	cmd.SetArgs(args)
+	cliutil.SetViperWithArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.EqualValues(t, 1577988001, ResultMsg.(*types.MsgCreateOrder).ExpireTime)
+
+	args[9] = "--expire-time=tomorrow"
+	cmd.SetArgs(args)
+	cliutil.SetViperWithArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, "errors : invalid expire time : tomorrow, please see help : $ cetcli tx market create-gte-order -h", err.Error())
+
+	args[9] = "--self-trade-prevention=cancel-oldest"
+	cmd.SetArgs(args)
+	cliutil.SetViperWithArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, types.SelfTradeCancelOldest, ResultMsg.(*types.MsgCreateOrder).SelfTradePrevention)
+
+	args[9] = "--self-trade-prevention=cancel-all"
+	cmd.SetArgs(args)
+	cliutil.SetViperWithArgs(args)
+	err = cmd.Execute()
+	assert.Equal(t, "errors : unknown self-trade prevention mode : cancel-all, please see help : $ cetcli tx market create-gte-order -h", err.Error())
 }
@@ -3,6 +3,7 @@ package rest
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -37,6 +38,128 @@ func queryOrdersInMarketHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) h
 			rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid Trading pair")
 			return
 		}
+		filter, page, err := parseOrderPage(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		param := keepers.NewQueryOrdersInMarketParam(dex.GetSymbol(vars["stock"], vars["money"]), filter, page)
+		restutil.RestQuery(cdc, cliCtx, w, r, query, param, nil)
+	}
+}
+
+// parseOrderPage reads the filter and the page of the order queries from the query string
+func parseOrderPage(r *http.Request) (filter keepers.OrderFilter, page keepers.PageRequest, err error) {
+	filter.TradingPair = r.FormValue("trading_pair")
+	int64Values := map[string]*int64{
+		"time_in_force": &filter.TimeInForce,
+		"min_height":    &filter.MinHeight,
+		"max_height":    &filter.MaxHeight,
+	}
+	for name, value := range int64Values {
+		if str := r.FormValue(name); str != "" {
+			if *value, err = strconv.ParseInt(str, 10, 64); err != nil {
+				return filter, page, fmt.Errorf("invalid %s", name)
+			}
+		}
+	}
+	if str := r.FormValue("side"); str != "" {
+		side, e := strconv.ParseUint(str, 10, 8)
+		if e != nil {
+			return filter, page, fmt.Errorf("invalid side")
+		}
+		filter.Side = byte(side)
+	}
+	page, err = parsePage(r)
+	return filter, page, err
+}
+
+// parsePage reads offset, limit and cursor from the query string
+func parsePage(r *http.Request) (page keepers.PageRequest, err error) {
+	intValues := map[string]*int{
+		"offset": &page.Offset,
+		"limit":  &page.Limit,
+	}
+	for name, value := range intValues {
+		if str := r.FormValue(name); str != "" {
+			if *value, err = strconv.Atoi(str); err != nil {
+				return page, fmt.Errorf("invalid %s", name)
+			}
+		}
+	}
+	page.Cursor = r.FormValue("cursor")
+	return page, nil
+}
+
+func queryDepthHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryDepth)
+		if !types.IsValidTradingPair([]string{vars["stock"], vars["money"]}) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid Trading pair")
+			return
+		}
+		levels := keepers.DefaultDepthLevels
+		if str := r.FormValue("levels"); str != "" {
+			n, err := strconv.Atoi(str)
+			if err != nil || n <= 0 {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid levels")
+				return
+			}
+			levels = n
+		}
+		merge := byte(types.MaxTokenPricePrecision)
+		if str := r.FormValue("merge"); str != "" {
+			n, err := strconv.Atoi(str)
+			if err != nil || n < types.MinTokenPricePrecision || n > types.MaxTokenPricePrecision {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid merge precision")
+				return
+			}
+			merge = byte(n)
+		}
+		param := keepers.NewQueryDepthParam(dex.GetSymbol(vars["stock"], vars["money"]), levels, merge)
+		restutil.RestQuery(cdc, cliCtx, w, r, query, param, nil)
+	}
+}
+
+func queryCandlesHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryCandles)
+		if !types.IsValidTradingPair([]string{vars["stock"], vars["money"]}) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid Trading pair")
+			return
+		}
+		span := types.CandleSpanMinute
+		if str := r.FormValue("span"); str != "" {
+			var ok bool
+			if span, ok = types.ParseCandleSpan(str); !ok {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid span")
+				return
+			}
+		}
+		count := keepers.DefaultCandleCount
+		if str := r.FormValue("count"); str != "" {
+			n, err := strconv.Atoi(str)
+			if err != nil || n <= 0 {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid count")
+				return
+			}
+			count = n
+		}
+		param := keepers.NewQueryCandlesParam(dex.GetSymbol(vars["stock"], vars["money"]), span, count)
+		restutil.RestQuery(cdc, cliCtx, w, r, query, param, nil)
+	}
+}
+
+func queryTickerHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		query := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryTicker)
+		if !types.IsValidTradingPair([]string{vars["stock"], vars["money"]}) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid Trading pair")
+			return
+		}
 		param := keepers.NewQueryMarketParam(dex.GetSymbol(vars["stock"], vars["money"]))
 		restutil.RestQuery(cdc, cliCtx, w, r, query, param, nil)
 	}
@@ -69,12 +192,71 @@ func queryUserOrderListHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) ht
 			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		param := keepers.QueryUserOrderList{User: vars["address"]}
+		filter, page, err := parseOrderPage(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		param := keepers.QueryUserOrderList{User: vars["address"], Filter: filter, Page: page}
 		route := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryUserOrders)
 		restutil.RestQuery(cdc, cliCtx, w, r, route, param, nil)
 	}
 }
 
+func queryTradesHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if !types.IsValidTradingPair([]string{vars["stock"], vars["money"]}) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid Trading pair")
+			return
+		}
+		page, err := parsePage(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		param := keepers.QueryTradesParam{TradingPair: dex.GetSymbol(vars["stock"], vars["money"]), Page: page}
+		route := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryTrades)
+		restutil.RestQuery(cdc, cliCtx, w, r, route, param, nil)
+	}
+}
+
+func queryUserTradesHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if _, err := sdk.AccAddressFromBech32(vars["address"]); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		page, err := parsePage(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		param := keepers.QueryUserTradesParam{User: vars["address"], Page: page}
+		route := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryUserTrades)
+		restutil.RestQuery(cdc, cliCtx, w, r, route, param, nil)
+	}
+}
+
+func queryOrderTradesHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if err := types.ValidateOrderID(vars["order-id"]); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "Invalid Order ID")
+			return
+		}
+		page, err := parsePage(r)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		param := keepers.QueryOrderTradesParam{OrderID: vars["order-id"], Page: page}
+		route := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryOrderTrades)
+		restutil.RestQuery(cdc, cliCtx, w, r, route, param, nil)
+	}
+}
+
 func queryParamsHandlerFn(cliCtx context.CLIContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		route := fmt.Sprintf("custom/%s/%s", types.StoreKey, keepers.QueryParameters)
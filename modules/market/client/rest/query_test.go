@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/coinexchain/cet-sdk/modules/market/internal/keepers"
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
 	"github.com/coinexchain/cosmos-utils/client/restutil"
 )
 
@@ -41,6 +42,47 @@ func TestQuery(t *testing.T) {
 	req, _ = http.NewRequest("GET", "http://example.com/market/orderbook/etc/cet", nil)
 	router.ServeHTTP(respWr, req)
 	assert.Equal(t, "custom/market/orders-in-market", ResultPath)
+	assert.Equal(t, keepers.QueryOrdersInMarketParam{
+		TradingPair: "etc/cet",
+	}, ResultParam)
+
+	req, _ = http.NewRequest("GET", "http://example.com/market/orderbook/etc/cet?side=2&time_in_force=4&min_height=100&max_height=200&offset=5&limit=50&cursor=abcd", nil)
+	router.ServeHTTP(respWr, req)
+	assert.Equal(t, keepers.QueryOrdersInMarketParam{
+		TradingPair: "etc/cet",
+		Filter:      keepers.OrderFilter{Side: types.SELL, TimeInForce: types.IOC, MinHeight: 100, MaxHeight: 200},
+		Page:        keepers.PageRequest{Offset: 5, Limit: 50, Cursor: "abcd"},
+	}, ResultParam)
+
+	req, _ = http.NewRequest("GET", "http://example.com/market/depth/etc/cet?levels=5&merge=2", nil)
+	router.ServeHTTP(respWr, req)
+	assert.Equal(t, "custom/market/depth", ResultPath)
+	assert.Equal(t, keepers.QueryDepthParam{
+		TradingPair: "etc/cet",
+		Levels:      5,
+		Merge:       2,
+	}, ResultParam)
+
+	req, _ = http.NewRequest("GET", "http://example.com/market/depth/etc/cet", nil)
+	router.ServeHTTP(respWr, req)
+	assert.Equal(t, keepers.QueryDepthParam{
+		TradingPair: "etc/cet",
+		Levels:      keepers.DefaultDepthLevels,
+		Merge:       types.MaxTokenPricePrecision,
+	}, ResultParam)
+
+	req, _ = http.NewRequest("GET", "http://example.com/market/candles/etc/cet?span=hour&count=24", nil)
+	router.ServeHTTP(respWr, req)
+	assert.Equal(t, "custom/market/candles", ResultPath)
+	assert.Equal(t, keepers.QueryCandlesParam{
+		TradingPair: "etc/cet",
+		Span:        types.CandleSpanHour,
+		Count:       24,
+	}, ResultParam)
+
+	req, _ = http.NewRequest("GET", "http://example.com/market/ticker/etc/cet", nil)
+	router.ServeHTTP(respWr, req)
+	assert.Equal(t, "custom/market/ticker", ResultPath)
 	assert.Equal(t, keepers.QueryMarketParam{
 		TradingPair: "etc/cet",
 	}, ResultParam)
@@ -63,6 +105,38 @@ func TestQuery(t *testing.T) {
 		User: "coinex1px8alypku5j84qlwzdpynhn4nyrkagaytu5u4a",
 	}, ResultParam)
 
+	req, _ = http.NewRequest("GET", "http://example.com/market/orders/account/coinex1px8alypku5j84qlwzdpynhn4nyrkagaytu5u4a?trading_pair=etc/cet&side=1&limit=20", nil)
+	router.ServeHTTP(respWr, req)
+	assert.Equal(t, keepers.QueryUserOrderList{
+		User:   "coinex1px8alypku5j84qlwzdpynhn4nyrkagaytu5u4a",
+		Filter: keepers.OrderFilter{TradingPair: "etc/cet", Side: types.BUY},
+		Page:   keepers.PageRequest{Limit: 20},
+	}, ResultParam)
+
+	req, _ = http.NewRequest("GET", "http://example.com/market/trades/etc/cet?limit=20", nil)
+	router.ServeHTTP(respWr, req)
+	assert.Equal(t, "custom/market/trades", ResultPath)
+	assert.Equal(t, keepers.QueryTradesParam{
+		TradingPair: "etc/cet",
+		Page:        keepers.PageRequest{Limit: 20},
+	}, ResultParam)
+
+	req, _ = http.NewRequest("GET", "http://example.com/market/orders/account/coinex1px8alypku5j84qlwzdpynhn4nyrkagaytu5u4a/trades?cursor=abcd", nil)
+	router.ServeHTTP(respWr, req)
+	assert.Equal(t, "custom/market/user-trades", ResultPath)
+	assert.Equal(t, keepers.QueryUserTradesParam{
+		User: "coinex1px8alypku5j84qlwzdpynhn4nyrkagaytu5u4a",
+		Page: keepers.PageRequest{Cursor: "abcd"},
+	}, ResultParam)
+
+	req, _ = http.NewRequest("GET", "http://example.com/market/orders/coinex1px8alypku5j84qlwzdpynhn4nyrkagaytu5u4a-1025/trades?offset=2", nil)
+	router.ServeHTTP(respWr, req)
+	assert.Equal(t, "custom/market/order-trades", ResultPath)
+	assert.Equal(t, keepers.QueryOrderTradesParam{
+		OrderID: "coinex1px8alypku5j84qlwzdpynhn4nyrkagaytu5u4a-1025",
+		Page:    keepers.PageRequest{Offset: 2},
+	}, ResultParam)
+
 	req, _ = http.NewRequest("GET", "http://example.com/market/parameters", nil)
 	router.ServeHTTP(respWr, req)
 	assert.Equal(t, "custom/market/parameters", ResultPath)
@@ -15,9 +15,15 @@ func RegisterRoutes(cliCtx context.CLIContext, r *mux.Router, cdc *codec.Codec)
 func registerQueryRoutes(cliCtx context.CLIContext, r *mux.Router, cdc *codec.Codec) {
 	r.HandleFunc("/market/trading-pairs/{stock}/{money}", queryMarketHandlerFn(cdc, cliCtx)).Methods("GET")
 	r.HandleFunc("/market/orderbook/{stock}/{money}", queryOrdersInMarketHandlerFn(cdc, cliCtx)).Methods("GET")
+	r.HandleFunc("/market/depth/{stock}/{money}", queryDepthHandlerFn(cdc, cliCtx)).Methods("GET")
+	r.HandleFunc("/market/candles/{stock}/{money}", queryCandlesHandlerFn(cdc, cliCtx)).Methods("GET")
+	r.HandleFunc("/market/ticker/{stock}/{money}", queryTickerHandlerFn(cdc, cliCtx)).Methods("GET")
 	r.HandleFunc("/market/exist-trading-pairs", queryMarketsHandlerFn(cdc, cliCtx)).Methods("GET")
 	r.HandleFunc("/market/orders/{order-id}", queryOrderInfoHandlerFn(cdc, cliCtx)).Methods("GET")
 	r.HandleFunc("/market/orders/account/{address}", queryUserOrderListHandlerFn(cdc, cliCtx)).Methods("GET")
+	r.HandleFunc("/market/trades/{stock}/{money}", queryTradesHandlerFn(cdc, cliCtx)).Methods("GET")
+	r.HandleFunc("/market/orders/account/{address}/trades", queryUserTradesHandlerFn(cdc, cliCtx)).Methods("GET")
+	r.HandleFunc("/market/orders/{order-id}/trades", queryOrderTradesHandlerFn(cdc, cliCtx)).Methods("GET")
 	r.HandleFunc("/market/parameters", queryParamsHandlerFn(cliCtx)).Methods("GET")
 }
 
@@ -25,7 +31,13 @@ func registerTXRoutes(cliCtx context.CLIContext, r *mux.Router, cdc *codec.Codec
 	r.HandleFunc("/market/gte-orders", createGTEOrderHandlerFn(cdc, cliCtx)).Methods("POST")
 	r.HandleFunc("/market/trading-pairs", createMarketHandlerFn(cdc, cliCtx)).Methods("POST")
 	r.HandleFunc("/market/ioc-orders", createIOCOrderHandlerFn(cdc, cliCtx)).Methods("POST")
+	r.HandleFunc("/market/batch-orders", createOrdersHandlerFn(cdc, cliCtx)).Methods("POST")
 	r.HandleFunc("/market/cancel-order", cancelOrderHandlerFn(cdc, cliCtx)).Methods("POST")
+	r.HandleFunc("/market/cancel-all-orders", cancelAllOrdersHandlerFn(cdc, cliCtx)).Methods("POST")
+	r.HandleFunc("/market/modify-order", modifyOrderHandlerFn(cdc, cliCtx)).Methods("POST")
 	r.HandleFunc("/market/cancel-trading-pair", cancelMarketHandlerFn(cdc, cliCtx)).Methods("POST")
 	r.HandleFunc("/market/price-precision", modifyTradingPairPricePrecision(cdc, cliCtx)).Methods("POST")
+	r.HandleFunc("/market/market-params", modifyMarketParamsHandlerFn(cdc, cliCtx)).Methods("POST")
+	r.HandleFunc("/market/halt", haltMarketHandlerFn(cdc, cliCtx)).Methods("POST")
+	r.HandleFunc("/market/resume", resumeMarketHandlerFn(cdc, cliCtx)).Methods("POST")
 }
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/cosmos/cosmos-sdk/client/context"
@@ -19,6 +20,8 @@ type createMarketReq struct {
 	Money          string       `json:"money"`
 	PricePrecision int          `json:"price_precision"`
 	OrderPrecision int          `json:"order_precision,omitempty"`
+	// call-auction, continuous or pro-rata, and call-auction is the default
+	MatchingAlgorithm string `json:"matching_algorithm,omitempty"`
 }
 
 func (req *createMarketReq) New() restutil.RestReq {
@@ -29,6 +32,13 @@ func (req *createMarketReq) GetBaseReq() *rest.BaseReq {
 }
 func (req *createMarketReq) GetMsg(r *http.Request, sender sdk.AccAddress) (sdk.Msg, error) {
 	msg := types.NewMsgCreateTradingPair(req.Stock, req.Money, sender, byte(req.PricePrecision), byte(req.OrderPrecision))
+	if len(req.MatchingAlgorithm) != 0 {
+		algorithm, ok := types.ParseMatchingAlgorithm(req.MatchingAlgorithm)
+		if !ok {
+			return nil, fmt.Errorf("unknown matching algorithm : %s", req.MatchingAlgorithm)
+		}
+		msg.MatchingAlgorithm = algorithm
+	}
 	return msg, nil
 }
 
@@ -74,6 +84,72 @@ func (req *modifyPricePrecision) GetMsg(r *http.Request, sender sdk.AccAddress)
 	return msg, nil
 }
 
+type modifyMarketParamsReq struct {
+	BaseReq     rest.BaseReq       `json:"base_req"`
+	TradingPair string             `json:"trading_pair"`
+	Params      types.MarketParams `json:"params"`
+}
+
+func (req *modifyMarketParamsReq) New() restutil.RestReq {
+	return new(modifyMarketParamsReq)
+}
+func (req *modifyMarketParamsReq) GetBaseReq() *rest.BaseReq {
+	return &req.BaseReq
+}
+func (req *modifyMarketParamsReq) GetMsg(r *http.Request, sender sdk.AccAddress) (sdk.Msg, error) {
+	msg := types.MsgModifyMarketParams{
+		Sender:      sender,
+		TradingPair: req.TradingPair,
+		Params:      req.Params,
+	}
+	return msg, nil
+}
+
+type haltMarketReq struct {
+	BaseReq     rest.BaseReq `json:"base_req"`
+	TradingPair string       `json:"trading_pair"`
+	// halted, cancel-only or auction-only
+	Status string `json:"status"`
+}
+
+func (req *haltMarketReq) New() restutil.RestReq {
+	return new(haltMarketReq)
+}
+func (req *haltMarketReq) GetBaseReq() *rest.BaseReq {
+	return &req.BaseReq
+}
+func (req *haltMarketReq) GetMsg(r *http.Request, sender sdk.AccAddress) (sdk.Msg, error) {
+	status, ok := types.ParseMarketStatus(req.Status)
+	if !ok || status == types.MarketOpen {
+		return nil, fmt.Errorf("unknown market status : %s", req.Status)
+	}
+	msg := types.MsgHaltMarket{
+		Sender:      sender,
+		TradingPair: req.TradingPair,
+		Status:      status,
+	}
+	return msg, nil
+}
+
+type resumeMarketReq struct {
+	BaseReq     rest.BaseReq `json:"base_req"`
+	TradingPair string       `json:"trading_pair"`
+}
+
+func (req *resumeMarketReq) New() restutil.RestReq {
+	return new(resumeMarketReq)
+}
+func (req *resumeMarketReq) GetBaseReq() *rest.BaseReq {
+	return &req.BaseReq
+}
+func (req *resumeMarketReq) GetMsg(r *http.Request, sender sdk.AccAddress) (sdk.Msg, error) {
+	msg := types.MsgResumeMarket{
+		Sender:      sender,
+		TradingPair: req.TradingPair,
+	}
+	return msg, nil
+}
+
 func createMarketHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
 	var req createMarketReq
 	return restutil.NewRestHandler(cdc, cliCtx, &req)
@@ -88,3 +164,18 @@ func modifyTradingPairPricePrecision(cdc *codec.Codec, cliCtx context.CLIContext
 	var req modifyPricePrecision
 	return restutil.NewRestHandler(cdc, cliCtx, &req)
 }
+
+func modifyMarketParamsHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	var req modifyMarketParamsReq
+	return restutil.NewRestHandler(cdc, cliCtx, &req)
+}
+
+func haltMarketHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	var req haltMarketReq
+	return restutil.NewRestHandler(cdc, cliCtx, &req)
+}
+
+func resumeMarketHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	var req resumeMarketReq
+	return restutil.NewRestHandler(cdc, cliCtx, &req)
+}
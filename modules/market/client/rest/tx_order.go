@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/cosmos/cosmos-sdk/client/context"
@@ -24,6 +25,12 @@ type createOrderReq struct {
 	Side           int          `json:"side"`
 	ExistBlocks    int          `json:"exist_blocks"`
 	TimeInForce    int          `json:"time_in_force"`
+	TriggerPrice   int64        `json:"trigger_price"`
+	ExpireTime     int64        `json:"expire_time"`
+	// none, cancel-newest, cancel-oldest, cancel-both or decrement-and-cancel
+	SelfTradePrevention string `json:"self_trade_prevention"`
+	// only this quantity of an iceberg order is shown in the order book
+	DisplayQuantity int64 `json:"display_quantity"`
 }
 
 func (req *createOrderReq) New() restutil.RestReq {
@@ -44,9 +51,46 @@ func (req *createOrderReq) GetMsg(r *http.Request, sender sdk.AccAddress) (sdk.M
 		Side:           byte(req.Side),
 		TimeInForce:    types.IOC,
 		ExistBlocks:    int64(req.ExistBlocks),
+		TriggerPrice:   req.TriggerPrice,
+		ExpireTime:     req.ExpireTime,
+	}
+	msg.DisplayQuantity = req.DisplayQuantity
+	if req.SelfTradePrevention != "" {
+		mode, ok := types.ParseSelfTradePrevention(req.SelfTradePrevention)
+		if !ok {
+			return nil, fmt.Errorf("unknown self-trade prevention mode : %s", req.SelfTradePrevention)
+		}
+		msg.SelfTradePrevention = mode
+	}
+	if req.TimeInForce == types.FOK {
+		msg.TimeInForce = types.FOK
 	}
 	if r.URL.Path == "/market/gte-orders" {
 		msg.TimeInForce = types.GTE
+		if req.TimeInForce == types.PostOnly {
+			msg.TimeInForce = types.PostOnly
+		}
+	}
+	return msg, nil
+}
+
+type createOrdersReq struct {
+	BaseReq     rest.BaseReq      `json:"base_req"`
+	TradingPair string            `json:"trading_pair"`
+	Orders      []types.OrderItem `json:"orders"`
+}
+
+func (req *createOrdersReq) New() restutil.RestReq {
+	return new(createOrdersReq)
+}
+func (req *createOrdersReq) GetBaseReq() *rest.BaseReq {
+	return &req.BaseReq
+}
+func (req *createOrdersReq) GetMsg(r *http.Request, sender sdk.AccAddress) (sdk.Msg, error) {
+	msg := &types.MsgCreateOrders{
+		Sender:      sender,
+		TradingPair: req.TradingPair,
+		Orders:      req.Orders,
 	}
 	return msg, nil
 }
@@ -70,6 +114,52 @@ func (req *cancelOrderReq) GetMsg(r *http.Request, sender sdk.AccAddress) (sdk.M
 	return msg, nil
 }
 
+type cancelAllOrdersReq struct {
+	BaseReq     rest.BaseReq `json:"base_req"`
+	TradingPair string       `json:"trading_pair"`
+	Side        int          `json:"side"`
+}
+
+func (req *cancelAllOrdersReq) New() restutil.RestReq {
+	return new(cancelAllOrdersReq)
+}
+func (req *cancelAllOrdersReq) GetBaseReq() *rest.BaseReq {
+	return &req.BaseReq
+}
+func (req *cancelAllOrdersReq) GetMsg(r *http.Request, sender sdk.AccAddress) (sdk.Msg, error) {
+	msg := &types.MsgCancelAllOrders{
+		Sender:      sender,
+		TradingPair: req.TradingPair,
+		Side:        byte(req.Side),
+	}
+	return msg, nil
+}
+
+type modifyOrderReq struct {
+	BaseReq        rest.BaseReq `json:"base_req"`
+	OrderID        string       `json:"order_id"`
+	PricePrecision int          `json:"price_precision"`
+	Price          int64        `json:"price"`
+	Quantity       int64        `json:"quantity"`
+}
+
+func (req *modifyOrderReq) New() restutil.RestReq {
+	return new(modifyOrderReq)
+}
+func (req *modifyOrderReq) GetBaseReq() *rest.BaseReq {
+	return &req.BaseReq
+}
+func (req *modifyOrderReq) GetMsg(r *http.Request, sender sdk.AccAddress) (sdk.Msg, error) {
+	msg := &types.MsgModifyOrder{
+		Sender:         sender,
+		OrderID:        req.OrderID,
+		PricePrecision: byte(req.PricePrecision),
+		Price:          req.Price,
+		Quantity:       req.Quantity,
+	}
+	return msg, nil
+}
+
 func createGTEOrderHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
 	return createOrderAndBroadCast(cdc, cliCtx)
 }
@@ -83,6 +173,21 @@ func cancelOrderHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.Hand
 	return restutil.NewRestHandler(cdc, cliCtx, &req)
 }
 
+func createOrdersHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	var req createOrdersReq
+	return restutil.NewRestHandler(cdc, cliCtx, &req)
+}
+
+func cancelAllOrdersHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	var req cancelAllOrdersReq
+	return restutil.NewRestHandler(cdc, cliCtx, &req)
+}
+
+func modifyOrderHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
+	var req modifyOrderReq
+	return restutil.NewRestHandler(cdc, cliCtx, &req)
+}
+
 func createOrderAndBroadCast(cdc *codec.Codec, cliCtx context.CLIContext) http.HandlerFunc {
 	var req createOrderReq
 	return restutil.NewRestHandler(cdc, cliCtx, &req)
@@ -47,6 +47,37 @@ func TestCmd(t *testing.T) {
 		PricePrecision: 9,
 	}, msg)
 	//==============
+	modifyParams := modifyMarketParamsReq{
+		TradingPair: "etc/cet",
+		Params:      types.MarketParams{MarketFeeRate: 20, TickSize: 5},
+	}
+	msg, _ = modifyParams.GetMsg(nil, addr)
+	assert.Equal(t, types.MsgModifyMarketParams{
+		Sender:      addr,
+		TradingPair: "etc/cet",
+		Params:      types.MarketParams{MarketFeeRate: 20, TickSize: 5},
+	}, msg)
+	//==============
+	halt := haltMarketReq{
+		TradingPair: "etc/cet",
+		Status:      "auction-only",
+	}
+	msg, _ = halt.GetMsg(nil, addr)
+	assert.Equal(t, types.MsgHaltMarket{
+		Sender:      addr,
+		TradingPair: "etc/cet",
+		Status:      types.MarketAuctionOnly,
+	}, msg)
+	halt.Status = "open"
+	_, err := halt.GetMsg(nil, addr)
+	assert.NotNil(t, err)
+	resume := resumeMarketReq{TradingPair: "etc/cet"}
+	msg, _ = resume.GetMsg(nil, addr)
+	assert.Equal(t, types.MsgResumeMarket{
+		Sender:      addr,
+		TradingPair: "etc/cet",
+	}, msg)
+	//==============
 	createOrder := createOrderReq{
 		OrderType:      types.LIMIT,
 		TradingPair:    "etc/cet",
@@ -20,8 +20,41 @@ type InfoForDeal struct {
 	msgSender     msgqueue.MsgSender
 	dataHash      []byte
 	changedOrders map[string]*types.Order
-	lastPrice     sdk.Dec
-	context       sdk.Context
+	// the orders excluded from matching because of their time-in-force or cancelled to prevent self-trades
+	rejectedOrders map[string]*types.Order
+	// the reasons of the rejected orders, which are empty when they can be told from the orders
+	delReasons map[string]string
+	lastPrice  sdk.Dec
+	// the OHLCV statistics of the trades in this block
+	candle *types.Candle
+	// the stock and money dealt by each account in this block, in the sequence of their first deals
+	tradedAmounts []*tradedAmount
+	tradedIndexes map[string]int
+	// the fills in this block, which are only recorded when the trade history is enabled
+	recordTrades bool
+	trades       []*types.Trade
+	context      sdk.Context
+}
+
+type tradedAmount struct {
+	trader sdk.AccAddress
+	stock  sdk.Int
+	money  sdk.Int
+}
+
+func (info *InfoForDeal) addTradedAmount(trader sdk.AccAddress, stock, money int64) {
+	idx, ok := info.tradedIndexes[string(trader)]
+	if !ok {
+		idx = len(info.tradedAmounts)
+		info.tradedIndexes[string(trader)] = idx
+		info.tradedAmounts = append(info.tradedAmounts, &tradedAmount{
+			trader: trader,
+			stock:  sdk.ZeroInt(),
+			money:  sdk.ZeroInt(),
+		})
+	}
+	info.tradedAmounts[idx].stock = info.tradedAmounts[idx].stock.AddRaw(stock)
+	info.tradedAmounts[idx].money = info.tradedAmounts[idx].money.AddRaw(money)
 }
 
 // returns true when a buyer's frozen money is not enough to buy LeftStock.
@@ -34,6 +67,8 @@ func notEnoughMoney(order *types.Order) bool {
 type WrappedOrder struct {
 	order       *types.Order
 	infoForDeal *InfoForDeal
+	// the order takes no part in the following matching after it is cancelled to prevent a self-trade
+	cancelled bool
 }
 
 // WrappedOrder implements OrderForTrade interface
@@ -42,15 +77,16 @@ func (wo *WrappedOrder) GetPrice() sdk.Dec {
 }
 
 func (wo *WrappedOrder) GetAmount() int64 {
-	if notEnoughMoney(wo.order) {
+	if wo.cancelled || notEnoughMoney(wo.order) {
 		// add this clause only for safe, should not reach here in production
 		return 0
 	}
-	return wo.order.LeftStock
+	// the hidden part of an iceberg order is not dealt until it is replenished into the visible slice
+	return wo.order.VisibleLeftStock()
 }
 
 func (wo *WrappedOrder) GetHeight() int64 {
-	return wo.order.Height
+	return wo.order.PriorityHeight()
 }
 
 func (wo *WrappedOrder) GetSide() int {
@@ -61,6 +97,49 @@ func (wo *WrappedOrder) GetOwner() match.Account {
 	return wo.order.Sender
 }
 
+func (wo *WrappedOrder) GetTimeInForce() int64 {
+	return wo.order.TimeInForce
+}
+
+func (wo *WrappedOrder) Reject() {
+	wo.infoForDeal.rejectedOrders[wo.order.OrderID()] = wo.order
+	wo.infoForDeal.delReasons[wo.order.OrderID()] = ""
+}
+
+func (wo *WrappedOrder) GetSelfTradePrevention() byte {
+	return wo.order.SelfTradePrevention
+}
+
+// The order is removed at the end of the block when all its left stock is cancelled, otherwise its left
+// stock is decreased and the frozen coins which are no longer needed are unfrozen. Only the visible slice
+// of an iceberg order can be cancelled, and it is replenished from the hidden part at the end of the block.
+func (wo *WrappedOrder) CancelSelfTrade(amount int64) {
+	order := wo.order
+	if amount >= order.LeftStock {
+		wo.cancelled = true
+		wo.infoForDeal.rejectedOrders[order.OrderID()] = order
+		wo.infoForDeal.delReasons[order.OrderID()] = types.CancelOrderBySelfTrade
+		return
+	}
+	order.LeftStock -= amount
+	if order.IsIceberg() {
+		order.VisibleStock -= amount
+	}
+	freeze := order.LeftStock
+	if order.Side == types.BUY {
+		freeze = order.Price.MulInt64(order.LeftStock).Ceil().RoundInt64()
+	}
+	if freeze < order.Freeze {
+		ctx := wo.infoForDeal.context
+		coins := dex.NewCoins(order.GetOrderUsedDenom(), order.Freeze-freeze)
+		if err := wo.infoForDeal.bxKeeper.UnFreezeCoins(ctx, order.Sender, coins); err != nil {
+			ctx.Logger().Error("%s", err.Error())
+		}
+		order.Freeze = freeze
+	}
+	wo.infoForDeal.changedOrders[order.OrderID()] = order
+}
+
 func (wo *WrappedOrder) String() string {
 	return wo.order.OrderID()
 }
@@ -97,6 +176,16 @@ func (wo *WrappedOrder) Deal(otherSide match.OrderForTrade, amount int64, price
 	buyer.DealMoney += moneyAmountInt64
 	seller.DealMoney += moneyAmountInt64
 	ctx := wo.infoForDeal.context
+	// an order is a maker if it rested in the order book before this block, and the
+	// dealt stock of an iceberg order is taken from its visible slice
+	for _, order := range []*types.Order{buyer, seller} {
+		if order.PriorityHeight() < ctx.BlockHeight() {
+			order.MakerDealStock += amount
+		}
+		if order.IsIceberg() {
+			order.VisibleStock -= amount
+		}
+	}
 	// exchange the coins
 	wo.infoForDeal.bxKeeper.UnFreezeCoins(ctx, seller.Sender, stockCoins)
 	wo.infoForDeal.bxKeeper.SendCoins(ctx, seller.Sender, buyer.Sender, stockCoins)
@@ -109,6 +198,13 @@ func (wo *WrappedOrder) Deal(otherSide match.OrderForTrade, amount int64, price
 
 	// record the last executed price, which will be stored in MarketInfo
 	wo.infoForDeal.lastPrice = price
+	wo.infoForDeal.candle.AddTrade(price, amount, moneyAmountInt64)
+	wo.infoForDeal.addTradedAmount(buyer.Sender, amount, moneyAmountInt64)
+	wo.infoForDeal.addTradedAmount(seller.Sender, amount, moneyAmountInt64)
+	if wo.infoForDeal.recordTrades {
+		trade := types.NewTrade(ctx, int64(len(wo.infoForDeal.trades)), buyer, seller, price, amount, moneyAmountInt64)
+		wo.infoForDeal.trades = append(wo.infoForDeal.trades, trade)
+	}
 
 	if wo.infoForDeal.msgSender.IsSubscribed(types.Topic) {
 		SendFillMsg(ctx, seller, buyer, amount, moneyAmountInt64, price, ctx.BlockHeight())
@@ -120,6 +216,7 @@ func SendFillMsg(ctx sdk.Context, seller *Order, buyer *Order, stockAmount, mone
 		OrderID:     seller.OrderID(),
 		Height:      currentHeight,
 		TradingPair: seller.TradingPair,
+		OrderType:   seller.OrderType,
 		Side:        seller.Side,
 		FillPrice:   price,
 		LeftStock:   seller.LeftStock,
@@ -136,6 +233,7 @@ func SendFillMsg(ctx sdk.Context, seller *Order, buyer *Order, stockAmount, mone
 		OrderID:     buyer.OrderID(),
 		Height:      currentHeight,
 		TradingPair: buyer.TradingPair,
+		OrderType:   buyer.OrderType,
 		Side:        buyer.Side,
 		FillPrice:   price,
 		LeftStock:   buyer.LeftStock,
@@ -164,7 +262,7 @@ func removeOrder(ctx sdk.Context, orderKeeper keepers.OrderKeeper, bxKeeper type
 func unfreezeCoinsForOrder(ctx sdk.Context, bxKeeper types.ExpectedBankxKeeper, order *types.Order,
 	keeper types.Keeper, marketParam *types.Params) {
 	unfreezeCoinsInOrder(ctx, order, bxKeeper)
-	chargeOrderCommission(ctx, order, marketParam.FeeForZeroDeal, bxKeeper, keeper)
+	chargeOrderCommission(ctx, order, marketParam, bxKeeper, keeper)
 	chargeOrderFeatureFee(ctx, order, marketParam.GTEOrderLifetime, bxKeeper, keeper)
 }
 
@@ -175,20 +273,28 @@ func unfreezeCoinsInOrder(ctx sdk.Context, order *types.Order, bxKeeper types.Ex
 	}
 }
 
-func chargeOrderCommission(ctx sdk.Context, order *types.Order, feeForZeroDeal int64,
+func chargeOrderCommission(ctx sdk.Context, order *types.Order, marketParam *types.Params,
 	bxKeeper types.ExpectedBankxKeeper, keeper types.Keeper) {
 	if order.FrozenCommission != 0 {
 		if err := bxKeeper.UnFreezeCoins(ctx, order.Sender, dex.NewCetCoins(order.FrozenCommission)); err != nil {
 			ctx.Logger().Error("%s", err.Error())
 		}
-		actualFee := order.CalActualOrderCommissionInt64(feeForZeroDeal)
+		actualFee := calActualOrderCommission(ctx, order, marketParam, keeper)
 		chargeFee(ctx, actualFee, order.Sender, keeper)
 	}
 }
 
+// the commission is charged at the maker and taker rates of the sender's fee tier in the market
+func calActualOrderCommission(ctx sdk.Context, order *types.Order, marketParam *types.Params,
+	keeper types.ExpectedFeeRateKeeper) int64 {
+	makerRate, takerRate := marketParam.GetFeeRates(keeper.GetUserVolume(ctx, order.Sender))
+	return order.CalActualOrderCommissionWithRates(marketParam.FeeForZeroDeal, marketParam.MarketFeeMin,
+		marketParam.MarketFeeRate, makerRate, takerRate)
+}
+
 func chargeOrderFeatureFee(ctx sdk.Context, order *types.Order, freeTimeBlocks int64,
 	bxKeeper types.ExpectedBankxKeeper, keeper types.Keeper) {
-	if order.TimeInForce == GTE && order.FrozenFeatureFee != 0 {
+	if (!order.IsImmediateOrder() || order.IsStopOrder()) && order.FrozenFeatureFee != 0 {
 		if err := bxKeeper.UnFreezeCoins(ctx, order.Sender, dex.NewCetCoins(order.FrozenFeatureFee)); err != nil {
 			ctx.Logger().Error("%s", err.Error())
 		}
@@ -237,20 +343,55 @@ func filterCandidates(ctx sdk.Context, asKeeper types.ExpectedAssetStatusKeeper,
 	return ordersOut
 }
 
-func runMatch(ctx sdk.Context, midPrice sdk.Dec, ratio int64, symbol string, keeper keepers.Keeper, dataHash []byte, currHeight int64) (map[string]*types.Order, sdk.Dec) {
+// Activate the dormant stop orders which are triggered by the last executed price, such that
+// they can take part in the following matching. The triggered orders are returned.
+func triggerStopOrders(ctx sdk.Context, keeper keepers.Keeper, orderKeeper keepers.OrderKeeper, lastPrice sdk.Dec) []*types.Order {
+	triggered := orderKeeper.GetOrdersToTrigger(ctx, lastPrice)
+	for _, order := range triggered {
+		if err := orderKeeper.Remove(ctx, order); err != nil {
+			ctx.Logger().Error("%s", err.Error())
+			continue
+		}
+		order.TriggerHeight = ctx.BlockHeight()
+		orderKeeper.Add(ctx, order)
+		if keeper.IsSubScribed(types.Topic) {
+			msgqueue.FillMsgs(ctx, types.TriggerOrderInfoKey, types.TriggerOrderInfo{
+				OrderID:           order.OrderID(),
+				TradingPair:       order.TradingPair,
+				OrderType:         order.OrderType,
+				Side:              order.Side,
+				Price:             order.Price,
+				TriggerPrice:      order.TriggerPrice,
+				LastExecutedPrice: lastPrice,
+				TriggerHeight:     order.TriggerHeight,
+			})
+		}
+	}
+	return triggered
+}
+
+func runMatch(ctx sdk.Context, mi types.MarketInfo, marketParams *types.Params, keeper keepers.Keeper, dataHash []byte,
+	currHeight int64, triggered []*types.Order) (map[string]*types.Order, map[string]string, sdk.Dec) {
+	symbol, midPrice := mi.GetSymbol(), mi.LastExecutedPrice
 	orderKeeper := keepers.NewOrderKeeper(keeper.GetMarketKey(), symbol, types.ModuleCdc)
 	asKeeper := keeper.GetAssetKeeper()
 	bxKeeper := keeper.GetBankxKeeper()
+	ratio := marketParams.MaxExecutedPriceChangeRatio
 	lowPrice := midPrice.Mul(sdk.NewDec(100 - ratio)).Quo(sdk.NewDec(100))
 	highPrice := midPrice.Mul(sdk.NewDec(100 + ratio)).Quo(sdk.NewDec(100))
 
 	infoForDeal := &InfoForDeal{
-		bxKeeper:      bxKeeper,
-		dataHash:      dataHash,
-		changedOrders: make(map[string]*types.Order),
-		context:       ctx,
-		lastPrice:     sdk.NewDec(0),
-		msgSender:     keeper.GetMsgProducer(),
+		bxKeeper:       bxKeeper,
+		dataHash:       dataHash,
+		changedOrders:  make(map[string]*types.Order),
+		rejectedOrders: make(map[string]*types.Order),
+		delReasons:     make(map[string]string),
+		context:        ctx,
+		lastPrice:      sdk.NewDec(0),
+		candle:         types.NewCandle(symbol, types.CandleSpanBlock, currHeight),
+		tradedIndexes:  make(map[string]int),
+		recordTrades:   marketParams.TradeRetention > 0,
+		msgSender:      keeper.GetMsgProducer(),
 	}
 
 	// from the order book, we fetch the candidate orders for matching and filter them
@@ -272,21 +413,75 @@ func runMatch(ctx sdk.Context, midPrice sdk.Dec, ratio int64, symbol string, kee
 			askList = append(askList, wrappedOrder)
 		}
 	}
-	// call the match engine
-	match.Match(highPrice, midPrice, lowPrice, bidList, askList)
+	// call the match engine with the algorithm of this market
+	match.MatchWith(match.GetAlgorithm(mi.MatchingAlgorithm), highPrice, midPrice, lowPrice, bidList, askList)
+	keepers.NewCandleKeeper(keeper.GetMarketKey(), types.ModuleCdc).Update(ctx, infoForDeal.candle, marketParams.CandleRetention)
+	keepers.NewTradeKeeper(keeper.GetMarketKey(), types.ModuleCdc).AddTrades(ctx, symbol, infoForDeal.trades)
+	for _, ta := range infoForDeal.tradedAmounts {
+		volume := keeper.GetMarketVolume(ctx, stock, money, sdk.NewDecFromInt(ta.stock), sdk.NewDecFromInt(ta.money))
+		keeper.AddUserVolume(ctx, ta.trader, volume.TruncateInt())
+	}
 
-	// both dealt orders and IOC order need further processing
+	// dealt orders, rejected orders, IOC orders and FOK orders need further processing
 	ordersForUpdate := infoForDeal.changedOrders
-	for _, order := range orderKeeper.GetOrdersAtHeight(ctx, currHeight) {
-		if order.TimeInForce == types.IOC {
-			// if an IOC order is not included, we include it
+	for id, order := range infoForDeal.rejectedOrders {
+		ordersForUpdate[id] = order
+	}
+	addImmediateOrders(ctx, orderKeeper, currHeight, triggered, ordersForUpdate)
+
+	return ordersForUpdate, infoForDeal.delReasons, infoForDeal.lastPrice
+}
+
+// the IOC and FOK orders can not stay in the order book, so they are removed at the end of the block.
+// the stop orders triggered in this block are handled as if they were created in this block
+func addImmediateOrders(ctx sdk.Context, orderKeeper keepers.OrderKeeper, currHeight int64,
+	triggered []*types.Order, ordersForUpdate map[string]*types.Order) {
+	for _, order := range append(orderKeeper.GetOrdersAtHeight(ctx, currHeight), triggered...) {
+		if order.IsImmediateOrder() && !order.IsDormant() {
+			// if an IOC/FOK order is not included, we include it
 			if _, ok := ordersForUpdate[order.OrderID()]; !ok {
 				ordersForUpdate[order.OrderID()] = order
 			}
 		}
 	}
+}
 
-	return ordersForUpdate, infoForDeal.lastPrice
+// runMatchWithStatus matches the orders of an open or auction-only market. If the circuit breaker is
+// enabled, the match runs in a cached context, which is dropped when the new price moves too much
+// from the reference price, and then the market is halted.
+func runMatchWithStatus(ctx sdk.Context, mi types.MarketInfo, marketParams *types.Params, keeper keepers.Keeper,
+	dataHash []byte, currHeight int64, triggered []*types.Order) (map[string]*types.Order, map[string]string, sdk.Dec) {
+	symbol := mi.GetSymbol()
+	status := keeper.GetMarketStatus(ctx, symbol)
+	if status.Status == types.MarketAuctionOnly {
+		mi.MatchingAlgorithm = types.CallAuction
+	}
+	if marketParams.CircuitBreakerRatio == 0 {
+		return runMatch(ctx, mi, marketParams, keeper, dataHash, currHeight, triggered)
+	}
+
+	cacheCtx, write := ctx.CacheContext()
+	oUpdate, oRejected, newPrice := runMatch(cacheCtx, mi, marketParams, keeper, dataHash, currHeight, triggered)
+	if newPrice.IsZero() {
+		write()
+		ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+		return oUpdate, oRejected, newPrice
+	}
+	refChanged := status.UpdateRefPrice(currHeight, marketParams.CircuitBreakerBlocks, mi.LastExecutedPrice, newPrice)
+	if marketParams.ExceedsCircuitBreaker(status.RefPrice, newPrice) {
+		changeMarketStatus(ctx, keeper, symbol, status, types.MarketHalted, types.StatusChangedByCircuitBreaker)
+		orderKeeper := keepers.NewOrderKeeper(keeper.GetMarketKey(), symbol, types.ModuleCdc)
+		orderKeeper.UnmarkNewlyAdded(ctx)
+		oUpdate = make(map[string]*types.Order)
+		addImmediateOrders(ctx, orderKeeper, currHeight, triggered, oUpdate)
+		return oUpdate, nil, sdk.ZeroDec()
+	}
+	if refChanged {
+		keeper.SetMarketStatus(ctx, symbol, status)
+	}
+	write()
+	ctx.EventManager().EmitEvents(cacheCtx.EventManager().Events())
+	return oUpdate, oRejected, newPrice
 }
 
 func removeExpiredOrder(ctx sdk.Context, keeper keepers.Keeper, marketInfoList []types.MarketInfo, marketParams *types.Params) {
@@ -295,21 +490,38 @@ func removeExpiredOrder(ctx sdk.Context, keeper keepers.Keeper, marketInfoList [
 	for _, mi := range marketInfoList {
 		orderKeeper := keepers.NewOrderKeeper(keeper.GetMarketKey(), mi.GetSymbol(), types.ModuleCdc)
 		oldOrders := orderKeeper.GetOlderThan(ctx, currHeight)
+		params := mi.GetEffectiveParams(*marketParams)
 
 		for _, order := range oldOrders {
 			if order.Height+order.ExistBlocks > currHeight {
 				continue
 			}
-			removeOrder(ctx, orderKeeper, bankxKeeper, keeper, order, marketParams)
+			removeOrder(ctx, orderKeeper, bankxKeeper, keeper, order, &params)
 			if keeper.IsSubScribed(types.Topic) {
 				cancelOrderInfo := packageCancelOrderMsgWithDelReason(ctx, order,
-					types.CancelOrderByGteTimeOut, marketParams, keeper)
+					types.CancelOrderByGteTimeOut, &params, keeper)
 				msgqueue.FillMsgs(ctx, types.CancelOrderInfoKey, cancelOrderInfo)
 			}
 		}
 	}
 }
 
+// remove the orders which reach their expire time, it runs in every block before matching
+func removeOrdersByExpireTime(ctx sdk.Context, keeper keepers.Keeper) {
+	globalKeeper := keepers.NewGlobalOrderKeeper(keeper.GetMarketKey(), types.ModuleCdc)
+	bankxKeeper := keeper.GetBankxKeeper()
+	for _, order := range globalKeeper.GetExpiredOrders(ctx, ctx.BlockHeader().Time.Unix()) {
+		orderKeeper := keepers.NewOrderKeeper(keeper.GetMarketKey(), order.TradingPair, types.ModuleCdc)
+		params := keeper.GetMarketParams(ctx, order.TradingPair)
+		removeOrder(ctx, orderKeeper, bankxKeeper, keeper, order, &params)
+		if keeper.IsSubScribed(types.Topic) {
+			cancelOrderInfo := packageCancelOrderMsgWithDelReason(ctx, order,
+				types.CancelOrderByExpireTime, &params, keeper)
+			msgqueue.FillMsgs(ctx, types.CancelOrderInfoKey, cancelOrderInfo)
+		}
+	}
+}
+
 func removeExpiredMarket(ctx sdk.Context, keeper keepers.Keeper, marketParams *types.Params) {
 	currHeight := ctx.BlockHeight()
 	currTime := ctx.BlockHeader().Time.UnixNano()
@@ -321,15 +533,21 @@ func removeExpiredMarket(ctx sdk.Context, keeper keepers.Keeper, marketParams *t
 	for _, symbol := range delistSymbols {
 		orderKeeper := keepers.NewOrderKeeper(keeper.GetMarketKey(), symbol, types.ModuleCdc)
 		oldOrders := orderKeeper.GetOlderThan(ctx, currHeight+1)
+		params := *marketParams
+		if mi, err := keeper.GetMarketInfo(ctx, symbol); err == nil {
+			params = mi.GetEffectiveParams(*marketParams)
+		}
 		for _, ord := range oldOrders {
-			removeOrder(ctx, orderKeeper, bankxKeeper, keeper, ord, marketParams)
+			removeOrder(ctx, orderKeeper, bankxKeeper, keeper, ord, &params)
 			if keeper.IsSubScribed(types.Topic) {
 				cancelOrderInfo := packageCancelOrderMsgWithDelReason(ctx, ord,
-					types.CancelOrderByGteTimeOut, marketParams, keeper)
+					types.CancelOrderByGteTimeOut, &params, keeper)
 				msgqueue.FillMsgs(ctx, types.CancelOrderInfoKey, cancelOrderInfo)
 			}
 		}
 		keeper.RemoveMarket(ctx, symbol)
+		keepers.NewCandleKeeper(keeper.GetMarketKey(), types.ModuleCdc).RemoveAllCandles(ctx, symbol)
+		keepers.NewTradeKeeper(keeper.GetMarketKey(), types.ModuleCdc).RemoveAllTrades(ctx, symbol)
 	}
 	delistKeeper.RemoveDelistRequestsBeforeTime(ctx, currTime)
 }
@@ -341,6 +559,9 @@ func EndBlocker(ctx sdk.Context, keeper keepers.Keeper) /*sdk.Tags*/ {
 	recordTime := keeper.GetOrderCleanTime(ctx)
 	currTime := ctx.BlockHeader().Time.Unix()
 
+	removeOrdersByExpireTime(ctx, keeper)
+	keepers.NewTradeKeeper(keeper.GetMarketKey(), types.ModuleCdc).PruneTrades(ctx, marketParams.TradeRetention)
+
 	var needRemove bool
 	if !strings.Contains(chainID, IntegrationNetSubString) {
 		if time.Unix(recordTime, 0).UTC().Day() != time.Unix(currTime, 0).UTC().Day() {
@@ -375,6 +596,7 @@ func EndBlocker(ctx sdk.Context, keeper keepers.Keeper) /*sdk.Tags*/ {
 	}
 	currHeight := ctx.BlockHeight()
 	ordersForUpdateList := make([]map[string]*types.Order, len(marketInfoList))
+	ordersRejectedList := make([]map[string]string, len(marketInfoList))
 	newPrices := make([]sdk.Dec, len(marketInfoList))
 	for idx, mi := range marketInfoList {
 		// if a token is globally forbidden, exchange it is also impossible
@@ -384,10 +606,21 @@ func EndBlocker(ctx sdk.Context, keeper keepers.Keeper) /*sdk.Tags*/ {
 		}
 		symbol := mi.GetSymbol()
 		dataHash := ctx.BlockHeader().DataHash
-		ratio := marketParams.MaxExecutedPriceChangeRatio
-		oUpdate, newPrice := runMatch(ctx, mi.LastExecutedPrice, ratio, symbol, keeper, dataHash, currHeight)
+		orderKeeper := keepers.NewOrderKeeper(keeper.GetMarketKey(), symbol, types.ModuleCdc)
+		if !keeper.GetMarketStatus(ctx, symbol).IsMatching() {
+			// no orders are matched or triggered in a halted market, except that the IOC and FOK orders are cancelled
+			orderKeeper.UnmarkNewlyAdded(ctx)
+			ordersForUpdateList[idx] = make(map[string]*types.Order)
+			addImmediateOrders(ctx, orderKeeper, currHeight, nil, ordersForUpdateList[idx])
+			newPrices[idx] = sdk.ZeroDec()
+			continue
+		}
+		triggered := triggerStopOrders(ctx, keeper, orderKeeper, mi.LastExecutedPrice)
+		params := mi.GetEffectiveParams(marketParams)
+		oUpdate, oRejected, newPrice := runMatchWithStatus(ctx, mi, &params, keeper, dataHash, currHeight, triggered)
 		newPrices[idx] = newPrice
 		ordersForUpdateList[idx] = oUpdate
+		ordersRejectedList[idx] = oRejected
 	}
 	for idx, mi := range marketInfoList {
 		// ignore a market if there are no orders need further processing
@@ -396,32 +629,42 @@ func EndBlocker(ctx sdk.Context, keeper keepers.Keeper) /*sdk.Tags*/ {
 		}
 		bankxKeeper := keeper.GetBankxKeeper()
 		orderKeeper := keepers.NewOrderKeeper(keeper.GetMarketKey(), mi.GetSymbol(), types.ModuleCdc)
+		params := mi.GetEffectiveParams(marketParams)
 		// update the order book
-		for _, order := range ordersForUpdateList[idx] {
+		replenished := false
+		for id, order := range ordersForUpdateList[idx] {
+			delReason, rejected := ordersRejectedList[idx][id]
+			removed := order.IsImmediateOrder() || order.LeftStock == 0 || notEnoughMoney(order) || rejected
+			if !removed && order.Replenish(currHeight) {
+				replenished = true
+			}
 			orderKeeper.Update(ctx, order)
-			if order.TimeInForce == types.IOC || order.LeftStock == 0 || notEnoughMoney(order) {
-				removeOrder(ctx, orderKeeper, bankxKeeper, keeper, order, &marketParams)
+			if removed {
+				removeOrder(ctx, orderKeeper, bankxKeeper, keeper, order, &params)
 				if keeper.IsSubScribed(types.Topic) {
-					cancelOrderInfo := packageCancelOrderMsg(ctx, order, &marketParams, keeper)
+					cancelOrderInfo := packageCancelOrderMsgWithDelReason(ctx, order, delReason, &params, keeper)
 					msgqueue.FillMsgs(ctx, types.CancelOrderInfoKey, cancelOrderInfo)
 				}
 			}
 		}
+		// the replenished iceberg orders will be matched again in the next block
+		if replenished {
+			orderKeeper.MarkNewlyAdded(ctx)
+		}
 		// if some orders dealt, update last executed price of this market
 		if !newPrices[idx].IsZero() {
 			mi.LastExecutedPrice = newPrices[idx]
 			keeper.SetMarket(ctx, mi)
+			// the stop orders triggered by the new price will be activated in the next block
+			if len(orderKeeper.GetOrdersToTrigger(ctx, mi.LastExecutedPrice)) != 0 {
+				orderKeeper.MarkNewlyAdded(ctx)
+			}
 		}
 	}
 }
 
-func packageCancelOrderMsg(ctx sdk.Context, order *types.Order,
-	marketParams *Params, keeper types.ExpectedAuthXKeeper) types.CancelOrderInfo {
-	return packageCancelOrderMsgWithDelReason(ctx, order, "", marketParams, keeper)
-}
-
 func packageCancelOrderMsgWithDelReason(ctx sdk.Context, order *types.Order, delReason string,
-	marketParams *Params, keeper types.ExpectedAuthXKeeper) types.CancelOrderInfo {
+	marketParams *Params, keeper types.Keeper) types.CancelOrderInfo {
 	currentHeight := ctx.BlockHeight()
 	msgInfo := types.CancelOrderInfo{
 		OrderID:        order.OrderID(),
@@ -429,7 +672,7 @@ func packageCancelOrderMsgWithDelReason(ctx sdk.Context, order *types.Order, del
 		Side:           order.Side,
 		Height:         currentHeight,
 		Price:          order.Price,
-		UsedCommission: order.CalActualOrderCommissionInt64(marketParams.FeeForZeroDeal),
+		UsedCommission: calActualOrderCommission(ctx, order, marketParams, keeper),
 		UsedFeatureFee: order.CalActualOrderFeatureFeeInt64(ctx, marketParams.GTEOrderLifetime),
 		LeftStock:      order.LeftStock,
 		RemainAmount:   order.Freeze,
@@ -457,11 +700,17 @@ func getCancelOrderReason(order *types.Order, delReason string) string {
 	if order.TimeInForce == types.IOC {
 		return types.CancelOrderByIocType
 	}
+	if order.TimeInForce == types.FOK && order.LeftStock != 0 {
+		return types.CancelOrderByFokNotFilled
+	}
 	if order.LeftStock == 0 {
 		return types.CancelOrderByAllFilled
 	}
 	if notEnoughMoney(order) {
 		return types.CancelOrderByNoEnoughMoney
 	}
+	if order.TimeInForce == types.PostOnly {
+		return types.CancelOrderByPostOnlyCross
+	}
 	return types.CancelOrderByNotKnow
 }
@@ -654,6 +654,581 @@ func TestEndBlocker(t *testing.T) {
 	require.EqualValues(t, sdk.NewDec(96).String(), mkInfo.LastExecutedPrice.String())
 }
 
+func TestTriggerStopOrder(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+	glk := keepers.NewGlobalOrderKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+
+	seller, _ := simpleAddr("00001")
+	buyer, _ := simpleAddr("00002")
+	sellOrder := Order{
+		LeftStock:   150,
+		Price:       sdk.NewDec(101),
+		Sender:      seller,
+		Sequence:    1,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      150,
+	}
+	buyOrder := Order{
+		LeftStock:   50,
+		Price:       sdk.NewDec(101),
+		Sender:      buyer,
+		Sequence:    2,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        BUY,
+		TimeInForce: GTE,
+		Freeze:      50 * 101,
+	}
+	stopOrder := Order{
+		LeftStock:    100,
+		Price:        sdk.NewDec(105),
+		TriggerPrice: sdk.NewDec(101),
+		Sender:       buyer,
+		Sequence:     3,
+		TradingPair:  mkInfo.GetSymbol(),
+		OrderType:    StopLimitOrder,
+		Height:       900,
+		Side:         BUY,
+		TimeInForce:  GTE,
+		Freeze:       100 * 105,
+	}
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	orderKeeper.Add(input.ctx, &stopOrder)
+
+	// the stop order is not triggered by the old price
+	EndBlocker(input.ctx, input.mk)
+	mkInfo, err := input.mk.GetMarketInfo(input.ctx, mkInfo.GetSymbol())
+	require.Nil(t, err)
+	require.EqualValues(t, sdk.NewDec(101).String(), mkInfo.LastExecutedPrice.String())
+	require.Nil(t, glk.QueryOrder(input.ctx, buyOrder.OrderID()))
+	require.True(t, glk.QueryOrder(input.ctx, stopOrder.OrderID()).IsDormant())
+	require.Equal(t, []string{mkInfo.GetSymbol()}, input.mk.GetMarketsWithNewlyAddedOrder(input.ctx))
+
+	// the new price triggers the stop order in the next block
+	input.ctx = input.ctx.WithBlockHeight(1001)
+	EndBlocker(input.ctx, input.mk)
+	require.Nil(t, glk.QueryOrder(input.ctx, stopOrder.OrderID()))
+	require.Nil(t, glk.QueryOrder(input.ctx, sellOrder.OrderID()))
+	require.Equal(t, 0, len(input.mk.GetMarketsWithNewlyAddedOrder(input.ctx)))
+}
+
+func TestCandlesInEndBlocker(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+	candleKeeper := keepers.NewCandleKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+	seller, _ := simpleAddr("00001")
+	buyer, _ := simpleAddr("00002")
+	sellOrder := Order{
+		LeftStock:   150,
+		Price:       sdk.NewDec(101),
+		Sender:      seller,
+		Sequence:    1,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      150,
+	}
+	buyOrder := Order{
+		LeftStock:   50,
+		Price:       sdk.NewDec(101),
+		Sender:      buyer,
+		Sequence:    2,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        BUY,
+		TimeInForce: GTE,
+		Freeze:      50 * 101,
+	}
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	EndBlocker(input.ctx, input.mk)
+
+	for _, span := range types.CandleSpans {
+		candles := candleKeeper.GetCandles(input.ctx, mkInfo.GetSymbol(), span, 10)
+		require.Equal(t, 1, len(candles))
+		require.Equal(t, sdk.NewDec(101), candles[0].Close)
+		require.Equal(t, sdk.NewInt(50), candles[0].StockVolume)
+		require.Equal(t, sdk.NewInt(50*101), candles[0].MoneyVolume)
+		require.EqualValues(t, 1, candles[0].TradeCount)
+	}
+	ticker := candleKeeper.GetTicker(input.ctx, mkInfo.GetSymbol())
+	require.Equal(t, sdk.NewDec(101), ticker.Open)
+	require.EqualValues(t, 1, ticker.TradeCount)
+}
+
+func TestTradesInEndBlocker(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	params := input.mk.GetParams(input.ctx)
+	params.TradeRetention = 100
+	input.mk.SetParams(input.ctx, params)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+	tradeKeeper := keepers.NewTradeKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+	seller, _ := simpleAddr("00001")
+	buyer, _ := simpleAddr("00002")
+	sellOrder := Order{
+		LeftStock:   150,
+		Price:       sdk.NewDec(101),
+		Sender:      seller,
+		Sequence:    1,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      150,
+	}
+	buyOrder := Order{
+		LeftStock:   50,
+		Price:       sdk.NewDec(101),
+		Sender:      buyer,
+		Sequence:    2,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        BUY,
+		TimeInForce: GTE,
+		Freeze:      50 * 101,
+	}
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	EndBlocker(input.ctx, input.mk)
+
+	// the buy order is filled and removed, but its trade is kept
+	require.Nil(t, keepers.NewGlobalOrderKeeper(input.mk.GetMarketKey(), types.ModuleCdc).QueryOrder(input.ctx, buyOrder.OrderID()))
+	trades, _ := tradeKeeper.GetTrades(input.ctx, mkInfo.GetSymbol(), keepers.PageRequest{})
+	require.Equal(t, 1, len(trades))
+	require.Equal(t, sdk.NewDec(101), trades[0].Price)
+	require.EqualValues(t, 50, trades[0].Amount)
+	require.EqualValues(t, 50*101, trades[0].Money)
+	require.EqualValues(t, 1000, trades[0].Height)
+	require.Equal(t, buyOrder.OrderID(), trades[0].BuyOrderID)
+	require.Equal(t, sellOrder.OrderID(), trades[0].SellOrderID)
+	userTrades, _ := tradeKeeper.GetAccountTrades(input.ctx, buyer.String(), keepers.PageRequest{})
+	require.Equal(t, trades, userTrades)
+	orderTrades, _ := tradeKeeper.GetOrderTrades(input.ctx, buyOrder.OrderID(), keepers.PageRequest{})
+	require.Equal(t, trades, orderTrades)
+
+	// the trade is pruned after 'trade_retention' blocks, even if there are no new orders
+	input.ctx = input.ctx.WithBlockHeight(1099)
+	EndBlocker(input.ctx, input.mk)
+	trades, _ = tradeKeeper.GetTrades(input.ctx, mkInfo.GetSymbol(), keepers.PageRequest{})
+	require.Equal(t, 1, len(trades))
+	input.ctx = input.ctx.WithBlockHeight(1100)
+	EndBlocker(input.ctx, input.mk)
+	trades, _ = tradeKeeper.GetTrades(input.ctx, mkInfo.GetSymbol(), keepers.PageRequest{})
+	require.Equal(t, 0, len(trades))
+	userTrades, _ = tradeKeeper.GetAccountTrades(input.ctx, buyer.String(), keepers.PageRequest{})
+	require.Equal(t, 0, len(userTrades))
+}
+
+func TestOrdersExpireInEndBlocker(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1000, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1000)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+	globalKeeper := keepers.NewGlobalOrderKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+	seller, _ := simpleAddr("00001")
+	buyer, _ := simpleAddr("00002")
+	sellOrder := Order{
+		LeftStock:   150,
+		Price:       sdk.NewDec(105),
+		Sender:      seller,
+		Sequence:    1,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        SELL,
+		TimeInForce: GTE,
+		ExistBlocks: 10000,
+		ExpireTime:  1010,
+	}
+	buyOrder := Order{
+		LeftStock:   50,
+		Price:       sdk.NewDec(101),
+		Sender:      buyer,
+		Sequence:    2,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        BUY,
+		TimeInForce: GTE,
+		ExistBlocks: 10000,
+	}
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+
+	// the orders are kept before the expire time
+	EndBlocker(input.ctx, input.mk)
+	require.NotNil(t, globalKeeper.QueryOrder(input.ctx, sellOrder.OrderID()))
+	require.NotNil(t, globalKeeper.QueryOrder(input.ctx, buyOrder.OrderID()))
+
+	// the sell order is cancelled once the block time reaches its expire time
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1010, 0)).WithBlockHeight(1001)
+	EndBlocker(input.ctx, input.mk)
+	require.Nil(t, globalKeeper.QueryOrder(input.ctx, sellOrder.OrderID()))
+	require.NotNil(t, globalKeeper.QueryOrder(input.ctx, buyOrder.OrderID()))
+	require.Equal(t, 0, len(globalKeeper.GetExpiredOrders(input.ctx, 2000)))
+}
+
+func TestSelfTradePreventionInEndBlocker(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+	globalKeeper := keepers.NewGlobalOrderKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+	trader, _ := simpleAddr("00001")
+	sellOrder := Order{
+		LeftStock:   150,
+		Price:       sdk.NewDec(101),
+		Sender:      trader,
+		Sequence:    1,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      150,
+	}
+	buyOrder := Order{
+		LeftStock:   50,
+		Price:       sdk.NewDec(101),
+		Sender:      trader,
+		Sequence:    2,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      1000,
+		Side:        BUY,
+		TimeInForce: GTE,
+		Freeze:      50 * 101,
+
+		SelfTradePrevention: SelfTradeDecrementAndCancel,
+	}
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	EndBlocker(input.ctx, input.mk)
+
+	// the buy order is cancelled, and the sell order is decreased by the same amount without any deal
+	require.Nil(t, globalKeeper.QueryOrder(input.ctx, buyOrder.OrderID()))
+	order := globalKeeper.QueryOrder(input.ctx, sellOrder.OrderID())
+	require.NotNil(t, order)
+	require.EqualValues(t, 100, order.LeftStock)
+	require.EqualValues(t, 100, order.Freeze)
+	require.EqualValues(t, 0, order.DealStock)
+	mkInfo, _ = input.mk.GetMarketInfo(input.ctx, mkInfo.GetSymbol())
+	require.Equal(t, sdk.NewDec(100), mkInfo.LastExecutedPrice)
+}
+
+func TestSelfTradePreventionWithIcebergOrder(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+	globalKeeper := keepers.NewGlobalOrderKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+	trader, _ := simpleAddr("00001")
+	icebergOrder := Order{
+		Quantity:    150,
+		LeftStock:   150,
+		Price:       sdk.NewDec(101),
+		Sender:      trader,
+		Sequence:    1,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      150,
+
+		DisplayQuantity: 30,
+		VisibleStock:    30,
+	}
+	buyOrder := Order{
+		LeftStock:   50,
+		Price:       sdk.NewDec(101),
+		Sender:      trader,
+		Sequence:    2,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      1000,
+		Side:        BUY,
+		TimeInForce: GTE,
+		Freeze:      50 * 101,
+
+		SelfTradePrevention: SelfTradeDecrementAndCancel,
+	}
+	orderKeeper.Add(input.ctx, &icebergOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	EndBlocker(input.ctx, input.mk)
+
+	// the visible slice of the iceberg order is cancelled and replenished from its hidden remainder
+	order := globalKeeper.QueryOrder(input.ctx, icebergOrder.OrderID())
+	require.NotNil(t, order)
+	require.EqualValues(t, 120, order.LeftStock)
+	require.EqualValues(t, 120, order.Freeze)
+	require.EqualValues(t, 30, order.VisibleStock)
+	require.EqualValues(t, 1000, order.RefreshHeight)
+	require.EqualValues(t, 0, order.DealStock)
+	order = globalKeeper.QueryOrder(input.ctx, buyOrder.OrderID())
+	require.NotNil(t, order)
+	require.EqualValues(t, 20, order.LeftStock)
+	require.EqualValues(t, 0, order.DealStock)
+}
+
+func TestIcebergOrderInEndBlocker(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+	globalKeeper := keepers.NewGlobalOrderKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+	seller, _ := simpleAddr("00001")
+	buyer, _ := simpleAddr("00002")
+	icebergOrder := Order{
+		Quantity:    100,
+		LeftStock:   100,
+		Price:       sdk.NewDec(100),
+		Sender:      seller,
+		Sequence:    1,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      100,
+
+		DisplayQuantity: 30,
+		VisibleStock:    30,
+	}
+	sellOrder := Order{
+		Quantity:    20,
+		LeftStock:   20,
+		Price:       sdk.NewDec(100),
+		Sender:      seller,
+		Sequence:    2,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      950,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      20,
+	}
+	buyOrder := Order{
+		Quantity:    50,
+		LeftStock:   50,
+		Price:       sdk.NewDec(100),
+		Sender:      buyer,
+		Sequence:    3,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      1000,
+		Side:        BUY,
+		TimeInForce: GTE,
+		Freeze:      50 * 100,
+	}
+	orderKeeper.Add(input.ctx, &icebergOrder)
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	EndBlocker(input.ctx, input.mk)
+
+	// only the visible slice of the iceberg order is dealt, and then it is replenished
+	require.Nil(t, globalKeeper.QueryOrder(input.ctx, buyOrder.OrderID()))
+	require.Nil(t, globalKeeper.QueryOrder(input.ctx, sellOrder.OrderID()))
+	order := globalKeeper.QueryOrder(input.ctx, icebergOrder.OrderID())
+	require.EqualValues(t, 70, order.LeftStock)
+	require.EqualValues(t, 70, order.Freeze)
+	require.EqualValues(t, 30, order.VisibleStock)
+	require.EqualValues(t, 1000, order.RefreshHeight)
+	require.EqualValues(t, 30, order.MakerDealStock)
+	require.Equal(t, []string{mkInfo.GetSymbol()}, input.mk.GetMarketsWithNewlyAddedOrder(input.ctx))
+
+	// the replenished slice has lost its time priority to the order which arrived earlier
+	input.ctx = input.ctx.WithBlockHeight(1001)
+	sellOrder.Sequence, sellOrder.Height = 4, 999
+	buyOrder.Sequence, buyOrder.Height, buyOrder.LeftStock = 5, 1001, 40
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	EndBlocker(input.ctx, input.mk)
+	require.Nil(t, globalKeeper.QueryOrder(input.ctx, buyOrder.OrderID()))
+	require.Nil(t, globalKeeper.QueryOrder(input.ctx, sellOrder.OrderID()))
+	order = globalKeeper.QueryOrder(input.ctx, icebergOrder.OrderID())
+	require.EqualValues(t, 50, order.LeftStock)
+	require.EqualValues(t, 30, order.VisibleStock)
+	require.EqualValues(t, 1001, order.RefreshHeight)
+}
+
+func TestContinuousMatchingInEndBlocker(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+		MatchingAlgorithm: types.ContinuousMatching,
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+	seller, _ := simpleAddr("00001")
+	buyer, _ := simpleAddr("00002")
+	sellOrder := Order{
+		LeftStock:   150,
+		Price:       sdk.NewDec(98),
+		Sender:      seller,
+		Sequence:    1,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      150,
+	}
+	buyOrder := Order{
+		LeftStock:   50,
+		Price:       sdk.NewDec(101),
+		Sender:      buyer,
+		Sequence:    2,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      1000,
+		Side:        BUY,
+		TimeInForce: GTE,
+		Freeze:      50 * 101,
+	}
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	EndBlocker(input.ctx, input.mk)
+
+	// the buyer takes liquidity at the price of the seller
+	mkInfo, err := input.mk.GetMarketInfo(input.ctx, mkInfo.GetSymbol())
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(98), mkInfo.LastExecutedPrice)
+	require.Equal(t, types.ContinuousMatching, mkInfo.MatchingAlgorithm)
+	glk := keepers.NewGlobalOrderKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+	order := glk.QueryOrder(input.ctx, sellOrder.OrderID())
+	require.EqualValues(t, 100, order.LeftStock)
+	require.EqualValues(t, 50*98, order.DealMoney)
+	require.Nil(t, glk.QueryOrder(input.ctx, buyOrder.OrderID()))
+}
+
+func TestPostOnlyAndFillOrKillOrder(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+	glk := keepers.NewGlobalOrderKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+
+	seller, _ := simpleAddr("00001")
+	buyer, _ := simpleAddr("00002")
+	sellOrder := Order{
+		LeftStock:   100,
+		Price:       sdk.NewDec(100),
+		Sender:      seller,
+		Sequence:    1,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      900,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      100,
+	}
+	postOnlyOrder := Order{
+		LeftStock:   50,
+		Price:       sdk.NewDec(101),
+		Sender:      buyer,
+		Sequence:    2,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      1000,
+		Side:        BUY,
+		TimeInForce: types.PostOnly,
+		Freeze:      50 * 101,
+	}
+	fokOrder := Order{
+		LeftStock:   150,
+		Price:       sdk.NewDec(100),
+		Sender:      buyer,
+		Sequence:    3,
+		TradingPair: mkInfo.GetSymbol(),
+		Height:      1000,
+		Side:        BUY,
+		TimeInForce: types.FOK,
+		Freeze:      150 * 100,
+	}
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &postOnlyOrder)
+	orderKeeper.Add(input.ctx, &fokOrder)
+
+	EndBlocker(input.ctx, input.mk)
+	require.Nil(t, glk.QueryOrder(input.ctx, postOnlyOrder.OrderID()))
+	require.Nil(t, glk.QueryOrder(input.ctx, fokOrder.OrderID()))
+	require.EqualValues(t, 100, glk.QueryOrder(input.ctx, sellOrder.OrderID()).LeftStock)
+	require.Equal(t, types.CancelOrderByPostOnlyCross, getCancelOrderReason(&postOnlyOrder, ""))
+	require.Equal(t, types.CancelOrderByFokNotFilled, getCancelOrderReason(&fokOrder, ""))
+	fokOrder.LeftStock = 0
+	require.Equal(t, types.CancelOrderByAllFilled, getCancelOrderReason(&fokOrder, ""))
+}
+
 func TestLeastAbsImbalance(t *testing.T) {
 	input := prepareMockInput(t, false, false)
 	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
@@ -1024,6 +1599,76 @@ func TestCalFrozenFeatureFee(t *testing.T) {
 
 }
 
+func TestMakerAndTakerCommission(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	params := input.mk.GetParams(input.ctx)
+	params.MakerFeeRate = types.DefaultMakerFeeRate
+	params.MarketFeeMin = 10
+	input.mk.SetParams(input.ctx, params)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+	seller, _ := simpleAddr("00001")
+	buyer, _ := simpleAddr("00002")
+	sellAccount := input.akp.NewAccountWithAddress(input.ctx, seller)
+	buyAccount := input.akp.NewAccountWithAddress(input.ctx, buyer)
+	require.Nil(t, sellAccount.SetCoins(sdk.NewCoins(sdk.NewCoin(stock, sdk.NewInt(10000)),
+		sdk.NewCoin(dex.CET, sdk.NewInt(10000)))))
+	require.Nil(t, buyAccount.SetCoins(sdk.NewCoins(sdk.NewCoin(stock, sdk.NewInt(10000)),
+		sdk.NewCoin(dex.CET, sdk.NewInt(10000)))))
+	input.akp.SetAccount(input.ctx, sellAccount)
+	input.akp.SetAccount(input.ctx, buyAccount)
+
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), GetSymbol(stock, dex.CET), types.ModuleCdc)
+	// the sell order rested in the order book, and the buy order enters in this block
+	sellOrder := Order{
+		Sender:           seller,
+		Sequence:         1,
+		TradingPair:      mkInfo.GetSymbol(),
+		LeftStock:        25,
+		Quantity:         25,
+		Price:            sdk.NewDec(100),
+		Freeze:           25,
+		FrozenCommission: 100,
+		Height:           900,
+		Side:             SELL,
+		TimeInForce:      GTE,
+	}
+	buyOrder := Order{
+		Sender:           buyer,
+		Sequence:         1,
+		TradingPair:      mkInfo.GetSymbol(),
+		LeftStock:        25,
+		Quantity:         25,
+		Price:            sdk.NewDec(100),
+		Freeze:           25 * 100,
+		FrozenCommission: 100,
+		Height:           1000,
+		Side:             BUY,
+		TimeInForce:      GTE,
+	}
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	EndBlocker(input.ctx, input.mk)
+
+	sellAccount = input.akp.GetAccount(input.ctx, seller)
+	buyAccount = input.akp.GetAccount(input.ctx, buyer)
+	require.EqualValues(t, 10000+2500-100*types.DefaultMakerFeeRate/types.DefaultMarketFeeRate,
+		sellAccount.GetCoins().AmountOf(dex.CET).Int64())
+	require.EqualValues(t, 10000-2500-100, buyAccount.GetCoins().AmountOf(dex.CET).Int64())
+
+	// the volumes are measured in CET
+	require.Equal(t, sdk.NewInt(2500), input.mk.GetUserVolume(input.ctx, seller))
+	require.Equal(t, sdk.NewInt(2500), input.mk.GetUserVolume(input.ctx, buyer))
+}
+
 func TestChargeFee(t *testing.T) {
 	keeper := &mockKeeper{}
 	ctx := sdk.Context{}
@@ -1056,3 +1701,88 @@ func TestChargeFee(t *testing.T) {
 	keeper.cleanRecord()
 
 }
+
+func TestCircuitBreakerInEndBlocker(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	input.ctx = input.ctx.WithChainID(IntegrationNetSubString + "01")
+	input.ctx = input.ctx.WithBlockTime(time.Unix(1, 0)).WithBlockHeight(1000)
+	input.mk.SetOrderCleanTime(input.ctx, 1)
+	params := input.mk.GetParams(input.ctx)
+	params.CircuitBreakerRatio = 10
+	params.CircuitBreakerBlocks = 100
+	input.mk.SetParams(input.ctx, params)
+	symbol := GetSymbol(stock, dex.CET)
+	orderKeeper := keepers.NewOrderKeeper(input.mk.GetMarketKey(), symbol, types.ModuleCdc)
+	glk := keepers.NewGlobalOrderKeeper(input.mk.GetMarketKey(), types.ModuleCdc)
+
+	mkInfo := MarketInfo{
+		Stock:             stock,
+		Money:             dex.CET,
+		LastExecutedPrice: sdk.NewDec(100),
+	}
+	input.mk.SetMarket(input.ctx, mkInfo)
+	input.mk.SetMarketStatus(input.ctx, symbol, types.MarketStatus{RefPrice: sdk.NewDec(100), RefHeight: 950})
+	seller, _ := simpleAddr("00001")
+	buyer, _ := simpleAddr("00002")
+	sellOrder := Order{
+		LeftStock:   100,
+		Price:       sdk.NewDec(115),
+		Sender:      seller,
+		Sequence:    1,
+		TradingPair: symbol,
+		Height:      1000,
+		Side:        SELL,
+		TimeInForce: GTE,
+		Freeze:      100,
+	}
+	buyOrder := Order{
+		LeftStock:   100,
+		Price:       sdk.NewDec(120),
+		Sender:      buyer,
+		Sequence:    2,
+		TradingPair: symbol,
+		Height:      1000,
+		Side:        BUY,
+		TimeInForce: GTE,
+		Freeze:      100 * 120,
+	}
+	iocOrder := buyOrder
+	iocOrder.Sequence = 3
+	iocOrder.TimeInForce = types.IOC
+	orderKeeper.Add(input.ctx, &sellOrder)
+	orderKeeper.Add(input.ctx, &buyOrder)
+	orderKeeper.Add(input.ctx, &iocOrder)
+	EndBlocker(input.ctx, input.mk)
+
+	// the match would move the price by more than 10%, so it is dropped and the market is halted
+	status := input.mk.GetMarketStatus(input.ctx, symbol)
+	require.Equal(t, types.MarketHalted, status.Status)
+	require.Equal(t, types.StatusChangedByCircuitBreaker, status.Reason)
+	mkInfo, err := input.mk.GetMarketInfo(input.ctx, symbol)
+	require.Nil(t, err)
+	require.Equal(t, sdk.NewDec(100), mkInfo.LastExecutedPrice)
+	require.EqualValues(t, 100, glk.QueryOrder(input.ctx, sellOrder.OrderID()).LeftStock)
+	require.EqualValues(t, 100, glk.QueryOrder(input.ctx, buyOrder.OrderID()).LeftStock)
+	require.Nil(t, glk.QueryOrder(input.ctx, iocOrder.OrderID()))
+	require.Empty(t, input.mk.GetMarketsWithNewlyAddedOrder(input.ctx))
+
+	// nothing is matched in the halted market
+	input.ctx = input.ctx.WithBlockHeight(1001)
+	orderKeeper.MarkNewlyAdded(input.ctx)
+	EndBlocker(input.ctx, input.mk)
+	require.EqualValues(t, 100, glk.QueryOrder(input.ctx, sellOrder.OrderID()).LeftStock)
+
+	// the first match after the market is resumed starts a new window of the circuit breaker
+	input.ctx = input.ctx.WithBlockHeight(1002)
+	ret := input.handler(input.ctx, types.MsgResumeMarket{Sender: haveCetAddress, TradingPair: symbol})
+	require.True(t, ret.IsOK(), ret.Log)
+	EndBlocker(input.ctx, input.mk)
+	status = input.mk.GetMarketStatus(input.ctx, symbol)
+	require.Equal(t, types.MarketOpen, status.Status)
+	require.EqualValues(t, 1002, status.RefHeight)
+	mkInfo, err = input.mk.GetMarketInfo(input.ctx, symbol)
+	require.Nil(t, err)
+	require.Equal(t, status.RefPrice, mkInfo.LastExecutedPrice)
+	require.True(t, mkInfo.LastExecutedPrice.GTE(sdk.NewDec(115)))
+	require.Nil(t, glk.QueryOrder(input.ctx, sellOrder.OrderID()))
+}
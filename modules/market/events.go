@@ -6,12 +6,19 @@ var (
 
 	EventTypeKeyCreateTradingPair    = "create_market"
 	EventTypeKeyCreateOrder          = "create_order"
+	EventTypeKeyCreateOrders         = "create_orders"
 	EventTypeKeyCancelOrder          = "cancel_order"
+	EventTypeKeyCancelAllOrders      = "cancel_all_orders"
+	EventTypeKeyModifyOrder          = "modify_order"
 	EventTypeKeyCancelTradingPair    = "cancel_market"
 	EventTypeKeyModifyPricePrecision = "modify_price_precision"
+	EventTypeKeyModifyMarketParams   = "modify_market_params"
+	EventTypeKeyChangeMarketStatus   = "change_market_status"
 
 	AttributeKeyTradingPair      = "trading_pair"
 	AttributeKeyOrder            = "order"
+	AttributeKeyOrders           = "orders"
+	AttributeKeyOrderCount       = "order_count"
 	AttributeKeyStock            = "stock"
 	AttributeKeyMoney            = "money"
 	AttributeKeyPricePrecision   = "price_precision"
@@ -38,4 +45,8 @@ var (
 
 	AttributeKeyOldPricePrecision = "old_price_precision"
 	AttributeKeyNewPricePrecision = "new_price_precision"
+
+	AttributeKeyOldMarketParams = "old_market_params"
+	AttributeKeyNewMarketParams = "new_market_params"
+	AttributeKeyMarketStatus    = "market_status"
 )
@@ -28,7 +28,10 @@ func NewGenesisState(params types.Params, orders []*types.Order, infos []types.M
 
 // DefaultGenesisState - Return a default genesis state
 func DefaultGenesisState() GenesisState {
-	return NewGenesisState(types.DefaultParams(), []*types.Order{}, []types.MarketInfo{}, 0)
+	// the new chains start with the discount for the makers
+	params := types.DefaultParams()
+	params.MakerFeeRate = types.DefaultMakerFeeRate
+	return NewGenesisState(params, []*types.Order{}, []types.MarketInfo{}, 0)
 }
 
 // InitGenesis - Init store state from genesis data
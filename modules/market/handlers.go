@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
@@ -23,12 +24,24 @@ func NewHandler(k keepers.Keeper) sdk.Handler {
 			return handleMsgCreateTradingPair(ctx, msg, k)
 		case types.MsgCreateOrder:
 			return handleMsgCreateOrder(ctx, msg, k)
+		case types.MsgCreateOrders:
+			return handleMsgCreateOrders(ctx, msg, k)
 		case types.MsgCancelOrder:
 			return handleMsgCancelOrder(ctx, msg, k)
+		case types.MsgCancelAllOrders:
+			return handleMsgCancelAllOrders(ctx, msg, k)
+		case types.MsgModifyOrder:
+			return handleMsgModifyOrder(ctx, msg, k)
 		case types.MsgCancelTradingPair:
 			return handleMsgCancelTradingPair(ctx, msg, k)
 		case types.MsgModifyPricePrecision:
 			return handleMsgModifyPricePrecision(ctx, msg, k)
+		case types.MsgModifyMarketParams:
+			return handleMsgModifyMarketParams(ctx, msg, k)
+		case types.MsgHaltMarket:
+			return handleMsgHaltMarket(ctx, msg, k)
+		case types.MsgResumeMarket:
+			return handleMsgResumeMarket(ctx, msg, k)
 		default:
 			return dex.ErrUnknownRequest(ModuleName, msg)
 		}
@@ -50,6 +63,7 @@ func handleMsgCreateTradingPair(ctx sdk.Context, msg types.MsgCreateTradingPair,
 		PricePrecision:    msg.PricePrecision,
 		LastExecutedPrice: sdk.ZeroDec(),
 		OrderPrecision:    orderPrecision,
+		MatchingAlgorithm: msg.MatchingAlgorithm,
 	}
 
 	if err := keeper.SetMarket(ctx, info); err != nil {
@@ -121,7 +135,7 @@ type ParamOfCommissionMsg struct {
 }
 
 func CalCommission(ctx sdk.Context, keeper keepers.QueryMarketInfoAndParams, msg ParamOfCommissionMsg) (int64, sdk.Error) {
-	marketParams := keeper.GetParams(ctx)
+	marketParams := keeper.GetMarketParams(ctx, dex.GetSymbol(msg.stock, msg.money))
 	volume := keeper.GetMarketVolume(ctx, msg.stock, msg.money, msg.amountOfStock, msg.amountOfMoney)
 	rate := sdk.NewDec(marketParams.MarketFeeRate).QuoInt64(int64(math.Pow10(types.DefaultMarketFeeRatePrecision)))
 	commission := volume.Mul(rate).Ceil().RoundInt64()
@@ -150,7 +164,8 @@ func calOrderCommission(ctx sdk.Context, keeper keepers.Keeper, msg types.MsgCre
 }
 
 func calFeatureFeeForExistBlocks(msg types.MsgCreateOrder, marketParam types.Params) int64 {
-	if msg.TimeInForce == types.IOC {
+	// a stop order may wait in the order book for a long time before it is triggered
+	if msg.IsImmediateOrder() && !msg.IsStopOrder() {
 		return 0
 	}
 	if msg.ExistBlocks < marketParam.GTEOrderLifetime {
@@ -199,6 +214,11 @@ func sendCreateOrderMsg(ctx sdk.Context, keeper keepers.Keeper, order types.Orde
 			FrozenCommission: order.FrozenCommission,
 			FrozenFeatureFee: order.FrozenFeatureFee,
 			Freeze:           order.Freeze,
+			TriggerPrice:     order.TriggerPrice,
+			ExpireTime:       order.ExpireTime,
+
+			SelfTradePrevention: order.SelfTradePrevention,
+			DisplayQuantity:     order.DisplayQuantity,
 		}
 		msgqueue.FillMsgs(ctx, types.CreateOrderInfoKey, createOrderInfo)
 	}
@@ -224,8 +244,7 @@ func getDenomAndOrderAmount(msg types.MsgCreateOrder) (string, int64, sdk.Error)
 }
 
 func handleMsgCreateOrder(ctx sdk.Context, msg types.MsgCreateOrder, keeper keepers.Keeper) sdk.Result {
-
-	denom, amount, err := getDenomAndOrderAmount(msg)
+	order, denom, err := newOrderFromMsg(ctx, keeper, msg)
 	if err != nil {
 		return err.Result()
 	}
@@ -233,27 +252,73 @@ func handleMsgCreateOrder(ctx sdk.Context, msg types.MsgCreateOrder, keeper keep
 	if err != nil {
 		return err.Result()
 	}
-	marketParams := keeper.GetParams(ctx)
+	order.Sequence = seq
+	frozenFee, featureFee := order.FrozenCommission, order.FrozenFeatureFee
+	if err := checkMsgCreateOrder(ctx, keeper, msg, frozenFee+featureFee, order.Freeze, denom, seq); err != nil {
+		return err.Result()
+	}
+
+	ork := keepers.NewOrderKeeper(keeper.GetMarketKey(), order.TradingPair, types.ModuleCdc)
+	if err := ork.Add(ctx, &order); err != nil {
+		return err.Result()
+	}
+	if err := handleFeeForCreateOrder(ctx, keeper, order.Freeze, denom, order.Sender, frozenFee, featureFee); err != nil {
+		return err.Result()
+	}
+	sendCreateOrderMsg(ctx, keeper, order, featureFee)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			EventTypeKeyCreateOrder,
+			sdk.NewAttribute(AttributeKeyOrder, order.OrderID()),
+			sdk.NewAttribute(AttributeKeyTradingPair, order.TradingPair),
+			sdk.NewAttribute(AttributeKeyHeight, strconv.FormatInt(order.Height, 10)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Sender.String()),
+		),
+	})
+	return sdk.Result{
+		Events: ctx.EventManager().Events(),
+	}
+}
+
+// newOrderFromMsg builds the order and calculates its frozen coins and fees, and returns the
+// denom to be frozen. The sequence of the order is left for the caller to fill in.
+func newOrderFromMsg(ctx sdk.Context, keeper keepers.Keeper, msg types.MsgCreateOrder) (types.Order, string, sdk.Error) {
+	var triggerPrice sdk.Dec
+	if msg.IsStopOrder() {
+		triggerPrice = sdk.NewDec(msg.TriggerPrice).Quo(sdk.NewDec(int64(math.Pow10(int(msg.PricePrecision)))))
+	}
+	if msg.IsMarketOrder() {
+		if err := setProtectivePrice(ctx, keeper, &msg, triggerPrice); err != nil {
+			return types.Order{}, "", err
+		}
+	}
+
+	denom, amount, err := getDenomAndOrderAmount(msg)
+	if err != nil {
+		return types.Order{}, "", err
+	}
+	marketParams := keeper.GetMarketParams(ctx, msg.TradingPair)
 	frozenFee, err := calOrderCommission(ctx, keeper, msg)
 	if err != nil {
-		return err.Result()
+		return types.Order{}, "", err
 	}
 	featureFee := calFeatureFeeForExistBlocks(msg, marketParams)
 	totalFee := frozenFee + featureFee
 	if featureFee > types.MaxOrderAmount || frozenFee > types.MaxOrderAmount || totalFee > types.MaxOrderAmount {
-		return types.ErrInvalidOrderAmount("The frozen fee is too large").Result()
-	}
-	if err := checkMsgCreateOrder(ctx, keeper, msg, totalFee, amount, denom, seq); err != nil {
-		return err.Result()
+		return types.Order{}, "", types.ErrInvalidOrderAmount("The frozen fee is too large")
 	}
 	existBlocks := msg.ExistBlocks
-	if existBlocks == 0 && msg.TimeInForce == GTE {
+	if existBlocks == 0 && (!msg.IsImmediateOrder() || msg.IsStopOrder()) {
 		existBlocks = marketParams.GTEOrderLifetime
 	}
 
 	order := types.Order{
 		Sender:           msg.Sender,
-		Sequence:         seq,
 		Identify:         msg.Identify,
 		TradingPair:      msg.TradingPair,
 		OrderType:        msg.OrderType,
@@ -265,37 +330,53 @@ func handleMsgCreateOrder(ctx sdk.Context, msg types.MsgCreateOrder, keeper keep
 		ExistBlocks:      existBlocks,
 		FrozenCommission: frozenFee,
 		FrozenFeatureFee: featureFee,
+		TriggerPrice:     triggerPrice,
+		ExpireTime:       msg.ExpireTime,
 		LeftStock:        msg.Quantity,
 		Freeze:           amount,
 		DealMoney:        0,
 		DealStock:        0,
+
+		SelfTradePrevention: msg.SelfTradePrevention,
+		DisplayQuantity:     msg.DisplayQuantity,
+		VisibleStock:        msg.DisplayQuantity,
 	}
+	return order, denom, nil
+}
 
-	ork := keepers.NewOrderKeeper(keeper.GetMarketKey(), order.TradingPair, types.ModuleCdc)
-	if err := ork.Add(ctx, &order); err != nil {
-		return err.Result()
+// Market orders and stop-market orders carry no limit price. Their price is set to the worst price
+// allowed by MaxExecutedPriceChangeRatio around a reference price, which is the last executed price
+// for market orders and the trigger price for stop-market orders.
+func setProtectivePrice(ctx sdk.Context, keeper keepers.Keeper, msg *types.MsgCreateOrder, triggerPrice sdk.Dec) sdk.Error {
+	marketInfo, err := keeper.GetMarketInfo(ctx, msg.TradingPair)
+	if err != nil {
+		return types.ErrInvalidMarket(err.Error())
 	}
-	if err := handleFeeForCreateOrder(ctx, keeper, amount, denom, order.Sender, frozenFee, featureFee); err != nil {
-		return err.Result()
+	if p := msg.PricePrecision; p > marketInfo.PricePrecision {
+		return types.ErrInvalidPricePrecision(p)
+	}
+	refPrice := marketInfo.LastExecutedPrice
+	if msg.OrderType == types.StopMarketOrder {
+		refPrice = triggerPrice
+	}
+	if refPrice.IsNil() || refPrice.IsZero() {
+		return types.ErrNoReferencePrice(msg.TradingPair)
 	}
-	sendCreateOrderMsg(ctx, keeper, order, featureFee)
 
-	ctx.EventManager().EmitEvents(sdk.Events{
-		sdk.NewEvent(
-			EventTypeKeyCreateOrder,
-			sdk.NewAttribute(AttributeKeyOrder, order.OrderID()),
-			sdk.NewAttribute(AttributeKeyTradingPair, order.TradingPair),
-			sdk.NewAttribute(AttributeKeyHeight, strconv.FormatInt(order.Height, 10)),
-		),
-		sdk.NewEvent(
-			sdk.EventTypeMessage,
-			sdk.NewAttribute(sdk.AttributeKeyModule, AttributeValueCategory),
-			sdk.NewAttribute(sdk.AttributeKeySender, msg.Sender.String()),
-		),
-	})
-	return sdk.Result{
-		Events: ctx.EventManager().Events(),
+	ratio := marketInfo.GetEffectiveParams(keeper.GetParams(ctx)).MaxExecutedPriceChangeRatio
+	scale := sdk.NewDec(int64(math.Pow10(int(marketInfo.PricePrecision))))
+	var price sdk.Int
+	if msg.Side == types.BUY {
+		price = refPrice.MulInt64(100 + ratio).QuoInt64(100).Mul(scale).TruncateInt()
+	} else {
+		price = refPrice.MulInt64(100 - ratio).QuoInt64(100).Mul(scale).Ceil().TruncateInt()
 	}
+	if !price.IsInt64() || price.Int64() <= 0 {
+		return types.ErrInvalidOrderAmount("The protective price of the market order is out of range")
+	}
+	msg.Price = price.Int64()
+	msg.PricePrecision = marketInfo.PricePrecision
+	return nil
 }
 
 func checkMsgCreateOrder(ctx sdk.Context, keeper keepers.Keeper, msg types.MsgCreateOrder, cetFee int64, amount int64, denom string, seq uint64) sdk.Error {
@@ -313,6 +394,12 @@ func checkMsgCreateOrder(ctx sdk.Context, keeper keepers.Keeper, msg types.MsgCr
 	if !keeper.HasCoins(ctx, msg.Sender, sdk.Coins{sdk.NewCoin(denom, totalAmount)}) {
 		return types.ErrInsufficientCoins()
 	}
+	return checkOrderInMarket(ctx, keeper, msg, seq)
+}
+
+// checks everything about a new order except whether the sender has enough coins
+func checkOrderInMarket(ctx sdk.Context, keeper keepers.Keeper, msg types.MsgCreateOrder, seq uint64) sdk.Error {
+	stock, money := SplitSymbol(msg.TradingPair)
 	orderID := types.AssemblyOrderID(msg.Sender.String(), seq, msg.Identify)
 	globalKeeper := keepers.NewGlobalOrderKeeper(keeper.GetMarketKey(), types.ModuleCdc)
 	if globalKeeper.QueryOrder(ctx, orderID) != nil {
@@ -325,6 +412,12 @@ func checkMsgCreateOrder(ctx sdk.Context, keeper keepers.Keeper, msg types.MsgCr
 	if p := msg.PricePrecision; p > marketInfo.PricePrecision {
 		return types.ErrInvalidPricePrecision(p)
 	}
+	if !keeper.GetMarketStatus(ctx, msg.TradingPair).AcceptsOrders() {
+		return types.ErrMarketCancelOnly(msg.TradingPair)
+	}
+	if msg.ExpireTime != 0 && msg.ExpireTime <= ctx.BlockHeader().Time.Unix() {
+		return types.ErrInvalidExpireTime(msg.ExpireTime)
+	}
 	if keeper.IsTokenForbidden(ctx, stock) || keeper.IsTokenForbidden(ctx, money) {
 		return types.ErrTokenForbidByIssuer()
 	}
@@ -335,18 +428,91 @@ func checkMsgCreateOrder(ctx sdk.Context, keeper keepers.Keeper, msg types.MsgCr
 	if msg.Quantity%baseValue != 0 {
 		return types.ErrInvalidOrderAmount("The amount of tokens to trade should be a multiple of the order precision")
 	}
+	if msg.DisplayQuantity%baseValue != 0 {
+		return types.ErrInvalidDisplayQuantity(msg.DisplayQuantity)
+	}
+	if err := marketInfo.CheckOrderQuantity(msg.Quantity); err != nil {
+		return err
+	}
+	// the protective price of a market order is not required to be on the tick
+	if !msg.IsMarketOrder() {
+		if err := marketInfo.CheckOrderPrice(msg.Price, msg.PricePrecision); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+func handleMsgCreateOrders(ctx sdk.Context, msg types.MsgCreateOrders, keeper keepers.Keeper) sdk.Result {
+	seq, err := keeper.QuerySeqWithAddr(ctx, msg.Sender)
+	if err != nil {
+		return err.Result()
+	}
+	orders := make([]types.Order, 0, len(msg.Orders))
+	frozenCoins := sdk.Coins{}
+	for _, orderMsg := range msg.GetCreateOrderMsgs() {
+		order, denom, err := newOrderFromMsg(ctx, keeper, orderMsg)
+		if err != nil {
+			return err.Result()
+		}
+		order.Sequence = seq
+		if err := checkOrderInMarket(ctx, keeper, orderMsg, seq); err != nil {
+			return err.Result()
+		}
+		frozenCoins = frozenCoins.Add(sdk.Coins{sdk.NewCoin(denom, sdk.NewInt(order.Freeze))})
+		if fee := order.FrozenCommission + order.FrozenFeatureFee; fee != 0 {
+			frozenCoins = frozenCoins.Add(dex.NewCetCoins(fee))
+		}
+		orders = append(orders, order)
+	}
+	// all the coins of the batch are checked and frozen together
+	if !keeper.HasCoins(ctx, msg.Sender, frozenCoins) {
+		return types.ErrInsufficientCoins().Result()
+	}
+
+	ork := keepers.NewOrderKeeper(keeper.GetMarketKey(), msg.TradingPair, types.ModuleCdc)
+	orderIDs := make([]string, len(orders))
+	for i := range orders {
+		if err := ork.Add(ctx, &orders[i]); err != nil {
+			return err.Result()
+		}
+		orderIDs[i] = orders[i].OrderID()
+	}
+	if err := keeper.FreezeCoins(ctx, msg.Sender, frozenCoins); err != nil {
+		return err.Result()
+	}
+	for _, order := range orders {
+		sendCreateOrderMsg(ctx, keeper, order, order.FrozenFeatureFee)
+	}
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			EventTypeKeyCreateOrders,
+			sdk.NewAttribute(AttributeKeyOrders, strings.Join(orderIDs, ",")),
+			sdk.NewAttribute(AttributeKeyOrderCount, strconv.Itoa(len(orderIDs))),
+			sdk.NewAttribute(AttributeKeyTradingPair, msg.TradingPair),
+			sdk.NewAttribute(AttributeKeyHeight, strconv.FormatInt(ctx.BlockHeight(), 10)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Sender.String()),
+		),
+	})
+	return sdk.Result{
+		Events: ctx.EventManager().Events(),
+	}
+}
+
 func handleMsgCancelOrder(ctx sdk.Context, msg types.MsgCancelOrder, keeper keepers.Keeper) sdk.Result {
 	if err := checkMsgCancelOrder(ctx, msg, keeper); err != nil {
 		return err.Result()
 	}
-	marketParams := keeper.GetParams(ctx)
 	bankxKeeper := keeper.GetBankxKeeper()
 	glk := keepers.NewGlobalOrderKeeper(keeper.GetMarketKey(), types.ModuleCdc)
 	order := glk.QueryOrder(ctx, msg.OrderID)
+	marketParams := keeper.GetMarketParams(ctx, order.TradingPair)
 	ork := keepers.NewOrderKeeper(keeper.GetMarketKey(), order.TradingPair, types.ModuleCdc)
 	removeOrder(ctx, ork, bankxKeeper, keeper, order, &marketParams)
 
@@ -390,6 +556,221 @@ func checkMsgCancelOrder(ctx sdk.Context, msg types.MsgCancelOrder, keeper keepe
 	return nil
 }
 
+func handleMsgCancelAllOrders(ctx sdk.Context, msg types.MsgCancelAllOrders, keeper keepers.Keeper) sdk.Result {
+	marketParams := keeper.GetMarketParams(ctx, msg.TradingPair)
+	bankxKeeper := keeper.GetBankxKeeper()
+	glk := keepers.NewGlobalOrderKeeper(keeper.GetMarketKey(), types.ModuleCdc)
+	ork := keepers.NewOrderKeeper(keeper.GetMarketKey(), msg.TradingPair, types.ModuleCdc)
+
+	orderIDs := make([]string, 0)
+	for _, orderID := range glk.GetOrdersFromUser(ctx, msg.Sender.String()) {
+		order := glk.QueryOrder(ctx, orderID)
+		if order == nil || order.TradingPair != msg.TradingPair {
+			continue
+		}
+		if msg.Side != 0 && order.Side != msg.Side {
+			continue
+		}
+		removeOrder(ctx, ork, bankxKeeper, keeper, order, &marketParams)
+		sendCancelOrderMsg(ctx, order, &marketParams, keeper)
+		orderIDs = append(orderIDs, orderID)
+	}
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			EventTypeKeyCancelAllOrders,
+			sdk.NewAttribute(AttributeKeyOrders, strings.Join(orderIDs, ",")),
+			sdk.NewAttribute(AttributeKeyOrderCount, strconv.Itoa(len(orderIDs))),
+			sdk.NewAttribute(AttributeKeyDelOrderReason, types.CancelOrderByManual),
+			sdk.NewAttribute(AttributeKeyDelOrderHeight, strconv.Itoa(int(ctx.BlockHeight()))),
+			sdk.NewAttribute(AttributeKeyTradingPair, msg.TradingPair),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Sender.String()),
+		),
+	})
+	return sdk.Result{
+		Events: ctx.EventManager().Events(),
+	}
+}
+
+func handleMsgModifyOrder(ctx sdk.Context, msg types.MsgModifyOrder, keeper keepers.Keeper) sdk.Result {
+	if err := checkMsgModifyOrder(ctx, msg, keeper); err != nil {
+		return err.Result()
+	}
+	glk := keepers.NewGlobalOrderKeeper(keeper.GetMarketKey(), types.ModuleCdc)
+	order := glk.QueryOrder(ctx, msg.OrderID)
+	newOrder, err := getModifiedOrder(ctx, msg, keeper, order)
+	if err != nil {
+		return err.Result()
+	}
+	if err := adjustFrozenCoinsForModifiedOrder(ctx, keeper, order, newOrder); err != nil {
+		return err.Result()
+	}
+
+	ork := keepers.NewOrderKeeper(keeper.GetMarketKey(), order.TradingPair, types.ModuleCdc)
+	if order.Price.Equal(newOrder.Price) {
+		// the keys of the order are not changed, so it keeps its time priority
+		if err := ork.Update(ctx, newOrder); err != nil {
+			return sdk.ErrInternal(err.Error()).Result()
+		}
+	} else {
+		// re-key the order at the new price, where it may be matched with other orders
+		if err := ork.Remove(ctx, order); err != nil {
+			return sdk.ErrInternal(err.Error()).Result()
+		}
+		newOrder.ModifyHeight = ctx.BlockHeight()
+		if err := ork.Add(ctx, newOrder); err != nil {
+			return sdk.ErrInternal(err.Error()).Result()
+		}
+	}
+	sendModifyOrderMsg(ctx, keeper, order, newOrder)
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			EventTypeKeyModifyOrder,
+			sdk.NewAttribute(AttributeKeyOrder, order.OrderID()),
+			sdk.NewAttribute(AttributeKeyTradingPair, order.TradingPair),
+			sdk.NewAttribute(AttributeKeyPrice, newOrder.Price.String()),
+			sdk.NewAttribute(AttributeKeyQuantity, strconv.FormatInt(newOrder.Quantity, 10)),
+			sdk.NewAttribute(AttributeKeyHeight, strconv.FormatInt(ctx.BlockHeight(), 10)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Sender.String()),
+		),
+	})
+	return sdk.Result{
+		Events: ctx.EventManager().Events(),
+	}
+}
+
+func checkMsgModifyOrder(ctx sdk.Context, msg types.MsgModifyOrder, keeper keepers.Keeper) sdk.Error {
+	globalKeeper := keepers.NewGlobalOrderKeeper(keeper.GetMarketKey(), types.ModuleCdc)
+	order := globalKeeper.QueryOrder(ctx, msg.OrderID)
+	if order == nil {
+		return types.ErrOrderNotFound(msg.OrderID)
+	}
+	if !bytes.Equal(order.Sender, msg.Sender) {
+		return types.ErrNotMatchSender("only order's sender can modify this order")
+	}
+	marketInfo, err := keeper.GetMarketInfo(ctx, order.TradingPair)
+	if err != nil {
+		return types.ErrInvalidMarket(err.Error())
+	}
+	if p := msg.PricePrecision; p > marketInfo.PricePrecision {
+		return types.ErrInvalidPricePrecision(p)
+	}
+	if !keeper.GetMarketStatus(ctx, order.TradingPair).AcceptsOrders() {
+		return types.ErrMarketCancelOnly(order.TradingPair)
+	}
+	if msg.Quantity > order.Quantity {
+		return types.ErrInvalidModification("the quantity can not be increased")
+	}
+	if msg.Quantity <= order.DealStock {
+		return types.ErrInvalidModification("the quantity must be larger than the dealt quantity")
+	}
+	if msg.Quantity%types.GetGranularityOfOrder(marketInfo.OrderPrecision) != 0 {
+		return types.ErrInvalidOrderAmount("The amount of tokens to trade should be a multiple of the order precision")
+	}
+	if err := marketInfo.CheckOrderQuantity(msg.Quantity); err != nil {
+		return err
+	}
+	price := sdk.NewDec(msg.Price).Quo(sdk.NewDec(int64(math.Pow10(int(msg.PricePrecision)))))
+	if order.OrderType == types.MarketOrder || order.OrderType == types.StopMarketOrder {
+		if !price.Equal(order.Price) {
+			return types.ErrInvalidModification("the price of a market order can not be modified")
+		}
+		return nil
+	}
+	return marketInfo.CheckOrderPrice(msg.Price, msg.PricePrecision)
+}
+
+// returns a copy of the order with new price, quantity and frozen amounts
+func getModifiedOrder(ctx sdk.Context, msg types.MsgModifyOrder, keeper keepers.Keeper, order *types.Order) (*types.Order, sdk.Error) {
+	newOrder := *order
+	newOrder.Price = sdk.NewDec(msg.Price).Quo(sdk.NewDec(int64(math.Pow10(int(msg.PricePrecision)))))
+	newOrder.Quantity = msg.Quantity
+	newOrder.LeftStock = msg.Quantity - order.DealStock
+	newOrder.Freeze = newOrder.LeftStock
+	if order.Side == types.BUY {
+		freeze := newOrder.Price.MulInt64(newOrder.LeftStock).Ceil()
+		if freeze.GT(sdk.NewDec(types.MaxOrderAmount)) {
+			return nil, types.ErrInvalidOrderAmount("The frozen money is too large")
+		}
+		newOrder.Freeze = freeze.RoundInt64()
+	}
+
+	// the commission is recalculated with the new price and quantity
+	stock, money := SplitSymbol(order.TradingPair)
+	commission, err := CalCommission(ctx, keeper, ParamOfCommissionMsg{
+		amountOfMoney: newOrder.Price.MulInt64(newOrder.Quantity).Ceil(),
+		amountOfStock: sdk.NewDec(newOrder.Quantity),
+		stock:         stock,
+		money:         money,
+	})
+	if err != nil {
+		return nil, err
+	}
+	newOrder.FrozenCommission = commission
+	return &newOrder, nil
+}
+
+// freeze or unfreeze the differences between the old order and the new order
+func adjustFrozenCoinsForModifiedOrder(ctx sdk.Context, keeper keepers.Keeper, order, newOrder *types.Order) sdk.Error {
+	denom := order.GetOrderUsedDenom()
+	freezeDiff := newOrder.Freeze - order.Freeze
+	commissionDiff := newOrder.FrozenCommission - order.FrozenCommission
+
+	needed := sdk.Coins{}
+	if freezeDiff > 0 {
+		needed = needed.Add(sdk.Coins{sdk.NewCoin(denom, sdk.NewInt(freezeDiff))})
+	}
+	if commissionDiff > 0 {
+		needed = needed.Add(dex.NewCetCoins(commissionDiff))
+	}
+	if !needed.IsZero() && !keeper.HasCoins(ctx, order.Sender, needed) {
+		return types.ErrInsufficientCoins()
+	}
+
+	if err := adjustFrozenCoins(ctx, keeper, order.Sender, denom, freezeDiff); err != nil {
+		return err
+	}
+	return adjustFrozenCoins(ctx, keeper, order.Sender, dex.CET, commissionDiff)
+}
+
+func adjustFrozenCoins(ctx sdk.Context, keeper keepers.Keeper, addr sdk.AccAddress, denom string, diff int64) sdk.Error {
+	if diff > 0 {
+		return keeper.FreezeCoins(ctx, addr, sdk.Coins{sdk.NewCoin(denom, sdk.NewInt(diff))})
+	}
+	if diff < 0 {
+		return keeper.UnFreezeCoins(ctx, addr, sdk.Coins{sdk.NewCoin(denom, sdk.NewInt(-diff))})
+	}
+	return nil
+}
+
+func sendModifyOrderMsg(ctx sdk.Context, keeper keepers.Keeper, order, newOrder *types.Order) {
+	if keeper.IsSubScribed(types.Topic) {
+		msgqueue.FillMsgs(ctx, types.ModifyOrderInfoKey, types.ModifyOrderInfo{
+			OrderID:          order.OrderID(),
+			Sender:           order.Sender.String(),
+			TradingPair:      order.TradingPair,
+			Height:           ctx.BlockHeight(),
+			Side:             order.Side,
+			OldPrice:         order.Price,
+			NewPrice:         newOrder.Price,
+			OldQuantity:      order.Quantity,
+			NewQuantity:      newOrder.Quantity,
+			LeftStock:        newOrder.LeftStock,
+			Freeze:           newOrder.Freeze,
+			FrozenCommission: newOrder.FrozenCommission,
+		})
+	}
+}
+
 func handleMsgCancelTradingPair(ctx sdk.Context, msg types.MsgCancelTradingPair, keeper keepers.Keeper) sdk.Result {
 	if err := checkMsgCancelTradingPair(keeper, msg, ctx); err != nil {
 		return err.Result()
@@ -455,11 +836,14 @@ func handleMsgModifyPricePrecision(ctx sdk.Context, msg types.MsgModifyPricePrec
 	}
 
 	oldInfo, _ := k.GetMarketInfo(ctx, msg.TradingPair)
+	// the order precision is reset as before, and the algorithm and param overrides are kept
 	info := types.MarketInfo{
 		Stock:             oldInfo.Stock,
 		Money:             oldInfo.Money,
 		PricePrecision:    msg.PricePrecision,
 		LastExecutedPrice: oldInfo.LastExecutedPrice,
+		MatchingAlgorithm: oldInfo.MatchingAlgorithm,
+		Params:            oldInfo.Params,
 	}
 	if err := k.SetMarket(ctx, info); err != nil {
 		return err.Result()
@@ -500,3 +884,127 @@ func checkMsgModifyPricePrecision(ctx sdk.Context, msg types.MsgModifyPricePreci
 
 	return nil
 }
+
+func handleMsgModifyMarketParams(ctx sdk.Context, msg types.MsgModifyMarketParams, k keepers.Keeper) sdk.Result {
+	if err := checkMsgModifyMarketParams(ctx, msg, k); err != nil {
+		return err.Result()
+	}
+
+	oldInfo, _ := k.GetMarketInfo(ctx, msg.TradingPair)
+	info := oldInfo
+	info.Params = msg.Params
+	if err := k.SetMarket(ctx, info); err != nil {
+		return err.Result()
+	}
+
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			EventTypeKeyModifyMarketParams,
+			sdk.NewAttribute(AttributeKeyTradingPair, msg.TradingPair),
+			sdk.NewAttribute(AttributeKeyOldMarketParams, oldInfo.Params.String()),
+			sdk.NewAttribute(AttributeKeyNewMarketParams, info.Params.String()),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, msg.Sender.String()),
+		),
+	})
+
+	return sdk.Result{
+		Events: ctx.EventManager().Events(),
+	}
+}
+
+func checkMsgModifyMarketParams(ctx sdk.Context, msg types.MsgModifyMarketParams, k keepers.Keeper) sdk.Error {
+	_, err := k.GetMarketInfo(ctx, msg.TradingPair)
+	if err != nil {
+		return types.ErrInvalidMarket("Error retrieving market information: " + err.Error())
+	}
+
+	if err := checkMarketOwner(ctx, k, msg.TradingPair, msg.Sender); err != nil {
+		return err
+	}
+	return k.GetParams(ctx).ValidateMarketParams(msg.Params)
+}
+
+// only the owner of the stock token can change the market
+func checkMarketOwner(ctx sdk.Context, k keepers.Keeper, tradingPair string, sender sdk.AccAddress) sdk.Error {
+	stock, _ := SplitSymbol(tradingPair)
+	tokenInfo := k.GetToken(ctx, stock)
+	if !tokenInfo.GetOwner().Equals(sender) {
+		return types.ErrNotMatchSender(fmt.Sprintf(
+			"The sender of the transaction (%s) does not match the owner of the transaction pair (%s)",
+			tokenInfo.GetOwner().String(), sender.String()))
+	}
+	return nil
+}
+
+func handleMsgHaltMarket(ctx sdk.Context, msg types.MsgHaltMarket, k keepers.Keeper) sdk.Result {
+	if _, err := k.GetMarketInfo(ctx, msg.TradingPair); err != nil {
+		return types.ErrInvalidMarket("Error retrieving market information: " + err.Error()).Result()
+	}
+	if err := checkMarketOwner(ctx, k, msg.TradingPair, msg.Sender); err != nil {
+		return err.Result()
+	}
+	status := k.GetMarketStatus(ctx, msg.TradingPair)
+	if status.Status != types.MarketOpen {
+		return types.ErrInvalidMarketStatus(status.Status).Result()
+	}
+	changeMarketStatus(ctx, k, msg.TradingPair, status, msg.Status, types.StatusChangedByOwner)
+	return marketStatusResult(ctx, msg.TradingPair, msg.Status, msg.Sender)
+}
+
+func handleMsgResumeMarket(ctx sdk.Context, msg types.MsgResumeMarket, k keepers.Keeper) sdk.Result {
+	if _, err := k.GetMarketInfo(ctx, msg.TradingPair); err != nil {
+		return types.ErrInvalidMarket("Error retrieving market information: " + err.Error()).Result()
+	}
+	if err := checkMarketOwner(ctx, k, msg.TradingPair, msg.Sender); err != nil {
+		return err.Result()
+	}
+	status := k.GetMarketStatus(ctx, msg.TradingPair)
+	if status.Status == types.MarketOpen {
+		return types.ErrInvalidMarketStatus(status.Status).Result()
+	}
+	// the circuit breaker starts a new window at the next executed price
+	status.RefPrice, status.RefHeight = sdk.ZeroDec(), 0
+	changeMarketStatus(ctx, k, msg.TradingPair, status, types.MarketOpen, types.StatusChangedByOwner)
+	// the orders which have crossed during the halt are matched in this block
+	keepers.NewOrderKeeper(k.GetMarketKey(), msg.TradingPair, types.ModuleCdc).MarkNewlyAdded(ctx)
+	return marketStatusResult(ctx, msg.TradingPair, types.MarketOpen, msg.Sender)
+}
+
+// changeMarketStatus saves the new status of a market and publishes the change
+func changeMarketStatus(ctx sdk.Context, k keepers.Keeper, symbol string, status types.MarketStatus,
+	newStatus byte, reason string) {
+	oldStatus := status.Status
+	status.Status, status.Reason, status.Height = newStatus, reason, ctx.BlockHeight()
+	k.SetMarketStatus(ctx, symbol, status)
+	if k.IsSubScribed(types.Topic) {
+		msgqueue.FillMsgs(ctx, types.MarketStatusInfoKey, types.MarketStatusInfo{
+			TradingPair: symbol,
+			OldStatus:   oldStatus,
+			NewStatus:   newStatus,
+			Reason:      reason,
+			Height:      ctx.BlockHeight(),
+		})
+	}
+}
+
+func marketStatusResult(ctx sdk.Context, symbol string, status byte, sender sdk.AccAddress) sdk.Result {
+	ctx.EventManager().EmitEvents(sdk.Events{
+		sdk.NewEvent(
+			EventTypeKeyChangeMarketStatus,
+			sdk.NewAttribute(AttributeKeyTradingPair, symbol),
+			sdk.NewAttribute(AttributeKeyMarketStatus, types.MarketStatusName(status)),
+		),
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, sender.String()),
+		),
+	})
+	return sdk.Result{
+		Events: ctx.EventManager().Events(),
+	}
+}
@@ -563,6 +563,62 @@ func TestCreateOrderSuccess(t *testing.T) {
 	require.Equal(t, true, isSameOrderAndMsg(order, msgIOCOrder), "order should equal msg")
 }
 
+func TestCreateMarketAndStopOrder(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	ret := createCetMarket(input, stock, 0)
+	require.Equal(t, true, ret.IsOK(), "create market should succeed")
+	glk := keepers.NewGlobalOrderKeeper(input.keys.marketKey, input.cdc)
+
+	msgMarketOrder := types.MsgCreateOrder{
+		Sender:      haveCetAddress,
+		Identify:    1,
+		TradingPair: GetSymbol(stock, "cet"),
+		OrderType:   types.MarketOrder,
+		Quantity:    10000000,
+		Side:        types.BUY,
+		TimeInForce: types.IOC,
+	}
+	ret = input.handler(input.ctx, msgMarketOrder)
+	require.Equal(t, types.CodeNoReferencePrice, ret.Code, "market order needs the last executed price")
+
+	mkInfo, err := input.mk.GetMarketInfo(input.ctx, msgMarketOrder.TradingPair)
+	require.Nil(t, err)
+	mkInfo.LastExecutedPrice = sdk.NewDec(1)
+	require.Nil(t, input.mk.SetMarket(input.ctx, mkInfo))
+
+	// the price of a market order is bounded by MaxExecutedPriceChangeRatio
+	seq, err := input.mk.QuerySeqWithAddr(input.ctx, msgMarketOrder.Sender)
+	require.Nil(t, err)
+	ret = input.handler(input.ctx, msgMarketOrder)
+	require.Equal(t, true, ret.IsOK(), "create market order should succeed ; ", ret.Log)
+	order := glk.QueryOrder(input.ctx, types.AssemblyOrderID(msgMarketOrder.Sender.String(), seq, msgMarketOrder.Identify))
+	require.Equal(t, sdk.NewDecWithPrec(125, 2).String(), order.Price.String())
+	require.Equal(t, int64(types.IOC), order.TimeInForce)
+	require.Equal(t, int64(12500000), order.Freeze)
+
+	// stop-market orders use the trigger price as reference price
+	msgStopOrder := types.MsgCreateOrder{
+		Sender:         haveCetAddress,
+		Identify:       2,
+		TradingPair:    GetSymbol(stock, "cet"),
+		OrderType:      types.StopMarketOrder,
+		PricePrecision: 1,
+		Quantity:       10000000,
+		Side:           types.SELL,
+		TimeInForce:    types.IOC,
+		TriggerPrice:   8,
+	}
+	ret = input.handler(input.ctx, msgStopOrder)
+	require.Equal(t, true, ret.IsOK(), "create stop-market order should succeed ; ", ret.Log)
+	order = glk.QueryOrder(input.ctx, types.AssemblyOrderID(msgStopOrder.Sender.String(), seq, msgStopOrder.Identify))
+	require.Equal(t, sdk.NewDecWithPrec(6, 1).String(), order.Price.String())
+	require.Equal(t, sdk.NewDecWithPrec(8, 1).String(), order.TriggerPrice.String())
+	require.Equal(t, input.mk.GetParams(input.ctx).GTEOrderLifetime, order.ExistBlocks)
+	require.True(t, order.IsDormant())
+	require.False(t, order.IsTriggeredBy(mkInfo.LastExecutedPrice))
+	require.True(t, order.IsTriggeredBy(sdk.NewDecWithPrec(8, 1)))
+}
+
 func isSameOrderAndMsg(order *types.Order, msg types.MsgCreateOrder) bool {
 	p := sdk.NewDec(msg.Price).Quo(sdk.NewDec(int64(math.Pow10(int(msg.PricePrecision)))))
 	samePrice := order.Price.Equal(p)
@@ -640,6 +696,130 @@ func TestCancelOrderSuccess(t *testing.T) {
 	require.Equal(t, true, input.hasCoins(notHaveCetAddress, sdk.Coins{remainCoin}), "The amount is error ")
 }
 
+func TestCreateOrdersAndCancelAllOrders(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	createCetMarket(input, stock, 0)
+	glk := keepers.NewGlobalOrderKeeper(input.keys.marketKey, input.cdc)
+	oldStock := input.getCoinFromAddr(haveCetAddress, stock)
+
+	msgOrders := types.MsgCreateOrders{
+		Sender:      haveCetAddress,
+		TradingPair: GetSymbol(stock, "cet"),
+		Orders: []types.OrderItem{
+			{Identify: 1, OrderType: types.LimitOrder, Price: 2, Quantity: 1000000, Side: types.BUY, TimeInForce: types.GTE},
+			{Identify: 2, OrderType: types.LimitOrder, Price: 3, Quantity: 1000000, Side: types.BUY, TimeInForce: types.GTE},
+			{Identify: 3, OrderType: types.LimitOrder, Price: 10, Quantity: 1000000, Side: types.SELL, TimeInForce: types.GTE},
+		},
+	}
+	ret := input.handler(input.ctx, msgOrders)
+	require.Equal(t, true, ret.IsOK(), "create orders should succeed ; ", ret.Log)
+	require.Equal(t, 3, len(glk.GetOrdersFromUser(input.ctx, haveCetAddress.String())))
+	newStock := input.getCoinFromAddr(haveCetAddress, stock)
+	require.Equal(t, true, IsEqual(oldStock, newStock, sdk.NewCoin(stock, sdk.NewInt(1000000))))
+	createEvents := 0
+	for _, event := range ret.Events {
+		require.NotEqual(t, EventTypeKeyCreateOrder, event.Type)
+		if event.Type == EventTypeKeyCreateOrders {
+			createEvents++
+		}
+	}
+	require.Equal(t, 1, createEvents)
+
+	// none of the orders is created if one of them fails
+	msgOrders.Orders = []types.OrderItem{
+		{Identify: 4, OrderType: types.LimitOrder, Price: 2, Quantity: 1000000, Side: types.BUY, TimeInForce: types.GTE},
+		{Identify: 5, OrderType: types.LimitOrder, Price: 10, Quantity: 10 * issueAmount, Side: types.SELL, TimeInForce: types.GTE},
+	}
+	ret = input.handler(input.ctx, msgOrders)
+	require.Equal(t, types.CodeInsufficientCoin, ret.Code)
+	require.Equal(t, 3, len(glk.GetOrdersFromUser(input.ctx, haveCetAddress.String())))
+
+	msgCancel := types.MsgCancelAllOrders{
+		Sender:      haveCetAddress,
+		TradingPair: GetSymbol(stock, "cet"),
+		Side:        types.BUY,
+	}
+	ret = input.handler(input.ctx, msgCancel)
+	require.Equal(t, true, ret.IsOK(), "cancel all orders should succeed ; ", ret.Log)
+	orderIDs := glk.GetOrdersFromUser(input.ctx, haveCetAddress.String())
+	require.Equal(t, 1, len(orderIDs))
+	require.EqualValues(t, types.SELL, glk.QueryOrder(input.ctx, orderIDs[0]).Side)
+
+	msgCancel.Side = 0
+	ret = input.handler(input.ctx, msgCancel)
+	require.Equal(t, true, ret.IsOK(), "cancel all orders should succeed ; ", ret.Log)
+	require.Equal(t, 0, len(glk.GetOrdersFromUser(input.ctx, haveCetAddress.String())))
+	require.Equal(t, oldStock, input.getCoinFromAddr(haveCetAddress, stock))
+}
+
+func TestModifyOrder(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	createCetMarket(input, stock, 0)
+	glk := keepers.NewGlobalOrderKeeper(input.keys.marketKey, input.cdc)
+
+	msgGteOrder := types.MsgCreateOrder{
+		Sender:         haveCetAddress,
+		Identify:       1,
+		TradingPair:    GetSymbol(stock, "cet"),
+		OrderType:      types.LimitOrder,
+		PricePrecision: 0,
+		Price:          2,
+		Quantity:       1000000,
+		Side:           types.BUY,
+		TimeInForce:    types.GTE,
+	}
+	seq, err := input.mk.QuerySeqWithAddr(input.ctx, msgGteOrder.Sender)
+	require.Nil(t, err)
+	ret := input.handler(input.ctx, msgGteOrder)
+	require.Equal(t, true, ret.IsOK(), "create GTE order should succeed ; ", ret.Log)
+	orderID := types.AssemblyOrderID(msgGteOrder.Sender.String(), seq, msgGteOrder.Identify)
+	oldOrder := glk.QueryOrder(input.ctx, orderID)
+
+	msgModify := types.MsgModifyOrder{
+		Sender:   haveCetAddress,
+		OrderID:  orderID,
+		Price:    2,
+		Quantity: 2000000,
+	}
+	ret = input.handler(input.ctx, msgModify)
+	require.Equal(t, types.CodeInvalidModification, ret.Code, "the quantity can not be increased")
+
+	msgModify.Sender = notHaveCetAddress
+	msgModify.Quantity = 500000
+	ret = input.handler(input.ctx, msgModify)
+	require.Equal(t, types.CodeNotMatchSender, ret.Code, "only the sender can modify the order")
+
+	// decrease the quantity in place
+	msgModify.Sender = haveCetAddress
+	oldCoin := input.getCoinFromAddr(haveCetAddress, dex.CET)
+	ret = input.handler(input.ctx, msgModify)
+	require.Equal(t, true, ret.IsOK(), "modify order should succeed ; ", ret.Log)
+	newCoin := input.getCoinFromAddr(haveCetAddress, dex.CET)
+	order := glk.QueryOrder(input.ctx, orderID)
+	require.EqualValues(t, 500000, order.Quantity)
+	require.EqualValues(t, 500000, order.LeftStock)
+	require.EqualValues(t, 1000000, order.Freeze)
+	require.EqualValues(t, 0, order.ModifyHeight)
+	unfrozen := oldOrder.Freeze + oldOrder.FrozenCommission - order.Freeze - order.FrozenCommission
+	require.Equal(t, true, IsEqual(newCoin, oldCoin, sdk.NewCoin(dex.CET, sdk.NewInt(unfrozen))))
+
+	// change the price, and the order loses its time priority
+	input.ctx = input.ctx.WithBlockHeight(input.ctx.BlockHeight() + 1)
+	msgModify.Price = 3
+	oldOrder = order
+	oldCoin = newCoin
+	ret = input.handler(input.ctx, msgModify)
+	require.Equal(t, true, ret.IsOK(), "modify order should succeed ; ", ret.Log)
+	newCoin = input.getCoinFromAddr(haveCetAddress, dex.CET)
+	order = glk.QueryOrder(input.ctx, orderID)
+	require.Equal(t, sdk.NewDec(3).String(), order.Price.String())
+	require.EqualValues(t, 1500000, order.Freeze)
+	require.EqualValues(t, input.ctx.BlockHeight(), order.ModifyHeight)
+	require.EqualValues(t, input.ctx.BlockHeight(), order.PriorityHeight())
+	frozen := order.Freeze + order.FrozenCommission - oldOrder.Freeze - oldOrder.FrozenCommission
+	require.Equal(t, true, IsEqual(oldCoin, newCoin, sdk.NewCoin(dex.CET, sdk.NewInt(frozen))))
+}
+
 func TestCancelMarketFailed(t *testing.T) {
 	input := prepareMockInput(t, false, false)
 	createCetMarket(input, stock, 0)
@@ -833,6 +1013,142 @@ func TestModifyPricePrecisionSuccess(t *testing.T) {
 	newCetCoin := input.getCoinFromAddr(haveCetAddress, dex.CET)
 	require.Equal(t, true, ret.IsOK(), "the tx should success")
 	require.Equal(t, true, IsEqual(oldCetCoin, newCetCoin, sdk.NewCoin(dex.CET, sdk.NewInt(0))), "the amount is error")
+
+	// the order precision is reset, and the algorithm and the param overrides are kept
+	info, err := input.mk.GetMarketInfo(input.ctx, msg.TradingPair)
+	require.Nil(t, err)
+	info.OrderPrecision = 2
+	info.MatchingAlgorithm = types.ContinuousMatching
+	info.Params = types.MarketParams{TickSize: 50}
+	require.Nil(t, input.mk.SetMarket(input.ctx, info))
+	msg.PricePrecision = 10
+	ret = input.handler(input.ctx, msg)
+	require.True(t, ret.IsOK())
+	info, err = input.mk.GetMarketInfo(input.ctx, msg.TradingPair)
+	require.Nil(t, err)
+	require.EqualValues(t, 10, info.PricePrecision)
+	require.EqualValues(t, 0, info.OrderPrecision)
+	require.Equal(t, types.ContinuousMatching, info.MatchingAlgorithm)
+	require.Equal(t, types.MarketParams{TickSize: 50}, info.Params)
+}
+
+func TestModifyMarketParams(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	createCetMarket(input, stock, 0)
+	symbol := GetSymbol(stock, dex.CET)
+
+	msg := types.MsgModifyMarketParams{
+		Sender:      haveCetAddress,
+		TradingPair: symbol,
+		Params:      types.MarketParams{MarketFeeRate: 20, MinOrderQuantity: 1000, TickSize: 50},
+	}
+	msgFailedBySender := msg
+	msgFailedBySender.Sender = notHaveCetAddress
+	ret := input.handler(input.ctx, msgFailedBySender)
+	require.Equal(t, types.CodeNotMatchSender, ret.Code)
+
+	msgFailedByLimit := msg
+	msgFailedByLimit.Params.MarketFeeRate = types.DefaultMarketFeeRateLimit + 1
+	ret = input.handler(input.ctx, msgFailedByLimit)
+	require.Equal(t, types.CodeInvalidMarketParams, ret.Code)
+
+	order := types.MsgCreateOrder{
+		Sender:         haveCetAddress,
+		Identify:       1,
+		TradingPair:    symbol,
+		OrderType:      types.LimitOrder,
+		PricePrecision: 8,
+		Price:          1e8,
+		Quantity:       1e10,
+		Side:           types.BUY,
+		TimeInForce:    types.GTE,
+	}
+	commission, err := calOrderCommission(input.ctx, input.mk, order)
+	require.Nil(t, err)
+
+	ret = input.handler(input.ctx, msg)
+	require.True(t, ret.IsOK(), ret.Log)
+	info, e := input.mk.GetMarketInfo(input.ctx, symbol)
+	require.Nil(t, e)
+	require.Equal(t, msg.Params, info.Params)
+	require.EqualValues(t, 20, input.mk.GetMarketParams(input.ctx, symbol).MarketFeeRate)
+
+	// the commission is frozen at the rate of the market
+	newCommission, err := calOrderCommission(input.ctx, input.mk, order)
+	require.Nil(t, err)
+	require.Equal(t, 2*commission, newCommission)
+
+	orderFailedByQuantity := order
+	orderFailedByQuantity.Quantity = 999
+	ret = input.handler(input.ctx, orderFailedByQuantity)
+	require.Equal(t, types.CodeInvalidOrderAmount, ret.Code)
+
+	orderFailedByTick := order
+	orderFailedByTick.Price = 1e8 + 1
+	ret = input.handler(input.ctx, orderFailedByTick)
+	require.Equal(t, types.CodeInvalidPrice, ret.Code)
+
+	ret = input.handler(input.ctx, order)
+	require.True(t, ret.IsOK(), ret.Log)
+
+	// the overrides are kept when the price precision is modified
+	ret = input.handler(input.ctx, types.MsgModifyPricePrecision{
+		Sender:         haveCetAddress,
+		TradingPair:    symbol,
+		PricePrecision: 10,
+	})
+	require.True(t, ret.IsOK(), ret.Log)
+	info, e = input.mk.GetMarketInfo(input.ctx, symbol)
+	require.Nil(t, e)
+	require.Equal(t, msg.Params, info.Params)
+}
+
+func TestHaltAndResumeMarket(t *testing.T) {
+	input := prepareMockInput(t, false, false)
+	createCetMarket(input, stock, 0)
+	symbol := GetSymbol(stock, dex.CET)
+
+	halt := types.MsgHaltMarket{
+		Sender:      notHaveCetAddress,
+		TradingPair: symbol,
+		Status:      types.MarketCancelOnly,
+	}
+	ret := input.handler(input.ctx, halt)
+	require.Equal(t, types.CodeNotMatchSender, ret.Code)
+	ret = input.handler(input.ctx, types.MsgResumeMarket{Sender: haveCetAddress, TradingPair: symbol})
+	require.Equal(t, types.CodeInvalidMarketStatus, ret.Code)
+
+	order := types.MsgCreateOrder{
+		Sender:         haveCetAddress,
+		Identify:       1,
+		TradingPair:    symbol,
+		OrderType:      types.LimitOrder,
+		PricePrecision: 8,
+		Price:          100,
+		Quantity:       10000000,
+		Side:           types.SELL,
+		TimeInForce:    types.GTE,
+	}
+	ret = input.handler(input.ctx, order)
+	require.True(t, ret.IsOK(), ret.Log)
+
+	halt.Sender = haveCetAddress
+	ret = input.handler(input.ctx, halt)
+	require.True(t, ret.IsOK(), ret.Log)
+	require.Equal(t, types.MarketCancelOnly, input.mk.GetMarketStatus(input.ctx, symbol).Status)
+	ret = input.handler(input.ctx, halt)
+	require.Equal(t, types.CodeInvalidMarketStatus, ret.Code)
+
+	// no new orders in a cancel-only market
+	order.Identify = 2
+	ret = input.handler(input.ctx, order)
+	require.Equal(t, types.CodeMarketCancelOnly, ret.Code)
+
+	ret = input.handler(input.ctx, types.MsgResumeMarket{Sender: haveCetAddress, TradingPair: symbol})
+	require.True(t, ret.IsOK(), ret.Log)
+	require.Equal(t, types.MarketOpen, input.mk.GetMarketStatus(input.ctx, symbol).Status)
+	ret = input.handler(input.ctx, order)
+	require.True(t, ret.IsOK(), ret.Log)
 }
 
 func TestGetGranularityOfOrder(t *testing.T) {
@@ -1139,8 +1455,18 @@ func TestCheckMsgCreateOrder(t *testing.T) {
 	err = checkMsgCreateOrder(input.ctx, input.mk, msg, issueAmount, issueAmount, dex.CET, math.MaxUint64)
 	require.EqualValues(t, err.Code(), types.CodeInvalidPricePrecision)
 
-	// Forbidden token
+	// Expire time has passed
 	msg.PricePrecision = 6
+	msg.ExpireTime = 1000
+	ctx := input.ctx.WithBlockTime(time.Unix(1000, 0))
+	err = checkMsgCreateOrder(ctx, input.mk, msg, issueAmount, issueAmount, dex.CET, math.MaxUint64)
+	require.EqualValues(t, types.CodeInvalidExpireTime, err.Code())
+
+	// Forbidden token
+	msg.ExpireTime = 1001
+	err = checkMsgCreateOrder(ctx, input.mk, msg, issueAmount, issueAmount, dex.CET, math.MaxUint64)
+	require.EqualValues(t, err.Code(), types.CodeTokenForbidByIssuer)
+	msg.ExpireTime = 0
 	err = checkMsgCreateOrder(input.ctx, input.mk, msg, issueAmount, issueAmount, dex.CET, math.MaxUint64)
 	require.EqualValues(t, err.Code(), types.CodeTokenForbidByIssuer)
 
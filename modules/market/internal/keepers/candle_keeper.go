@@ -0,0 +1,157 @@
+package keepers
+
+import (
+	"math"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	dex "github.com/coinexchain/cet-sdk/types"
+)
+
+// This keeper records the OHLCV candles of each market, in the spans of block, minute, hour and day
+type CandleKeeper struct {
+	marketKey sdk.StoreKey
+	codec     *codec.Codec
+}
+
+func NewCandleKeeper(key sdk.StoreKey, codec *codec.Codec) *CandleKeeper {
+	return &CandleKeeper{
+		marketKey: key,
+		codec:     codec,
+	}
+}
+
+func candleKey(symbol string, span byte, begin int64) []byte {
+	return dex.ConcatKeys(
+		CandleKeyPrefix,
+		[]byte(symbol),
+		[]byte{0x0, span},
+		int64ToBigEndianBytes(begin),
+	)
+}
+
+// the range of candles in [begin, end)
+func candleKeyRange(symbol string, span byte, begin, end int64) (start, stop []byte) {
+	return candleKey(symbol, span, begin), candleKey(symbol, span, end)
+}
+
+func (keeper *CandleKeeper) getCandle(ctx sdk.Context, key []byte) *types.Candle {
+	bz := ctx.KVStore(keeper.marketKey).Get(key)
+	if bz == nil {
+		return nil
+	}
+	var candle types.Candle
+	keeper.codec.MustUnmarshalBinaryBare(bz, &candle)
+	return &candle
+}
+
+// Merge the trades of the current block into the candles of all the spans. When a new candle
+// is started, the candles older than 'retention' spans are pruned.
+func (keeper *CandleKeeper) Update(ctx sdk.Context, blockCandle *types.Candle, retention int64) {
+	if blockCandle.TradeCount == 0 {
+		return
+	}
+	store := ctx.KVStore(keeper.marketKey)
+	symbol := blockCandle.TradingPair
+	for _, span := range types.CandleSpans {
+		begin := types.GetSpanBegin(span, ctx.BlockHeight(), ctx.BlockHeader().Time.Unix())
+		key := candleKey(symbol, span, begin)
+		candle := keeper.getCandle(ctx, key)
+		if candle == nil {
+			candle = types.NewCandle(symbol, span, begin)
+			keeper.removeCandles(ctx, symbol, span, begin-retention*types.GetSpanLength(span))
+		}
+		candle.Merge(blockCandle)
+		store.Set(key, keeper.codec.MustMarshalBinaryBare(candle))
+	}
+}
+
+// remove the candles which begin before 'end'
+func (keeper *CandleKeeper) removeCandles(ctx sdk.Context, symbol string, span byte, end int64) {
+	if end <= 0 {
+		return
+	}
+	store := ctx.KVStore(keeper.marketKey)
+	start, stop := candleKeyRange(symbol, span, 0, end)
+	var keys [][]byte
+	iter := store.Iterator(start, stop)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// Remove all the candles of a market
+func (keeper *CandleKeeper) RemoveAllCandles(ctx sdk.Context, symbol string) {
+	store := ctx.KVStore(keeper.marketKey)
+	start := dex.ConcatKeys(CandleKeyPrefix, []byte(symbol), []byte{0x0})
+	stop := dex.ConcatKeys(CandleKeyPrefix, []byte(symbol), []byte{0x1})
+	var keys [][]byte
+	iter := store.Iterator(start, stop)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// Return at most 'count' latest candles of a span, in the ascending order of time
+func (keeper *CandleKeeper) GetCandles(ctx sdk.Context, symbol string, span byte, count int) []*types.Candle {
+	store := ctx.KVStore(keeper.marketKey)
+	start, stop := candleKeyRange(symbol, span, 0, math.MaxInt64)
+	iter := store.ReverseIterator(start, stop)
+	defer iter.Close()
+	var result []*types.Candle
+	for ; iter.Valid() && len(result) < count; iter.Next() {
+		var candle types.Candle
+		keeper.codec.MustUnmarshalBinaryBare(iter.Value(), &candle)
+		result = append(result, &candle)
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// Return the ticker of the last 24 hours, which is merged from the minute candles
+func (keeper *CandleKeeper) GetTicker(ctx sdk.Context, symbol string) *types.Ticker {
+	endTime := ctx.BlockHeader().Time.Unix()
+	beginTime := types.GetSpanBegin(types.CandleSpanMinute, 0, endTime) -
+		types.SecondsPerDay + types.GetSpanLength(types.CandleSpanMinute)
+	if beginTime < 0 {
+		beginTime = 0
+	}
+
+	sum := types.NewCandle(symbol, types.CandleSpanMinute, beginTime)
+	store := ctx.KVStore(keeper.marketKey)
+	start, stop := candleKeyRange(symbol, types.CandleSpanMinute, beginTime, math.MaxInt64)
+	iter := store.Iterator(start, stop)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var candle types.Candle
+		keeper.codec.MustUnmarshalBinaryBare(iter.Value(), &candle)
+		sum.Merge(&candle)
+	}
+	if sum.TradeCount == 0 {
+		sum.Open, sum.High, sum.Low, sum.Close = sdk.ZeroDec(), sdk.ZeroDec(), sdk.ZeroDec(), sdk.ZeroDec()
+	}
+	return &types.Ticker{
+		TradingPair: symbol,
+		BeginTime:   beginTime,
+		EndTime:     endTime,
+		Open:        sum.Open,
+		High:        sum.High,
+		Low:         sum.Low,
+		Close:       sum.Close,
+		StockVolume: sum.StockVolume,
+		MoneyVolume: sum.MoneyVolume,
+		TradeCount:  sum.TradeCount,
+	}
+}
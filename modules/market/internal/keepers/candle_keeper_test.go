@@ -0,0 +1,69 @@
+package keepers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/keepers"
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	"github.com/coinexchain/cet-sdk/testapp"
+)
+
+func TestCandles(t *testing.T) {
+	app := testapp.NewTestApp()
+	ctx := app.NewCtx()
+	keeper := keepers.NewCandleKeeper(app.MarketKeeper.GetMarketKey(), app.Cdc)
+	begin := time.Unix(1500000000-1500000000%types.SecondsPerDay, 0)
+
+	// one trade every 30 seconds, and the price goes up by 1 in each trade
+	for i := int64(0); i < 10; i++ {
+		ctx = ctx.WithBlockHeight(100 + i).WithBlockTime(begin.Add(time.Duration(i*30) * time.Second))
+		candle := types.NewCandle("abc/cet", types.CandleSpanBlock, ctx.BlockHeight())
+		candle.AddTrade(sdk.NewDec(10+i), 100, 100*(10+i))
+		keeper.Update(ctx, candle, 3)
+	}
+
+	// only 3 spans are kept before the current one
+	blocks := keeper.GetCandles(ctx, "abc/cet", types.CandleSpanBlock, 100)
+	require.Equal(t, 4, len(blocks))
+	require.EqualValues(t, 106, blocks[0].Begin)
+	require.EqualValues(t, 109, blocks[3].Begin)
+	require.Equal(t, 2, len(keeper.GetCandles(ctx, "abc/cet", types.CandleSpanBlock, 2)))
+
+	minutes := keeper.GetCandles(ctx, "abc/cet", types.CandleSpanMinute, 100)
+	require.Equal(t, 4, len(minutes))
+	require.EqualValues(t, begin.Unix()+60, minutes[0].Begin)
+	require.Equal(t, sdk.NewDec(12), minutes[0].Open)
+	require.Equal(t, sdk.NewDec(13), minutes[0].Close)
+	require.Equal(t, sdk.NewInt(200), minutes[0].StockVolume)
+	require.EqualValues(t, 2, minutes[0].TradeCount)
+
+	days := keeper.GetCandles(ctx, "abc/cet", types.CandleSpanDay, 100)
+	require.Equal(t, 1, len(days))
+	require.EqualValues(t, begin.Unix(), days[0].Begin)
+	require.Equal(t, sdk.NewDec(10), days[0].Open)
+	require.Equal(t, sdk.NewDec(19), days[0].High)
+	require.Equal(t, sdk.NewDec(10), days[0].Low)
+	require.Equal(t, sdk.NewDec(19), days[0].Close)
+	require.Equal(t, sdk.NewInt(1000), days[0].StockVolume)
+	require.Equal(t, sdk.NewInt(14500), days[0].MoneyVolume)
+
+	// the ticker is merged from the kept minute candles
+	ticker := keeper.GetTicker(ctx, "abc/cet")
+	require.Equal(t, sdk.NewDec(12), ticker.Open)
+	require.Equal(t, sdk.NewDec(19), ticker.Close)
+	require.EqualValues(t, 8, ticker.TradeCount)
+
+	// the minute candles older than one day are not included in the ticker
+	ctx = ctx.WithBlockTime(begin.Add(25 * time.Hour))
+	ticker = keeper.GetTicker(ctx, "abc/cet")
+	require.EqualValues(t, 0, ticker.TradeCount)
+	require.Equal(t, sdk.ZeroDec(), ticker.Close)
+
+	keeper.RemoveAllCandles(ctx, "abc/cet")
+	require.Equal(t, 0, len(keeper.GetCandles(ctx, "abc/cet", types.CandleSpanDay, 100)))
+}
@@ -17,6 +17,7 @@ import (
 
 type QueryMarketInfoAndParams interface {
 	GetParams(ctx sdk.Context) types.Params
+	GetMarketParams(ctx sdk.Context, symbol string) types.Params
 	GetMarketVolume(ctx sdk.Context, stock, money string, stockVolume, moneyVolume sdk.Dec) sdk.Dec
 }
 
@@ -168,12 +169,35 @@ func (k Keeper) SetParams(ctx sdk.Context, params types.Params) {
 	k.paramSubspace.SetParamSet(ctx, &params)
 }
 
-// GetParams gets the asset module's parameters.
+// GetParams gets the asset module's parameters. The params added after the chain starts take their
+// defaults until they are set.
 func (k Keeper) GetParams(ctx sdk.Context) (params types.Params) {
-	k.paramSubspace.GetParamSet(ctx, &params)
+	params = types.DefaultParams()
+	for _, pair := range params.ParamSetPairs() {
+		if types.IsUpgradedParamKey(pair.Key) {
+			k.paramSubspace.GetIfExists(ctx, pair.Key, pair.Value)
+		} else {
+			k.paramSubspace.Get(ctx, pair.Key, pair.Value)
+		}
+	}
+	// the makers are charged at the same rate as the takers until the chain sets the maker rate
+	if !k.paramSubspace.Has(ctx, types.KeyMakerFeeRate) {
+		params.MakerFeeRate = params.MarketFeeRate
+	}
 	return
 }
 
+// GetMarketParams returns the global params with the overrides of the market applied,
+// or the global params if the market does not exist.
+func (k Keeper) GetMarketParams(ctx sdk.Context, symbol string) types.Params {
+	params := k.GetParams(ctx)
+	info, err := k.GetMarketInfo(ctx, symbol)
+	if err != nil {
+		return params
+	}
+	return info.GetEffectiveParams(params)
+}
+
 func (k Keeper) GetMarketFeeMin(ctx sdk.Context) int64 {
 	return k.GetParams(ctx).MarketFeeMin
 }
@@ -198,6 +222,7 @@ func (k Keeper) SetMarket(ctx sdk.Context, info types.MarketInfo) sdk.Error {
 }
 
 func (k Keeper) RemoveMarket(ctx sdk.Context, symbol string) sdk.Error {
+	k.RemoveMarketStatus(ctx, symbol)
 	return k.gmk.RemoveMarket(ctx, symbol)
 }
 
@@ -100,6 +100,33 @@ func TestKeeper_GetParams(t *testing.T) {
 	assert.Equal(t, fee, market.DefaultParams().MarketFeeMin)
 }
 
+func TestKeeper_GetParamsAfterUpgrade(t *testing.T) {
+	// the params of a chain started before the new params are added
+	app := testapp.NewTestApp()
+	ctx := app.NewCtx()
+	subspace, ok := app.ParamsKeeper.GetSubspace(market.StoreKey)
+	assert.True(t, ok)
+	params := market.DefaultParams()
+	params.MarketFeeMin = 2000000
+	params.FeeForZeroDeal = 2000000
+	params.MarketFeeRate = 20
+	for _, pair := range params.ParamSetPairs() {
+		if !market.IsUpgradedParamKey(pair.Key) {
+			subspace.Set(ctx, pair.Key, pair.Value)
+		}
+	}
+
+	// the makers are charged at the market rate of the chain instead of the default maker rate
+	params.MakerFeeRate = 20
+	got := app.MarketKeeper.GetParams(ctx)
+	assert.Equal(t, params, got)
+	assert.Equal(t, market.DefaultParams().CandleRetention, got.CandleRetention)
+
+	got.TradeRetention = 100
+	app.MarketKeeper.SetParams(ctx, got)
+	assert.Equal(t, int64(100), app.MarketKeeper.GetParams(ctx).TradeRetention)
+}
+
 func TestKeeper_SetMarket(t *testing.T) {
 	ctx := app.NewCtx()
 	info1 := market.MarketInfo{
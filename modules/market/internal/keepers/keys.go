@@ -2,6 +2,13 @@ package keepers
 
 var (
 	MarketIdentifierPrefix = []byte{0x15}
+	CandleKeyPrefix        = []byte{0x18}
+	UserVolumeKeyPrefix    = []byte{0x19}
+	MarketStatusKeyPrefix  = []byte{0x1A}
+	TradeKeyPrefix         = []byte{0x1B}
+	AccountTradeKeyPrefix  = []byte{0x1C}
+	OrderTradeKeyPrefix    = []byte{0x1D}
+	TradeHeightKeyPrefix   = []byte{0x1F}
 	DelistKey              = []byte{0x40}
 	DelistRevKey           = []byte{0x42}
 )
@@ -0,0 +1,30 @@
+package keepers
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+)
+
+// The status of each market is stored next to its MarketInfo, and the open markets may have no status
+
+func marketStatusKey(symbol string) []byte {
+	return append(MarketStatusKeyPrefix, []byte(symbol)...)
+}
+
+func (k Keeper) GetMarketStatus(ctx sdk.Context, symbol string) types.MarketStatus {
+	var status types.MarketStatus
+	bz := ctx.KVStore(k.marketKey).Get(marketStatusKey(symbol))
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryBare(bz, &status)
+	}
+	return status
+}
+
+func (k Keeper) SetMarketStatus(ctx sdk.Context, symbol string, status types.MarketStatus) {
+	ctx.KVStore(k.marketKey).Set(marketStatusKey(symbol), k.cdc.MustMarshalBinaryBare(status))
+}
+
+func (k Keeper) RemoveMarketStatus(ctx sdk.Context, symbol string) {
+	ctx.KVStore(k.marketKey).Delete(marketStatusKey(symbol))
+}
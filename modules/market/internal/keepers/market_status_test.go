@@ -0,0 +1,33 @@
+package keepers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	"github.com/coinexchain/cet-sdk/testapp"
+)
+
+func TestMarketStatus(t *testing.T) {
+	app := testapp.NewTestApp()
+	ctx := app.NewCtx()
+	keeper := app.MarketKeeper
+	info := types.MarketInfo{Stock: "abc", Money: "cet", LastExecutedPrice: sdk.ZeroDec()}
+	require.Nil(t, keeper.SetMarket(ctx, info))
+
+	// a market is open without status
+	status := keeper.GetMarketStatus(ctx, info.GetSymbol())
+	require.Equal(t, types.MarketOpen, status.Status)
+
+	status = types.MarketStatus{Status: types.MarketHalted, Reason: types.StatusChangedByCircuitBreaker,
+		Height: 10, RefPrice: sdk.NewDec(100), RefHeight: 5}
+	keeper.SetMarketStatus(ctx, info.GetSymbol(), status)
+	require.Equal(t, status, keeper.GetMarketStatus(ctx, info.GetSymbol()))
+	require.Equal(t, types.MarketOpen, keeper.GetMarketStatus(ctx, "abd/cet").Status)
+
+	require.Nil(t, keeper.RemoveMarket(ctx, info.GetSymbol()))
+	require.Equal(t, types.MarketOpen, keeper.GetMarketStatus(ctx, info.GetSymbol()).Status)
+}
@@ -3,6 +3,7 @@ package keepers
 import (
 	"bytes"
 	"encoding/binary"
+	"math"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -17,6 +18,9 @@ var (
 	BidListKeyPrefix       = []byte{0x12}
 	AskListKeyPrefix       = []byte{0x13}
 	OrderQueueKeyPrefix    = []byte{0x14}
+	BuyStopKeyPrefix       = []byte{0x16}
+	SellStopKeyPrefix      = []byte{0x17}
+	ExpireQueueKeyPrefix   = []byte{0x1E}
 	NewlyAddedKeyPrefix    = []byte{0x66}
 	NewlyAddedKeyEnd       = []byte{0x67}
 	LastOrderCleanUpDayKey = []byte{0x20}
@@ -59,6 +63,11 @@ type OrderKeeper interface {
 	GetOlderThan(ctx sdk.Context, height int64) []*types.Order
 	GetOrdersAtHeight(ctx sdk.Context, height int64) []*types.Order
 	GetMatchingCandidates(ctx sdk.Context) []*types.Order
+	GetOrdersToTrigger(ctx sdk.Context, lastPrice sdk.Dec) []*types.Order
+	GetDepth(ctx sdk.Context, side byte, levels int, precision byte) []*DepthLevel
+	GetOrdersInPage(ctx sdk.Context, filter OrderFilter, page PageRequest) ([]*types.Order, string)
+	MarkNewlyAdded(ctx sdk.Context)
+	UnmarkNewlyAdded(ctx sdk.Context)
 	GetSymbol() string
 }
 
@@ -111,6 +120,40 @@ func (keeper *PersistentOrderKeeper) orderQueueKey(order *types.Order) []byte {
 	)
 }
 
+// build the key for the orders with expire time, which are sorted by their expire time
+func expireQueueKey(order *types.Order) []byte {
+	return dex.ConcatKeys(
+		ExpireQueueKeyPrefix,
+		int64ToBigEndianBytes(order.ExpireTime),
+		[]byte(order.OrderID()),
+	)
+}
+
+// build the key for the dormant stop orders, which are sorted by trigger price
+func (keeper *PersistentOrderKeeper) stopListKey(order *types.Order) []byte {
+	prefix := BuyStopKeyPrefix
+	if order.Side == types.SELL {
+		prefix = SellStopKeyPrefix
+	}
+	return dex.ConcatKeys(
+		prefix,
+		[]byte(keeper.symbol),
+		[]byte{0x0},
+		types.DecToBigEndianBytes(order.TriggerPrice),
+		[]byte(order.OrderID()),
+	)
+}
+
+// amino decodes a nil trigger price as zero, so we restore it for the orders which are not stop orders
+func decodeOrder(codec *codec.Codec, bz []byte) *types.Order {
+	order := &types.Order{}
+	codec.MustUnmarshalBinaryBare(bz, order)
+	if !order.IsStopOrder() {
+		order.TriggerPrice = sdk.Dec{}
+	}
+	return order
+}
+
 func NewOrderKeeper(key sdk.StoreKey, symbol string, codec *codec.Codec) OrderKeeper {
 	return &PersistentOrderKeeper{
 		marketKey: key,
@@ -130,11 +173,20 @@ func int64ToBigEndianBytes(n int64) []byte {
 }
 
 func (keeper *PersistentOrderKeeper) Add(ctx sdk.Context, order *types.Order) sdk.Error {
-	// mark this order book as newly-added
+	keeper.MarkNewlyAdded(ctx)
+	return keeper.Update(ctx, order)
+}
+
+// mark this order book as newly-added, such that it will be processed in EndBlocker
+func (keeper *PersistentOrderKeeper) MarkNewlyAdded(ctx sdk.Context) {
 	store := ctx.KVStore(keeper.marketKey)
 	store.Set(append(NewlyAddedKeyPrefix, []byte(keeper.symbol)...), []byte{'a'})
+}
 
-	return keeper.Update(ctx, order)
+// mark this order book as not-newly-added, such that it will be skipped in EndBlocker
+func (keeper *PersistentOrderKeeper) UnmarkNewlyAdded(ctx sdk.Context) {
+	store := ctx.KVStore(keeper.marketKey)
+	store.Delete(append(NewlyAddedKeyPrefix, []byte(keeper.symbol)...))
 }
 
 func (keeper *PersistentOrderKeeper) Update(ctx sdk.Context, order *types.Order) sdk.Error {
@@ -148,6 +200,17 @@ func (keeper *PersistentOrderKeeper) Update(ctx sdk.Context, order *types.Order)
 	key = keeper.orderQueueKey(order)
 	store.Set(key, []byte{})
 
+	// add it to the global expire queue
+	if order.ExpireTime != 0 {
+		store.Set(expireQueueKey(order), []byte{})
+	}
+
+	// a dormant stop order waits in the stop list instead of the bidList and askList
+	if order.IsDormant() {
+		store.Set(keeper.stopListKey(order), []byte{})
+		return nil
+	}
+
 	// add it to the local bidList and askList
 	if order.Side == types.BID {
 		key = keeper.bidListKey(order)
@@ -173,6 +236,15 @@ func (keeper *PersistentOrderKeeper) Remove(ctx sdk.Context, order *types.Order)
 	key = keeper.orderQueueKey(order)
 	store.Delete(key)
 
+	if order.ExpireTime != 0 {
+		store.Delete(expireQueueKey(order))
+	}
+
+	if order.IsDormant() {
+		store.Delete(keeper.stopListKey(order))
+		return nil
+	}
+
 	// remove it from the local bidList and askList
 	if order.Side == types.BID {
 		key = keeper.bidListKey(order)
@@ -245,16 +317,13 @@ func (keeper *PersistentOrderKeeper) getOrder(ctx sdk.Context, orderID string) *
 	if len(orderBytes) == 0 {
 		return nil
 	}
-	order := &types.Order{}
-	keeper.codec.MustUnmarshalBinaryBare(orderBytes, order)
-	return order
+	return decodeOrder(keeper.codec, orderBytes)
 }
 
 // Return the bid orders and ask orders which have proper prices and have possibilities for deal
 func (keeper *PersistentOrderKeeper) GetMatchingCandidates(ctx sdk.Context) []*types.Order {
 	store := ctx.KVStore(keeper.marketKey)
-	// mark this order book as not-newly-added
-	store.Delete(append(NewlyAddedKeyPrefix, []byte(keeper.symbol)...))
+	keeper.UnmarkNewlyAdded(ctx)
 
 	priceStartPos := len(keeper.symbol) + 2
 	priceEndPos := priceStartPos + types.DecByteCount
@@ -308,6 +377,90 @@ func (keeper *PersistentOrderKeeper) GetMatchingCandidates(ctx sdk.Context) []*t
 	return result
 }
 
+// Return the dormant stop orders which will be triggered by lastPrice:
+// buy stop orders whose trigger prices are not higher than lastPrice, and
+// sell stop orders whose trigger prices are not lower than lastPrice
+func (keeper *PersistentOrderKeeper) GetOrdersToTrigger(ctx sdk.Context, lastPrice sdk.Dec) []*types.Order {
+	if lastPrice.IsNil() || lastPrice.IsZero() {
+		return nil
+	}
+	store := ctx.KVStore(keeper.marketKey)
+	priceEndPos := len(keeper.symbol) + 2 + types.DecByteCount
+	lastPriceBytes := types.DecToBigEndianBytes(lastPrice)
+
+	var result []*types.Order
+	collect := func(iter sdk.Iterator) {
+		defer iter.Close()
+		for ; iter.Valid(); iter.Next() {
+			order := keeper.getOrder(ctx, string(iter.Key()[priceEndPos:]))
+			if order != nil {
+				result = append(result, order)
+			}
+		}
+	}
+	// order IDs only contain printable characters, so 0xFF is larger than all of them
+	collect(store.Iterator(
+		dex.ConcatKeys(BuyStopKeyPrefix, []byte(keeper.symbol), []byte{0x0}),
+		dex.ConcatKeys(BuyStopKeyPrefix, []byte(keeper.symbol), []byte{0x0}, lastPriceBytes, []byte{0xFF}),
+	))
+	collect(store.Iterator(
+		dex.ConcatKeys(SellStopKeyPrefix, []byte(keeper.symbol), []byte{0x0}, lastPriceBytes),
+		dex.ConcatKeys(SellStopKeyPrefix, []byte(keeper.symbol), []byte{0x1}),
+	))
+	return result
+}
+
+// DepthLevel aggregates the orders whose prices fall into the same price level
+type DepthLevel struct {
+	Price      sdk.Dec `json:"price"`
+	Amount     sdk.Int `json:"amount"`
+	OrderCount int     `json:"order_count"`
+}
+
+// Return at most 'levels' price levels on one side of the order book, beginning from the best price.
+// Prices are rounded to 'precision' decimal places: bid prices are rounded down and ask prices are
+// rounded up, so a price level never shows a better price than the orders in it.
+// Only the visible slices of the iceberg orders are counted.
+func (keeper *PersistentOrderKeeper) GetDepth(ctx sdk.Context, side byte, levels int, precision byte) []*DepthLevel {
+	store := ctx.KVStore(keeper.marketKey)
+	priceEndPos := len(keeper.symbol) + 2 + types.DecByteCount
+	var iter sdk.Iterator
+	if side == types.BUY {
+		iter = store.ReverseIterator(dex.ConcatKeys(BidListKeyPrefix, []byte(keeper.symbol), []byte{0x0}),
+			dex.ConcatKeys(BidListKeyPrefix, []byte(keeper.symbol), []byte{0x1}))
+	} else {
+		iter = store.Iterator(dex.ConcatKeys(AskListKeyPrefix, []byte(keeper.symbol), []byte{0x0}),
+			dex.ConcatKeys(AskListKeyPrefix, []byte(keeper.symbol), []byte{0x1}))
+	}
+	defer iter.Close()
+
+	scale := int64(math.Pow10(int(precision)))
+	var result []*DepthLevel
+	for ; iter.Valid(); iter.Next() {
+		order := keeper.getOrder(ctx, string(iter.Key()[priceEndPos:]))
+		if order == nil {
+			continue
+		}
+		price := order.Price.MulInt64(scale)
+		if side == types.BUY {
+			price = price.TruncateDec()
+		} else {
+			price = price.Ceil()
+		}
+		price = price.QuoInt64(scale)
+		if n := len(result); n == 0 || !result[n-1].Price.Equal(price) {
+			if n == levels {
+				break
+			}
+			result = append(result, &DepthLevel{Price: price, Amount: sdk.ZeroInt()})
+		}
+		level := result[len(result)-1]
+		level.Amount = level.Amount.AddRaw(order.VisibleLeftStock())
+		level.OrderCount++
+	}
+	return result
+}
+
 ////////////////////////////////////////////////
 
 // Global order keep can lookup a order, given its ID or the prefix of its ID, i.e. the sender's address
@@ -315,6 +468,8 @@ type GlobalOrderKeeper interface {
 	GetAllOrders(ctx sdk.Context) []*types.Order
 	QueryOrder(ctx sdk.Context, orderID string) *types.Order
 	GetOrdersFromUser(ctx sdk.Context, user string) []string
+	GetOrdersFromUserInPage(ctx sdk.Context, user string, filter OrderFilter, page PageRequest) ([]*types.Order, string)
+	GetExpiredOrders(ctx sdk.Context, unixTime int64) []*types.Order
 }
 
 type PersistentGlobalOrderKeeper struct {
@@ -354,9 +509,7 @@ func (keeper *PersistentGlobalOrderKeeper) GetAllOrders(ctx sdk.Context) []*type
 	iter := store.Iterator(start, end)
 	defer iter.Close()
 	for ; iter.Valid(); iter.Next() {
-		order := &types.Order{}
-		keeper.codec.MustUnmarshalBinaryBare(iter.Value(), order)
-		result = append(result, order)
+		result = append(result, decodeOrder(keeper.codec, iter.Value()))
 	}
 	return result
 }
@@ -368,7 +521,22 @@ func (keeper *PersistentGlobalOrderKeeper) QueryOrder(ctx sdk.Context, orderID s
 	if len(orderBytes) == 0 {
 		return nil
 	}
-	order := &types.Order{}
-	keeper.codec.MustUnmarshalBinaryBare(orderBytes, order)
-	return order
+	return decodeOrder(keeper.codec, orderBytes)
+}
+
+// Using the expire queue, find the orders whose expire time is not later than unixTime
+func (keeper *PersistentGlobalOrderKeeper) GetExpiredOrders(ctx sdk.Context, unixTime int64) []*types.Order {
+	store := ctx.KVStore(keeper.marketKey)
+	var result []*types.Order
+	start := dex.ConcatKeys(ExpireQueueKeyPrefix, int64ToBigEndianBytes(1))
+	end := dex.ConcatKeys(ExpireQueueKeyPrefix, int64ToBigEndianBytes(unixTime+1))
+	iter := store.Iterator(start, end)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		orderID := string(iter.Key()[len(start):])
+		if order := keeper.QueryOrder(ctx, orderID); order != nil {
+			result = append(result, order)
+		}
+	}
+	return result
 }
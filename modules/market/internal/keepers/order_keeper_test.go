@@ -8,6 +8,7 @@ import (
 	sdkstore "github.com/cosmos/cosmos-sdk/store"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/libs/log"
 	dbm "github.com/tendermint/tm-db"
@@ -235,3 +236,116 @@ func TestOrderBook3(t *testing.T) {
 		t.Errorf("Matching result must be nil!")
 	}
 }
+
+func TestStopOrders(t *testing.T) {
+	ctx, keys := newContextAndMarketKey(unitChainID)
+	keeper := newKeeperForTest(keys.marketKey)
+	gkeeper := newGlobalKeeperForTest(keys.marketKey)
+
+	stopOrder := func(order *types.Order, triggerPrice int64) *types.Order {
+		order.OrderType = types.StopLimitOrder
+		order.TriggerPrice = sdk.NewDec(triggerPrice).QuoInt(sdk.NewInt(10000))
+		return order
+	}
+	orders := []*types.Order{
+		stopOrder(newTO("00001", 1, 11100, 50, types.BUY, types.GTE, 998), 11000),  //0
+		stopOrder(newTO("00002", 2, 11300, 50, types.BUY, types.GTE, 998), 11200),  //1
+		stopOrder(newTO("00003", 3, 10800, 50, types.SELL, types.GTE, 998), 10900), //2
+		stopOrder(newTO("00004", 4, 10600, 50, types.SELL, types.GTE, 998), 10700), //3
+		newTO("00005", 5, 10500, 50, types.SELL, types.GTE, 998),                   //4
+	}
+	for _, order := range orders {
+		require.Nil(t, keeper.Add(ctx, order))
+	}
+	// dormant stop orders do not take part in matching
+	require.Equal(t, 0, len(keeper.GetMatchingCandidates(ctx)))
+	require.Equal(t, 5, len(keeper.GetOrdersAtHeight(ctx, 998)))
+
+	require.Nil(t, keeper.GetOrdersToTrigger(ctx, sdk.ZeroDec()))
+	require.Equal(t, 0, len(keeper.GetOrdersToTrigger(ctx, sdk.NewDecWithPrec(10950, 4))))
+	triggered := keeper.GetOrdersToTrigger(ctx, sdk.NewDecWithPrec(11000, 4))
+	require.Equal(t, 1, len(triggered))
+	require.Equal(t, orders[0].OrderID(), triggered[0].OrderID())
+	require.True(t, triggered[0].IsTriggeredBy(sdk.NewDecWithPrec(11000, 4)))
+	triggered = keeper.GetOrdersToTrigger(ctx, sdk.NewDecWithPrec(10700, 4))
+	require.Equal(t, 2, len(triggered))
+	require.Equal(t, orders[3].OrderID(), triggered[0].OrderID())
+	require.Equal(t, orders[2].OrderID(), triggered[1].OrderID())
+
+	// a triggered stop order leaves the stop list and enters the bid list
+	require.Nil(t, keeper.Remove(ctx, orders[1]))
+	require.Nil(t, gkeeper.QueryOrder(ctx, orders[1].OrderID()))
+	require.Equal(t, 1, len(keeper.GetOrdersToTrigger(ctx, sdk.NewDecWithPrec(11300, 4))))
+	require.Nil(t, keeper.Remove(ctx, orders[0]))
+	orders[0].TriggerHeight = 999
+	require.Nil(t, keeper.Add(ctx, orders[0]))
+	require.False(t, orders[0].IsDormant())
+	require.Equal(t, 0, len(keeper.GetOrdersToTrigger(ctx, sdk.NewDecWithPrec(11300, 4))))
+	candidates := keeper.GetMatchingCandidates(ctx)
+	require.Equal(t, 2, len(candidates))
+	require.Equal(t, orders[0].OrderID(), candidates[0].OrderID())
+	require.Equal(t, orders[4].OrderID(), candidates[1].OrderID())
+}
+
+func TestExpireQueue(t *testing.T) {
+	ctx, keys := newContextAndMarketKey(unitChainID)
+	keeper := newKeeperForTest(keys.marketKey)
+	gkeeper := newGlobalKeeperForTest(keys.marketKey)
+
+	expireAt := func(order *types.Order, expireTime int64) *types.Order {
+		order.ExpireTime = expireTime
+		return order
+	}
+	orders := []*types.Order{
+		expireAt(newTO("00001", 1, 11100, 50, types.BUY, types.GTE, 998), 1000),  //0
+		expireAt(newTO("00002", 2, 11300, 50, types.BUY, types.GTE, 998), 2000),  //1
+		expireAt(newTO("00003", 3, 10800, 50, types.SELL, types.GTE, 998), 1000), //2
+		newTO("00004", 4, 10600, 50, types.SELL, types.GTE, 998),                 //3
+	}
+	for _, order := range orders {
+		require.Nil(t, keeper.Add(ctx, order))
+	}
+	require.Equal(t, 0, len(gkeeper.GetExpiredOrders(ctx, 999)))
+	expired := gkeeper.GetExpiredOrders(ctx, 1000)
+	require.Equal(t, 2, len(expired))
+	require.Equal(t, 3, len(gkeeper.GetExpiredOrders(ctx, 2000)))
+
+	// the removed orders leave the expire queue
+	require.Nil(t, keeper.Remove(ctx, orders[0]))
+	expired = gkeeper.GetExpiredOrders(ctx, 1500)
+	require.Equal(t, 1, len(expired))
+	require.Equal(t, orders[2].OrderID(), expired[0].OrderID())
+}
+
+func TestGetDepth(t *testing.T) {
+	ctx, keys := newContextAndMarketKey(unitChainID)
+	keeper := newKeeperForTest(keys.marketKey)
+	for _, order := range createTO3() {
+		require.Nil(t, keeper.Add(ctx, order))
+	}
+
+	bids := keeper.GetDepth(ctx, types.BUY, 10, 4)
+	require.Equal(t, 3, len(bids))
+	require.Equal(t, sdk.NewDecWithPrec(11080, 4), bids[0].Price)
+	require.Equal(t, sdk.NewDecWithPrec(11051, 4), bids[1].Price)
+	require.Equal(t, sdk.NewDecWithPrec(10900, 4), bids[2].Price)
+	asks := keeper.GetDepth(ctx, types.SELL, 2, 4)
+	require.Equal(t, 2, len(asks))
+	require.Equal(t, sdk.NewDecWithPrec(12010, 4), asks[0].Price)
+	require.Equal(t, sdk.NewInt(100), asks[0].Amount)
+	require.Equal(t, sdk.NewDecWithPrec(12032, 4), asks[1].Price)
+
+	// bid prices are rounded down and ask prices are rounded up
+	bids = keeper.GetDepth(ctx, types.BUY, 10, 2)
+	require.Equal(t, 2, len(bids))
+	require.Equal(t, sdk.NewDecWithPrec(110, 2), bids[0].Price)
+	require.Equal(t, sdk.NewInt(100), bids[0].Amount)
+	require.Equal(t, 2, bids[0].OrderCount)
+	require.Equal(t, sdk.NewDecWithPrec(109, 2), bids[1].Price)
+	require.Equal(t, 1, bids[1].OrderCount)
+	asks = keeper.GetDepth(ctx, types.SELL, 10, 2)
+	require.Equal(t, 1, len(asks))
+	require.Equal(t, sdk.NewDecWithPrec(121, 2), asks[0].Price)
+	require.Equal(t, sdk.NewInt(280), asks[0].Amount)
+	require.Equal(t, 3, asks[0].OrderCount)
+}
@@ -0,0 +1,151 @@
+package keepers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	dex "github.com/coinexchain/cet-sdk/types"
+)
+
+const (
+	DefaultOrderPageLimit = 100
+	MaxOrderPageLimit     = 1000
+)
+
+// OrderFilter selects the orders returned by the paginated queries, its zero values match every order.
+// The height range is inclusive, and MaxHeight is unlimited when it is zero.
+type OrderFilter struct {
+	TradingPair string `json:"trading_pair,omitempty"`
+	Side        byte   `json:"side,omitempty"`
+	TimeInForce int64  `json:"time_in_force,omitempty"`
+	MinHeight   int64  `json:"min_height,omitempty"`
+	MaxHeight   int64  `json:"max_height,omitempty"`
+}
+
+func (f OrderFilter) ValidateBasic() sdk.Error {
+	if f.Side != 0 && f.Side != types.BID && f.Side != types.ASK {
+		return types.ErrInvalidTradeSide()
+	}
+	if f.TimeInForce != 0 && (f.TimeInForce < types.GTE || f.TimeInForce > types.FOK) {
+		return types.ErrInvalidTimeInForce(f.TimeInForce)
+	}
+	if f.MinHeight < 0 || f.MaxHeight < 0 || (f.MaxHeight != 0 && f.MinHeight > f.MaxHeight) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("invalid height range : [%d, %d]", f.MinHeight, f.MaxHeight))
+	}
+	return nil
+}
+
+func (f OrderFilter) Match(order *types.Order) bool {
+	return (f.TradingPair == "" || f.TradingPair == order.TradingPair) &&
+		(f.Side == 0 || f.Side == order.Side) &&
+		(f.TimeInForce == 0 || f.TimeInForce == order.TimeInForce) &&
+		order.Height >= f.MinHeight &&
+		(f.MaxHeight == 0 || order.Height <= f.MaxHeight)
+}
+
+func (f OrderFilter) maxHeight() int64 {
+	if f.MaxHeight == 0 {
+		return math.MaxInt64 - 1
+	}
+	return f.MaxHeight
+}
+
+// PageRequest selects a page of the matched orders. Cursor is the NextCursor returned with the
+// previous page, and Offset skips orders after the cursor. Limit is DefaultOrderPageLimit when
+// it is zero and can not exceed MaxOrderPageLimit.
+type PageRequest struct {
+	Offset int    `json:"offset,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+func (p PageRequest) ValidateBasic() sdk.Error {
+	if p.Offset < 0 || p.Limit < 0 {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("invalid offset or limit : %d, %d", p.Offset, p.Limit))
+	}
+	if _, err := hex.DecodeString(p.Cursor); err != nil {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("invalid cursor : %s", p.Cursor))
+	}
+	return nil
+}
+
+func (p PageRequest) limit() int {
+	if p.Limit <= 0 {
+		return DefaultOrderPageLimit
+	} else if p.Limit > MaxOrderPageLimit {
+		return MaxOrderPageLimit
+	}
+	return p.Limit
+}
+
+// collect the orders matched by the filter from an iterator over an index, whose keys are mapped to
+// orders by getOrder. The next cursor is the key of the last order when the page is full.
+func collectOrders(iter sdk.Iterator, getOrder func(key, value []byte) *types.Order,
+	filter OrderFilter, page PageRequest) (orders []*types.Order, nextCursor string) {
+
+	defer iter.Close()
+	skipped, limit := 0, page.limit()
+	for ; iter.Valid() && len(orders) < limit; iter.Next() {
+		order := getOrder(iter.Key(), iter.Value())
+		if order == nil || !filter.Match(order) {
+			continue
+		}
+		if skipped < page.Offset {
+			skipped++
+			continue
+		}
+		orders = append(orders, order)
+		if len(orders) == limit {
+			nextCursor = hex.EncodeToString(iter.Key())
+		}
+	}
+	return
+}
+
+// GetOrdersInPage returns the orders of this market from the newest to the oldest, the height range
+// of the filter is served by the order queue, and other conditions are checked one by one.
+func (keeper *PersistentOrderKeeper) GetOrdersInPage(ctx sdk.Context, filter OrderFilter, page PageRequest) ([]*types.Order, string) {
+	store := ctx.KVStore(keeper.marketKey)
+	prefix := dex.ConcatKeys(OrderQueueKeyPrefix, []byte(keeper.symbol), []byte{0x0})
+	start := dex.ConcatKeys(prefix, int64ToBigEndianBytes(filter.MinHeight))
+	end := dex.ConcatKeys(prefix, int64ToBigEndianBytes(filter.maxHeight()+1))
+	if cursor, _ := hex.DecodeString(page.Cursor); len(cursor) != 0 {
+		// the reverse iterator excludes the end, which is the last order of the previous page
+		if string(cursor) < string(end) {
+			end = cursor
+		}
+	}
+	if string(start) >= string(end) {
+		return nil, ""
+	}
+	orderIDPos := len(prefix) + 8
+	return collectOrders(store.ReverseIterator(start, end), func(key, _ []byte) *types.Order {
+		return keeper.getOrder(ctx, string(key[orderIDPos:]))
+	}, filter, page)
+}
+
+// GetOrdersFromUserInPage returns the orders of a user in the sequence of their IDs
+func (keeper *PersistentGlobalOrderKeeper) GetOrdersFromUserInPage(ctx sdk.Context, user string,
+	filter OrderFilter, page PageRequest) ([]*types.Order, string) {
+
+	store := ctx.KVStore(keeper.marketKey)
+	start := orderBookKey(user + "-")
+	end := orderBookKey(user + string([]byte{0xFF}))
+	if cursor, _ := hex.DecodeString(page.Cursor); len(cursor) != 0 {
+		// the iterator includes the start, so skip the last order of the previous page
+		next := append(cursor, 0x0)
+		if string(next) > string(start) {
+			start = next
+		}
+	}
+	if string(start) >= string(end) {
+		return nil, ""
+	}
+	return collectOrders(store.Iterator(start, end), func(_, value []byte) *types.Order {
+		return decodeOrder(keeper.codec, value)
+	}, filter, page)
+}
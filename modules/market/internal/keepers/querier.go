@@ -17,8 +17,14 @@ const (
 	QueryMarket            = "market-info"
 	QueryMarkets           = "market-list"
 	QueryOrdersInMarket    = "orders-in-market"
+	QueryDepth             = "depth"
+	QueryCandles           = "candles"
+	QueryTicker            = "ticker"
 	QueryOrder             = "order-info"
 	QueryUserOrders        = "user-order-list"
+	QueryTrades            = "trades"
+	QueryUserTrades        = "user-trades"
+	QueryOrderTrades       = "order-trades"
 	QueryWaitCancelMarkets = "wait-cancel-markets"
 	QueryParameters        = "parameters"
 )
@@ -35,10 +41,22 @@ func NewQuerier(mk Keeper) sdk.Querier {
 			return queryMarketList(ctx, req, mk)
 		case QueryOrdersInMarket:
 			return queryOrdersInMarket(ctx, req, mk)
+		case QueryDepth:
+			return queryDepth(ctx, req, mk)
+		case QueryCandles:
+			return queryCandles(ctx, req, mk)
+		case QueryTicker:
+			return queryTicker(ctx, req, mk)
 		case QueryOrder:
 			return queryOrder(ctx, req, mk)
 		case QueryUserOrders:
 			return queryUserOrderList(ctx, req, mk)
+		case QueryTrades:
+			return queryTrades(ctx, req, mk)
+		case QueryUserTrades:
+			return queryUserTrades(ctx, req, mk)
+		case QueryOrderTrades:
+			return queryOrderTrades(ctx, req, mk)
 		case QueryWaitCancelMarkets:
 			return queryWaitCancelMarkets(ctx, req, mk)
 		default:
@@ -75,6 +93,10 @@ type QueryMarketInfo struct {
 	PricePrecision    string         `json:"price_precision"`
 	LastExecutedPrice sdk.Dec        `json:"last_executed_price"`
 	OrderPrecision    string         `json:"order_precision"`
+	// the overrides of the global params
+	Params types.MarketParams `json:"params"`
+	// open, halted, cancel-only or auction-only
+	Status string `json:"status"`
 }
 
 func queryMarket(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.Error) {
@@ -95,6 +117,8 @@ func queryMarket(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk
 		PricePrecision:    strconv.Itoa(int(info.PricePrecision)),
 		LastExecutedPrice: info.LastExecutedPrice,
 		OrderPrecision:    strconv.Itoa(int(info.OrderPrecision)),
+		Params:            info.Params,
+		Status:            types.MarketStatusName(mk.GetMarketStatus(ctx, info.GetSymbol()).Status),
 	}
 	bz, err := codec.MarshalJSONIndent(mk.cdc, queryInfo)
 	if err != nil {
@@ -117,6 +141,8 @@ func queryMarketList(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte,
 			PricePrecision:    strconv.Itoa(int(info.PricePrecision)),
 			LastExecutedPrice: info.LastExecutedPrice,
 			OrderPrecision:    strconv.Itoa(int(info.OrderPrecision)),
+			Params:            info.Params,
+			Status:            types.MarketStatusName(mk.GetMarketStatus(ctx, info.GetSymbol()).Status),
 		}
 	}
 	bz, err := codec.MarshalJSONIndent(mk.cdc, mInfoList)
@@ -142,12 +168,24 @@ type ResOrder struct {
 	ExistBlocks      int64          `json:"exist_blocks"`
 	FrozenFeatureFee int64          `json:"frozen_feature_fee"`   // DEX2
 	FrozenFee        int64          `json:"frozen_fee,omitempty"` // DEX2: -> frozen_commission
+	TriggerPrice     sdk.Dec        `json:"trigger_price,omitempty"`
+	TriggerHeight    int64          `json:"trigger_height,omitempty"`
+	ModifyHeight     int64          `json:"modify_height,omitempty"`
+	ExpireTime       int64          `json:"expire_time,omitempty"`
+
+	SelfTradePrevention byte `json:"self_trade_prevention,omitempty"`
+
+	DisplayQuantity int64 `json:"display_quantity,omitempty"`
+	VisibleStock    int64 `json:"visible_stock,omitempty"`
+	RefreshHeight   int64 `json:"refresh_height,omitempty"`
 
 	// These fields will change when order was filled/canceled.
 	LeftStock int64 `json:"left_stock"`
 	Freeze    int64 `json:"freeze"`
 	DealStock int64 `json:"deal_stock"`
 	DealMoney int64 `json:"deal_money"`
+	// the part of DealStock which is dealt as maker
+	MakerDealStock int64 `json:"maker_deal_stock,omitempty"`
 }
 
 func convertResOrderFromOrder(order *types.Order) *ResOrder {
@@ -167,26 +205,211 @@ func convertResOrderFromOrder(order *types.Order) *ResOrder {
 		ExistBlocks:      order.ExistBlocks,
 		FrozenFeatureFee: order.FrozenFeatureFee,
 		FrozenFee:        order.FrozenFee,
+		TriggerPrice:     order.TriggerPrice,
+		TriggerHeight:    order.TriggerHeight,
+		ModifyHeight:     order.ModifyHeight,
+		ExpireTime:       order.ExpireTime,
 		LeftStock:        order.LeftStock,
 		Freeze:           order.Freeze,
 		DealStock:        order.DealStock,
 		DealMoney:        order.DealMoney,
+		MakerDealStock:   order.MakerDealStock,
+
+		SelfTradePrevention: order.SelfTradePrevention,
+		DisplayQuantity:     order.DisplayQuantity,
+		VisibleStock:        order.VisibleStock,
+		RefreshHeight:       order.RefreshHeight,
+	}
+}
+
+// ResOrderPage is a page of the orders, NextCursor is empty on the last page
+type ResOrderPage struct {
+	Orders     []*ResOrder `json:"orders"`
+	NextCursor string      `json:"next_cursor"`
+}
+
+func marshalOrderPage(cdc *codec.Codec, orders []*types.Order, nextCursor string) ([]byte, sdk.Error) {
+	page := ResOrderPage{
+		Orders:     make([]*ResOrder, len(orders)),
+		NextCursor: nextCursor,
+	}
+	for i, order := range orders {
+		page.Orders[i] = convertResOrderFromOrder(order)
+	}
+	bz, err := codec.MarshalJSONIndent(cdc, page)
+	if err != nil {
+		return nil, types.ErrFailedMarshal()
+	}
+	return bz, nil
+}
+
+type QueryOrdersInMarketParam struct {
+	TradingPair string
+	Filter      OrderFilter
+	Page        PageRequest
+}
+
+func NewQueryOrdersInMarketParam(symbol string, filter OrderFilter, page PageRequest) QueryOrdersInMarketParam {
+	return QueryOrdersInMarketParam{
+		TradingPair: symbol,
+		Filter:      filter,
+		Page:        page,
 	}
 }
 
+// queryOrdersInMarket returns the orders in a market from the newest to the oldest, page by page.
+// All the orders are returned as a list like before if neither filter nor page is given.
 func queryOrdersInMarket(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.Error) {
-	var param QueryMarketParam
+	var param QueryOrdersInMarketParam
 	if err := mk.cdc.UnmarshalJSON(req.Data, &param); err != nil {
 		return nil, sdk.ErrInternal(fmt.Sprintf("failed to parse param: %s", err))
 	}
+	if err := param.Filter.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	if err := param.Page.ValidateBasic(); err != nil {
+		return nil, err
+	}
 
 	k := NewOrderKeeper(mk.marketKey, param.TradingPair, mk.cdc)
-	orders := k.GetOlderThan(ctx, math.MaxInt64)
-	rs := make([]*ResOrder, len(orders))
-	for i, or := range orders {
-		rs[i] = convertResOrderFromOrder(or)
+	if param.Filter == (OrderFilter{}) && param.Page == (PageRequest{}) {
+		orders := k.GetOlderThan(ctx, math.MaxInt64)
+		rs := make([]*ResOrder, len(orders))
+		for i, order := range orders {
+			rs[i] = convertResOrderFromOrder(order.PublicView())
+		}
+		bz, err := codec.MarshalJSONIndent(mk.cdc, rs)
+		if err != nil {
+			return nil, types.ErrFailedMarshal()
+		}
+		return bz, nil
+	}
+	orders, nextCursor := k.GetOrdersInPage(ctx, param.Filter, param.Page)
+	// the hidden parts of the iceberg orders are not shown in the order book
+	for i, order := range orders {
+		orders[i] = order.PublicView()
+	}
+	return marshalOrderPage(mk.cdc, orders, nextCursor)
+}
+
+const (
+	DefaultDepthLevels = 20
+	MaxDepthLevels     = 200
+)
+
+type QueryDepthParam struct {
+	TradingPair string
+	Levels      int
+	Merge       byte
+}
+
+func NewQueryDepthParam(symbol string, levels int, merge byte) QueryDepthParam {
+	return QueryDepthParam{
+		TradingPair: symbol,
+		Levels:      levels,
+		Merge:       merge,
+	}
+}
+
+type ResDepth struct {
+	TradingPair    string        `json:"trading_pair"`
+	PricePrecision byte          `json:"price_precision"`
+	Bids           []*DepthLevel `json:"bids"`
+	Asks           []*DepthLevel `json:"asks"`
+}
+
+// queryDepth aggregates the order book into price levels. The merge precision can not be
+// larger than the price precision of the market.
+func queryDepth(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.Error) {
+	var param QueryDepthParam
+	if err := mk.cdc.UnmarshalJSON(req.Data, &param); err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("failed to parse param: %s", err))
+	}
+	info, err := mk.GetMarketInfo(ctx, param.TradingPair)
+	if err != nil {
+		return nil, types.ErrInvalidMarket("Maybe the market have been deleted or not exist")
+	}
+	levels := param.Levels
+	if levels <= 0 {
+		levels = DefaultDepthLevels
+	} else if levels > MaxDepthLevels {
+		levels = MaxDepthLevels
+	}
+	precision := param.Merge
+	if precision > info.PricePrecision {
+		precision = info.PricePrecision
+	}
+
+	k := NewOrderKeeper(mk.marketKey, param.TradingPair, mk.cdc)
+	depth := ResDepth{
+		TradingPair:    param.TradingPair,
+		PricePrecision: precision,
+		Bids:           k.GetDepth(ctx, types.BUY, levels, precision),
+		Asks:           k.GetDepth(ctx, types.SELL, levels, precision),
+	}
+	bz, err := codec.MarshalJSONIndent(mk.cdc, depth)
+	if err != nil {
+		return nil, types.ErrFailedMarshal()
+	}
+	return bz, nil
+}
+
+const (
+	DefaultCandleCount = 100
+	MaxCandleCount     = 1000
+)
+
+type QueryCandlesParam struct {
+	TradingPair string
+	Span        byte
+	Count       int
+}
+
+func NewQueryCandlesParam(symbol string, span byte, count int) QueryCandlesParam {
+	return QueryCandlesParam{
+		TradingPair: symbol,
+		Span:        span,
+		Count:       count,
+	}
+}
+
+func queryCandles(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.Error) {
+	var param QueryCandlesParam
+	if err := mk.cdc.UnmarshalJSON(req.Data, &param); err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("failed to parse param: %s", err))
+	}
+	if !types.IsValidCandleSpan(param.Span) {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("invalid candle span : %d", param.Span))
+	}
+	count := param.Count
+	if count <= 0 {
+		count = DefaultCandleCount
+	} else if count > MaxCandleCount {
+		count = MaxCandleCount
+	}
+
+	candles := NewCandleKeeper(mk.marketKey, mk.cdc).GetCandles(ctx, param.TradingPair, param.Span, count)
+	if candles == nil {
+		candles = []*types.Candle{}
+	}
+	bz, err := codec.MarshalJSONIndent(mk.cdc, candles)
+	if err != nil {
+		return nil, types.ErrFailedMarshal()
+	}
+	return bz, nil
+}
+
+func queryTicker(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.Error) {
+	var param QueryMarketParam
+	if err := mk.cdc.UnmarshalJSON(req.Data, &param); err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("failed to parse param: %s", err))
+	}
+	if _, err := mk.GetMarketInfo(ctx, param.TradingPair); err != nil {
+		return nil, types.ErrInvalidMarket("Maybe the market have been deleted or not exist")
 	}
-	bz, err := codec.MarshalJSONIndent(mk.cdc, rs)
+
+	ticker := NewCandleKeeper(mk.marketKey, mk.cdc).GetTicker(ctx, param.TradingPair)
+	bz, err := codec.MarshalJSONIndent(mk.cdc, ticker)
 	if err != nil {
 		return nil, types.ErrFailedMarshal()
 	}
@@ -223,28 +446,109 @@ func queryOrder(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.
 }
 
 type QueryUserOrderList struct {
-	User string
+	User   string
+	Filter OrderFilter
+	Page   PageRequest
 }
 
+// queryUserOrderList returns the orders of a user in the sequence of their IDs, page by page.
+// Only the IDs of all the orders are returned like before if neither filter nor page is given.
 func queryUserOrderList(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.Error) {
 	var param QueryUserOrderList
 	if err := mk.cdc.UnmarshalJSON(req.Data, &param); err != nil {
 		return nil, types.ErrFailedParseParam()
 	}
+	if err := param.Filter.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	if err := param.Page.ValidateBasic(); err != nil {
+		return nil, err
+	}
 
 	okp := NewGlobalOrderKeeper(mk.marketKey, mk.cdc)
-	orders := okp.GetOrdersFromUser(ctx, param.User)
-	if len(orders) == 0 {
-		orders = append(orders, "")
+	if param.Filter == (OrderFilter{}) && param.Page == (PageRequest{}) {
+		orderIDs := okp.GetOrdersFromUser(ctx, param.User)
+		if len(orderIDs) == 0 {
+			orderIDs = append(orderIDs, "")
+		}
+		bz, err := codec.MarshalJSONIndent(mk.cdc, orderIDs)
+		if err != nil {
+			return nil, types.ErrFailedMarshal()
+		}
+		return bz, nil
 	}
-	bz, err := codec.MarshalJSONIndent(mk.cdc, orders)
+	orders, nextCursor := okp.GetOrdersFromUserInPage(ctx, param.User, param.Filter, param.Page)
+	return marshalOrderPage(mk.cdc, orders, nextCursor)
+}
+
+// ResTradePage is a page of the trades from the newest to the oldest, NextCursor is empty on the last page
+type ResTradePage struct {
+	Trades     []*types.Trade `json:"trades"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+func marshalTradePage(cdc *codec.Codec, trades []*types.Trade, nextCursor string) ([]byte, sdk.Error) {
+	if trades == nil {
+		trades = []*types.Trade{}
+	}
+	bz, err := codec.MarshalJSONIndent(cdc, ResTradePage{Trades: trades, NextCursor: nextCursor})
 	if err != nil {
 		return nil, types.ErrFailedMarshal()
 	}
-
 	return bz, nil
 }
 
+type QueryTradesParam struct {
+	TradingPair string
+	Page        PageRequest
+}
+
+type QueryUserTradesParam struct {
+	User string
+	Page PageRequest
+}
+
+type QueryOrderTradesParam struct {
+	OrderID string
+	Page    PageRequest
+}
+
+func queryTrades(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.Error) {
+	var param QueryTradesParam
+	if err := mk.cdc.UnmarshalJSON(req.Data, &param); err != nil {
+		return nil, types.ErrFailedParseParam()
+	}
+	if err := param.Page.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	trades, nextCursor := NewTradeKeeper(mk.marketKey, mk.cdc).GetTrades(ctx, param.TradingPair, param.Page)
+	return marshalTradePage(mk.cdc, trades, nextCursor)
+}
+
+func queryUserTrades(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.Error) {
+	var param QueryUserTradesParam
+	if err := mk.cdc.UnmarshalJSON(req.Data, &param); err != nil {
+		return nil, types.ErrFailedParseParam()
+	}
+	if err := param.Page.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	trades, nextCursor := NewTradeKeeper(mk.marketKey, mk.cdc).GetAccountTrades(ctx, param.User, param.Page)
+	return marshalTradePage(mk.cdc, trades, nextCursor)
+}
+
+func queryOrderTrades(ctx sdk.Context, req abci.RequestQuery, mk Keeper) ([]byte, sdk.Error) {
+	var param QueryOrderTradesParam
+	if err := mk.cdc.UnmarshalJSON(req.Data, &param); err != nil {
+		return nil, types.ErrFailedParseParam()
+	}
+	if err := param.Page.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	trades, nextCursor := NewTradeKeeper(mk.marketKey, mk.cdc).GetOrderTrades(ctx, param.OrderID, param.Page)
+	return marshalTradePage(mk.cdc, trades, nextCursor)
+}
+
 type QueryCancelMarkets struct {
 	Time int64
 }
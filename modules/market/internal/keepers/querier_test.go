@@ -119,7 +119,7 @@ func TestQueryOrdersInMarket(t *testing.T) {
 	order3 := createOrder(ctx, testApp, addr, 12346, 1)
 
 	// query params
-	reqParams := keepers.QueryMarketParam{
+	reqParams := keepers.QueryOrdersInMarketParam{
 		TradingPair: "eth/cet",
 	}
 	reqBytes := testApp.Cdc.MustMarshalJSON(reqParams)
@@ -137,6 +137,97 @@ func TestQueryOrdersInMarket(t *testing.T) {
 	require.Equal(t, order1, res[2])
 	require.Equal(t, order2, res[1])
 	require.Equal(t, order3, res[0])
+
+	// a page is returned if a page is given
+	reqBytes = testApp.Cdc.MustMarshalJSON(keepers.NewQueryOrdersInMarketParam("eth/cet",
+		keepers.OrderFilter{}, keepers.PageRequest{Limit: 10}))
+	resBytes, err = querier(ctx, []string{keepers.QueryOrdersInMarket}, abci.RequestQuery{Data: reqBytes})
+	require.NoError(t, err)
+	var page keepers.ResOrderPage
+	testApp.Cdc.MustUnmarshalJSON(resBytes, &page)
+	require.Equal(t, 3, len(page.Orders))
+	require.Equal(t, order3.OrderID(), page.Orders[0].OrderID)
+	require.Equal(t, "", page.NextCursor)
+}
+
+func TestQueryOrdersInPage(t *testing.T) {
+	testApp := testapp.NewTestApp()
+	ctx := testApp.NewCtx()
+	testApp.MarketKeeper.SetParams(ctx, types.DefaultParams())
+	_, _, addr := testutil.KeyPubAddr()
+	var orders []types.Order
+	for i := 0; i < 6; i++ {
+		order := types.Order{
+			TradingPair: "eth/cet",
+			Sender:      addr,
+			Sequence:    uint64(100 + i),
+			Price:       sdk.NewDec(1),
+			Side:        byte(types.BUY + i%2),
+			TimeInForce: types.GTE,
+			Height:      int64(10 + i),
+		}
+		testApp.MarketKeeper.SetOrder(ctx, &order)
+		orders = append(orders, order)
+	}
+	other := types.Order{TradingPair: "btc/cet", Sender: addr, Sequence: 200, Price: sdk.NewDec(1), Side: types.BUY, Height: 12}
+	testApp.MarketKeeper.SetOrder(ctx, &other)
+	querier := keepers.NewQuerier(testApp.MarketKeeper)
+
+	query := func(path string, param interface{}) keepers.ResOrderPage {
+		resBytes, err := querier(ctx, []string{path}, abci.RequestQuery{Data: testApp.Cdc.MustMarshalJSON(param)})
+		require.NoError(t, err)
+		var res keepers.ResOrderPage
+		testApp.Cdc.MustUnmarshalJSON(resBytes, &res)
+		return res
+	}
+	orderIDs := func(res keepers.ResOrderPage) []string {
+		ids := make([]string, len(res.Orders))
+		for i, order := range res.Orders {
+			ids[i] = order.OrderID
+		}
+		return ids
+	}
+
+	// the orders in a market are returned from the newest, and the cursor continues the previous page
+	param := keepers.NewQueryOrdersInMarketParam("eth/cet", keepers.OrderFilter{}, keepers.PageRequest{Limit: 4})
+	res := query(keepers.QueryOrdersInMarket, param)
+	require.Equal(t, []string{orders[5].OrderID(), orders[4].OrderID(), orders[3].OrderID(), orders[2].OrderID()}, orderIDs(res))
+	require.NotEqual(t, "", res.NextCursor)
+	param.Page.Cursor = res.NextCursor
+	res = query(keepers.QueryOrdersInMarket, param)
+	require.Equal(t, []string{orders[1].OrderID(), orders[0].OrderID()}, orderIDs(res))
+	require.Equal(t, "", res.NextCursor)
+
+	// filter by side and height range, then skip one with offset
+	param = keepers.NewQueryOrdersInMarketParam("eth/cet",
+		keepers.OrderFilter{Side: types.SELL, MinHeight: 11, MaxHeight: 14}, keepers.PageRequest{Offset: 1})
+	res = query(keepers.QueryOrdersInMarket, param)
+	require.Equal(t, []string{orders[1].OrderID()}, orderIDs(res))
+
+	// the orders of a user are returned in the sequence of their IDs
+	userParam := keepers.QueryUserOrderList{User: addr.String(), Page: keepers.PageRequest{Limit: 5}}
+	res = query(keepers.QueryUserOrders, userParam)
+	require.Equal(t, 5, len(res.Orders))
+	userParam.Page.Cursor = res.NextCursor
+	res = query(keepers.QueryUserOrders, userParam)
+	require.Equal(t, []string{orders[5].OrderID(), other.OrderID()}, orderIDs(res))
+	require.Equal(t, "", res.NextCursor)
+
+	userParam = keepers.QueryUserOrderList{User: addr.String(),
+		Filter: keepers.OrderFilter{TradingPair: "btc/cet"}}
+	res = query(keepers.QueryUserOrders, userParam)
+	require.Equal(t, []string{other.OrderID()}, orderIDs(res))
+	userParam.Filter = keepers.OrderFilter{TradingPair: "eth/cet", TimeInForce: types.GTE, MinHeight: 15}
+	res = query(keepers.QueryUserOrders, userParam)
+	require.Equal(t, []string{orders[5].OrderID()}, orderIDs(res))
+
+	// invalid params
+	_, err := querier(ctx, []string{keepers.QueryUserOrders}, abci.RequestQuery{Data: testApp.Cdc.MustMarshalJSON(
+		keepers.QueryUserOrderList{User: addr.String(), Filter: keepers.OrderFilter{Side: 3}})})
+	require.Equal(t, types.CodeInvalidTradeSide, err.Code())
+	_, err = querier(ctx, []string{keepers.QueryOrdersInMarket}, abci.RequestQuery{Data: testApp.Cdc.MustMarshalJSON(
+		keepers.NewQueryOrdersInMarketParam("eth/cet", keepers.OrderFilter{}, keepers.PageRequest{Cursor: "xyz"}))})
+	require.Equal(t, sdk.CodeUnknownRequest, err.Code())
 }
 
 func TestQueryOrderList(t *testing.T) {
@@ -168,6 +259,14 @@ func TestQueryOrderList(t *testing.T) {
 	require.Equal(t, order1.OrderID(), res[0])
 	require.Equal(t, order2.OrderID(), res[1])
 	require.Equal(t, order3.OrderID(), res[2])
+
+	// the list of a user without orders has an empty ID
+	_, _, other := testutil.KeyPubAddr()
+	reqBytes = testApp.Cdc.MustMarshalJSON(keepers.QueryUserOrderList{User: other.String()})
+	resBytes, err = querier(ctx, []string{keepers.QueryUserOrders}, abci.RequestQuery{Data: reqBytes})
+	require.NoError(t, err)
+	testApp.Cdc.MustUnmarshalJSON(resBytes, &res)
+	require.Equal(t, []string{""}, res)
 }
 
 func TestQueryWaitCancelMarkets(t *testing.T) {
@@ -196,3 +295,80 @@ func TestQueryWaitCancelMarkets(t *testing.T) {
 	require.Equal(t, 1, len(res))
 	require.Equal(t, "foo/bar", res[0])
 }
+
+func TestQueryDepth(t *testing.T) {
+	// setup
+	testApp := testapp.NewTestApp()
+	ctx := testApp.NewCtx()
+	testApp.MarketKeeper.SetParams(ctx, types.DefaultParams())
+	createMarket(ctx, testApp, "foo", "bar", 1, sdk.NewDec(1))
+	_, _, addr := testutil.KeyPubAddr()
+	ork := keepers.NewOrderKeeper(testApp.MarketKeeper.GetMarketKey(), "foo/bar", testApp.Cdc)
+	for i, price := range []int64{125, 121, 133} {
+		side := byte(types.BUY)
+		if i == 2 {
+			side = types.SELL
+		}
+		require.Nil(t, ork.Add(ctx, &types.Order{
+			TradingPair: "foo/bar",
+			Sender:      addr,
+			Sequence:    uint64(i),
+			Price:       sdk.NewDecWithPrec(price, 2),
+			Side:        side,
+			LeftStock:   100,
+		}))
+	}
+
+	// the merge precision is limited by the price precision of the market
+	reqBytes := testApp.Cdc.MustMarshalJSON(keepers.NewQueryDepthParam("foo/bar", 10, 8))
+	querier := keepers.NewQuerier(testApp.MarketKeeper)
+	resBytes, err := querier(ctx, []string{keepers.QueryDepth}, abci.RequestQuery{Data: reqBytes})
+	require.NoError(t, err)
+
+	var res keepers.ResDepth
+	testApp.Cdc.MustUnmarshalJSON(resBytes, &res)
+	require.EqualValues(t, 1, res.PricePrecision)
+	require.Equal(t, 1, len(res.Bids))
+	require.Equal(t, sdk.NewDecWithPrec(12, 1), res.Bids[0].Price)
+	require.Equal(t, sdk.NewInt(200), res.Bids[0].Amount)
+	require.Equal(t, 1, len(res.Asks))
+	require.Equal(t, sdk.NewDecWithPrec(14, 1), res.Asks[0].Price)
+
+	reqBytes = testApp.Cdc.MustMarshalJSON(keepers.NewQueryDepthParam("foo/cet", 10, 8))
+	_, err = querier(ctx, []string{keepers.QueryDepth}, abci.RequestQuery{Data: reqBytes})
+	require.Equal(t, types.CodeInvalidMarket, err.Code())
+
+	// only the visible slice of an iceberg order is counted
+	require.Nil(t, ork.Add(ctx, &types.Order{
+		TradingPair:     "foo/bar",
+		Sender:          addr,
+		Sequence:        3,
+		Price:           sdk.NewDecWithPrec(133, 2),
+		Side:            types.SELL,
+		Quantity:        500,
+		LeftStock:       500,
+		Freeze:          500,
+		DisplayQuantity: 50,
+		VisibleStock:    50,
+	}))
+	reqBytes = testApp.Cdc.MustMarshalJSON(keepers.NewQueryDepthParam("foo/bar", 10, 1))
+	resBytes, err = querier(ctx, []string{keepers.QueryDepth}, abci.RequestQuery{Data: reqBytes})
+	require.NoError(t, err)
+	testApp.Cdc.MustUnmarshalJSON(resBytes, &res)
+	require.Equal(t, sdk.NewInt(150), res.Asks[0].Amount)
+	require.Equal(t, 2, res.Asks[0].OrderCount)
+
+	reqOrders := testApp.Cdc.MustMarshalJSON(keepers.QueryOrdersInMarketParam{TradingPair: "foo/bar"})
+	resBytes, err = querier(ctx, []string{keepers.QueryOrdersInMarket}, abci.RequestQuery{Data: reqOrders})
+	require.NoError(t, err)
+	var orders []*keepers.ResOrder
+	testApp.Cdc.MustUnmarshalJSON(resBytes, &orders)
+	for _, order := range orders {
+		require.EqualValues(t, 0, order.DisplayQuantity)
+		if order.Sequence == 3 {
+			require.EqualValues(t, 50, order.Quantity)
+			require.EqualValues(t, 50, order.LeftStock)
+			require.EqualValues(t, 50, order.Freeze)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package keepers
+
+import (
+	"encoding/hex"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	dex "github.com/coinexchain/cet-sdk/types"
+)
+
+// This keeper records the recent trades of each market, which can also be looked up by
+// the accounts and the orders taking part in them. Only the trades in the last
+// TradeRetention blocks are kept, and the older ones are pruned in EndBlocker.
+type TradeKeeper struct {
+	marketKey sdk.StoreKey
+	codec     *codec.Codec
+}
+
+func NewTradeKeeper(key sdk.StoreKey, codec *codec.Codec) *TradeKeeper {
+	return &TradeKeeper{
+		marketKey: key,
+		codec:     codec,
+	}
+}
+
+func tradeKey(symbol string, height, sequence int64) []byte {
+	return dex.ConcatKeys(
+		TradeKeyPrefix,
+		[]byte(symbol),
+		[]byte{0x0},
+		int64ToBigEndianBytes(height),
+		int64ToBigEndianBytes(sequence),
+	)
+}
+
+// the index entries are sorted by height, and their values are the keys of the trades
+func tradeIndexKey(prefix []byte, owner string, height int64, key []byte) []byte {
+	return dex.ConcatKeys(
+		prefix,
+		[]byte(owner),
+		[]byte{0x0},
+		int64ToBigEndianBytes(height),
+		key,
+	)
+}
+
+// the height index is used to prune the trades in all the markets
+func tradeHeightKey(height int64, key []byte) []byte {
+	return dex.ConcatKeys(
+		TradeHeightKeyPrefix,
+		int64ToBigEndianBytes(height),
+		key,
+	)
+}
+
+func tradeIndexKeys(trade *types.Trade, key []byte) [][]byte {
+	return [][]byte{
+		tradeIndexKey(AccountTradeKeyPrefix, trade.Buyer.String(), trade.Height, key),
+		tradeIndexKey(AccountTradeKeyPrefix, trade.Seller.String(), trade.Height, key),
+		tradeIndexKey(OrderTradeKeyPrefix, trade.BuyOrderID, trade.Height, key),
+		tradeIndexKey(OrderTradeKeyPrefix, trade.SellOrderID, trade.Height, key),
+		tradeHeightKey(trade.Height, key),
+	}
+}
+
+// Record the trades of a market in the current block
+func (keeper *TradeKeeper) AddTrades(ctx sdk.Context, symbol string, trades []*types.Trade) {
+	store := ctx.KVStore(keeper.marketKey)
+	for _, trade := range trades {
+		key := tradeKey(symbol, trade.Height, trade.Sequence)
+		store.Set(key, keeper.codec.MustMarshalBinaryBare(trade))
+		for _, indexKey := range tradeIndexKeys(trade, key) {
+			store.Set(indexKey, key)
+		}
+	}
+}
+
+// Prune the trades in all the markets which are older than 'retention' blocks, and all the trades
+// are removed when retention is zero. It is called in every block.
+func (keeper *TradeKeeper) PruneTrades(ctx sdk.Context, retention int64) {
+	end := ctx.BlockHeight() - retention + 1
+	if retention <= 0 {
+		end = ctx.BlockHeight() + 1
+	}
+	store := ctx.KVStore(keeper.marketKey)
+	start, stop := tradeHeightKey(0, nil), tradeHeightKey(end, nil)
+	var keys [][]byte
+	iter := store.Iterator(start, stop)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		trade := keeper.getTrade(ctx, iter.Value())
+		if trade == nil {
+			keys = append(keys, iter.Key())
+			continue
+		}
+		keys = append(append(keys, iter.Value()), tradeIndexKeys(trade, iter.Value())...)
+	}
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// Remove all the trades of a market, together with their index entries
+func (keeper *TradeKeeper) RemoveAllTrades(ctx sdk.Context, symbol string) {
+	store := ctx.KVStore(keeper.marketKey)
+	start := dex.ConcatKeys(TradeKeyPrefix, []byte(symbol), []byte{0x0})
+	stop := dex.ConcatKeys(TradeKeyPrefix, []byte(symbol), []byte{0x1})
+	var keys [][]byte
+	iter := store.Iterator(start, stop)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var trade types.Trade
+		keeper.codec.MustUnmarshalBinaryBare(iter.Value(), &trade)
+		keys = append(append(keys, iter.Key()), tradeIndexKeys(&trade, iter.Key())...)
+	}
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+func (keeper *TradeKeeper) getTrade(ctx sdk.Context, key []byte) *types.Trade {
+	bz := ctx.KVStore(keeper.marketKey).Get(key)
+	if bz == nil {
+		return nil
+	}
+	var trade types.Trade
+	keeper.codec.MustUnmarshalBinaryBare(bz, &trade)
+	return &trade
+}
+
+// collect a page of trades from the newest one in [start, end), getTrade maps the values to the trades
+func (keeper *TradeKeeper) collectTrades(ctx sdk.Context, start, end []byte, page PageRequest,
+	getTrade func(value []byte) *types.Trade) (trades []*types.Trade, nextCursor string) {
+
+	if cursor, _ := hex.DecodeString(page.Cursor); len(cursor) != 0 && string(cursor) < string(end) {
+		end = cursor
+	}
+	if string(start) >= string(end) {
+		return nil, ""
+	}
+	iter := ctx.KVStore(keeper.marketKey).ReverseIterator(start, end)
+	defer iter.Close()
+	skipped, limit := 0, page.limit()
+	for ; iter.Valid() && len(trades) < limit; iter.Next() {
+		trade := getTrade(iter.Value())
+		if trade == nil {
+			continue
+		}
+		if skipped < page.Offset {
+			skipped++
+			continue
+		}
+		trades = append(trades, trade)
+		if len(trades) == limit {
+			nextCursor = hex.EncodeToString(iter.Key())
+		}
+	}
+	return
+}
+
+// GetTrades returns the trades of a market from the newest to the oldest
+func (keeper *TradeKeeper) GetTrades(ctx sdk.Context, symbol string, page PageRequest) ([]*types.Trade, string) {
+	start := dex.ConcatKeys(TradeKeyPrefix, []byte(symbol), []byte{0x0})
+	end := dex.ConcatKeys(TradeKeyPrefix, []byte(symbol), []byte{0x1})
+	return keeper.collectTrades(ctx, start, end, page, func(value []byte) *types.Trade {
+		var trade types.Trade
+		keeper.codec.MustUnmarshalBinaryBare(value, &trade)
+		return &trade
+	})
+}
+
+// GetAccountTrades returns the trades of an account in all the markets from the newest to the oldest
+func (keeper *TradeKeeper) GetAccountTrades(ctx sdk.Context, addr string, page PageRequest) ([]*types.Trade, string) {
+	return keeper.getIndexedTrades(ctx, AccountTradeKeyPrefix, addr, page)
+}
+
+// GetOrderTrades returns the trades of an order from the newest to the oldest
+func (keeper *TradeKeeper) GetOrderTrades(ctx sdk.Context, orderID string, page PageRequest) ([]*types.Trade, string) {
+	return keeper.getIndexedTrades(ctx, OrderTradeKeyPrefix, orderID, page)
+}
+
+func (keeper *TradeKeeper) getIndexedTrades(ctx sdk.Context, prefix []byte, owner string, page PageRequest) ([]*types.Trade, string) {
+	start := dex.ConcatKeys(prefix, []byte(owner), []byte{0x0})
+	end := dex.ConcatKeys(prefix, []byte(owner), []byte{0x1})
+	return keeper.collectTrades(ctx, start, end, page, func(value []byte) *types.Trade {
+		return keeper.getTrade(ctx, value)
+	})
+}
@@ -0,0 +1,106 @@
+package keepers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/keepers"
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	"github.com/coinexchain/cet-sdk/testapp"
+	"github.com/coinexchain/cet-sdk/testutil"
+)
+
+func TestTrades(t *testing.T) {
+	app := testapp.NewTestApp()
+	ctx := app.NewCtx()
+	keeper := keepers.NewTradeKeeper(app.MarketKeeper.GetMarketKey(), app.Cdc)
+	_, _, buyer := testutil.KeyPubAddr()
+	_, _, seller := testutil.KeyPubAddr()
+	buyOrder := &types.Order{TradingPair: "abc/cet", Sender: buyer, Sequence: 1, Side: types.BUY}
+	sellOrder := &types.Order{TradingPair: "abc/cet", Sender: seller, Sequence: 2, Side: types.SELL}
+	otherBuyOrder := &types.Order{TradingPair: "xyz/cet", Sender: buyer, Sequence: 3, Side: types.BUY}
+	otherOrder := &types.Order{TradingPair: "xyz/cet", Sender: seller, Sequence: 4, Side: types.SELL}
+
+	// two trades in every block, and only the trades in the last 3 blocks are kept
+	for i := int64(0); i < 5; i++ {
+		ctx = ctx.WithBlockHeight(100 + i)
+		keeper.PruneTrades(ctx, 3)
+		keeper.AddTrades(ctx, "abc/cet", []*types.Trade{
+			types.NewTrade(ctx, 0, buyOrder, sellOrder, sdk.NewDec(10+i), 10, 100),
+			types.NewTrade(ctx, 1, buyOrder, sellOrder, sdk.NewDec(10+i), 20, 200),
+		})
+	}
+	keeper.AddTrades(ctx, "xyz/cet", []*types.Trade{
+		types.NewTrade(ctx, 0, otherBuyOrder, otherOrder, sdk.NewDec(1), 5, 5),
+	})
+
+	trades, cursor := keeper.GetTrades(ctx, "abc/cet", keepers.PageRequest{})
+	require.Equal(t, 6, len(trades))
+	require.Equal(t, "", cursor)
+	require.EqualValues(t, 104, trades[0].Height)
+	require.EqualValues(t, 1, trades[0].Sequence)
+	require.EqualValues(t, 102, trades[5].Height)
+	require.EqualValues(t, 0, trades[5].Sequence)
+
+	// page through the trades of the buyer, which take part in both markets
+	trades, cursor = keeper.GetAccountTrades(ctx, buyer.String(), keepers.PageRequest{Limit: 4})
+	require.Equal(t, 4, len(trades))
+	require.NotEqual(t, "", cursor)
+	require.EqualValues(t, 104, trades[0].Height)
+	trades, cursor = keeper.GetAccountTrades(ctx, buyer.String(), keepers.PageRequest{Limit: 4, Cursor: cursor})
+	require.Equal(t, 3, len(trades))
+	require.Equal(t, "", cursor)
+	require.EqualValues(t, 102, trades[2].Height)
+	trades, _ = keeper.GetAccountTrades(ctx, seller.String(), keepers.PageRequest{Offset: 6})
+	require.Equal(t, 1, len(trades))
+
+	trades, _ = keeper.GetOrderTrades(ctx, sellOrder.OrderID(), keepers.PageRequest{})
+	require.Equal(t, 6, len(trades))
+	trades, _ = keeper.GetOrderTrades(ctx, otherOrder.OrderID(), keepers.PageRequest{})
+	require.Equal(t, 1, len(trades))
+	require.Equal(t, "xyz/cet", trades[0].TradingPair)
+
+	// the index entries are removed with the trades
+	keeper.RemoveAllTrades(ctx, "abc/cet")
+	trades, _ = keeper.GetTrades(ctx, "abc/cet", keepers.PageRequest{})
+	require.Equal(t, 0, len(trades))
+	trades, _ = keeper.GetAccountTrades(ctx, buyer.String(), keepers.PageRequest{})
+	require.Equal(t, 1, len(trades))
+	trades, _ = keeper.GetOrderTrades(ctx, sellOrder.OrderID(), keepers.PageRequest{})
+	require.Equal(t, 0, len(trades))
+}
+
+func TestPruneTradesInAllMarkets(t *testing.T) {
+	app := testapp.NewTestApp()
+	ctx := app.NewCtx()
+	keeper := keepers.NewTradeKeeper(app.MarketKeeper.GetMarketKey(), app.Cdc)
+	_, _, buyer := testutil.KeyPubAddr()
+	_, _, seller := testutil.KeyPubAddr()
+	buyOrder := &types.Order{TradingPair: "abc/cet", Sender: buyer, Sequence: 1, Side: types.BUY}
+	sellOrder := &types.Order{TradingPair: "abc/cet", Sender: seller, Sequence: 2, Side: types.SELL}
+	otherBuyOrder := &types.Order{TradingPair: "xyz/cet", Sender: buyer, Sequence: 3, Side: types.BUY}
+	otherOrder := &types.Order{TradingPair: "xyz/cet", Sender: seller, Sequence: 4, Side: types.SELL}
+
+	// the trades of a market without new trades are also pruned
+	ctx = ctx.WithBlockHeight(10)
+	keeper.AddTrades(ctx, "abc/cet", []*types.Trade{types.NewTrade(ctx, 0, buyOrder, sellOrder, sdk.NewDec(1), 5, 5)})
+	ctx = ctx.WithBlockHeight(12)
+	keeper.AddTrades(ctx, "xyz/cet", []*types.Trade{types.NewTrade(ctx, 0, otherBuyOrder, otherOrder, sdk.NewDec(1), 5, 5)})
+	ctx = ctx.WithBlockHeight(13)
+	keeper.PruneTrades(ctx, 3)
+	trades, _ := keeper.GetTrades(ctx, "abc/cet", keepers.PageRequest{})
+	require.Equal(t, 0, len(trades))
+	trades, _ = keeper.GetAccountTrades(ctx, buyer.String(), keepers.PageRequest{})
+	require.Equal(t, 1, len(trades))
+	require.Equal(t, "xyz/cet", trades[0].TradingPair)
+
+	// all the trades are removed when the trade history is disabled
+	keeper.PruneTrades(ctx, 0)
+	trades, _ = keeper.GetTrades(ctx, "xyz/cet", keepers.PageRequest{})
+	require.Equal(t, 0, len(trades))
+	trades, _ = keeper.GetOrderTrades(ctx, otherOrder.OrderID(), keepers.PageRequest{})
+	require.Equal(t, 0, len(trades))
+}
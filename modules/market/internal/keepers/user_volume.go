@@ -0,0 +1,78 @@
+package keepers
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	dex "github.com/coinexchain/cet-sdk/types"
+)
+
+// The trading volume of each account is recorded by day, in CET, to decide its fee tier
+
+func userVolumeKey(addr sdk.AccAddress, day int64) []byte {
+	return dex.ConcatKeys(UserVolumeKeyPrefix, addr, int64ToBigEndianBytes(day))
+}
+
+func currentDay(ctx sdk.Context) int64 {
+	day := ctx.BlockHeader().Time.Unix() / types.SecondsPerDay
+	if day < 0 {
+		return 0
+	}
+	return day
+}
+
+// Add the volume to the current day, and remove the records which are older than VolumeDays days
+func (k Keeper) AddUserVolume(ctx sdk.Context, addr sdk.AccAddress, volume sdk.Int) {
+	if !volume.IsPositive() {
+		return
+	}
+	store := ctx.KVStore(k.marketKey)
+	day := currentDay(ctx)
+	key := userVolumeKey(addr, day)
+	sum := volume
+	if bz := store.Get(key); bz != nil {
+		var old sdk.Int
+		k.cdc.MustUnmarshalBinaryBare(bz, &old)
+		sum = sum.Add(old)
+	} else if day >= types.VolumeDays {
+		k.removeUserVolumes(ctx, addr, day-types.VolumeDays+1)
+	}
+	store.Set(key, k.cdc.MustMarshalBinaryBare(sum))
+}
+
+// remove the records which are before the 'end' day
+func (k Keeper) removeUserVolumes(ctx sdk.Context, addr sdk.AccAddress, end int64) {
+	store := ctx.KVStore(k.marketKey)
+	var keys [][]byte
+	iter := store.Iterator(userVolumeKey(addr, 0), userVolumeKey(addr, end))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	for _, key := range keys {
+		store.Delete(key)
+	}
+}
+
+// Return the trading volume in the last VolumeDays days, including today
+func (k Keeper) GetUserVolume(ctx sdk.Context, addr sdk.AccAddress) sdk.Int {
+	store := ctx.KVStore(k.marketKey)
+	begin := currentDay(ctx) - types.VolumeDays + 1
+	if begin < 0 {
+		begin = 0
+	}
+	sum := sdk.ZeroInt()
+	iter := store.Iterator(userVolumeKey(addr, begin), userVolumeKey(addr, currentDay(ctx)+1))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var volume sdk.Int
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), &volume)
+		sum = sum.Add(volume)
+	}
+	return sum
+}
+
+// Return the maker and taker commission rates of an account, according to its fee tier
+func (k Keeper) GetFeeRates(ctx sdk.Context, addr sdk.AccAddress) (makerRate, takerRate int64) {
+	return k.GetParams(ctx).GetFeeRates(k.GetUserVolume(ctx, addr))
+}
@@ -0,0 +1,56 @@
+package keepers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	"github.com/coinexchain/cet-sdk/testapp"
+)
+
+func TestUserVolume(t *testing.T) {
+	app := testapp.NewTestApp()
+	ctx := app.NewCtx()
+	keeper := app.MarketKeeper
+	addr := sdk.AccAddress("user0000000000000000")
+	other := sdk.AccAddress("user0000000000000001")
+	begin := time.Unix(1500000000-1500000000%types.SecondsPerDay, 0)
+	day := time.Duration(types.SecondsPerDay) * time.Second
+
+	// trade 100 every day, and twice on the first day
+	for i := 0; i < types.VolumeDays; i++ {
+		ctx = ctx.WithBlockTime(begin.Add(time.Duration(i) * day))
+		keeper.AddUserVolume(ctx, addr, sdk.NewInt(100))
+	}
+	ctx = ctx.WithBlockTime(begin)
+	keeper.AddUserVolume(ctx, addr, sdk.NewInt(100))
+	keeper.AddUserVolume(ctx, other, sdk.NewInt(7))
+	require.Equal(t, sdk.NewInt(200), keeper.GetUserVolume(ctx, addr))
+
+	ctx = ctx.WithBlockTime(begin.Add(time.Duration(types.VolumeDays-1) * day))
+	require.Equal(t, sdk.NewInt(100*types.VolumeDays+100), keeper.GetUserVolume(ctx, addr))
+	require.Equal(t, sdk.NewInt(7), keeper.GetUserVolume(ctx, other))
+
+	// the first day is out of the window
+	ctx = ctx.WithBlockTime(begin.Add(time.Duration(types.VolumeDays) * day))
+	require.Equal(t, sdk.NewInt(100*types.VolumeDays-100), keeper.GetUserVolume(ctx, addr))
+	keeper.AddUserVolume(ctx, addr, sdk.NewInt(50))
+	require.Equal(t, sdk.NewInt(100*types.VolumeDays-50), keeper.GetUserVolume(ctx, addr))
+	require.Equal(t, sdk.ZeroInt(), keeper.GetUserVolume(ctx, other))
+
+	// the rates are picked by the volume
+	params := types.DefaultParams()
+	params.MakerFeeRate = types.DefaultMakerFeeRate
+	params.FeeTiers = []types.FeeTier{{MinVolume: 1000, MakerFeeRate: 2, TakerFeeRate: 8}}
+	keeper.SetParams(ctx, params)
+	makerRate, takerRate := keeper.GetFeeRates(ctx, addr)
+	require.EqualValues(t, 2, makerRate)
+	require.EqualValues(t, 8, takerRate)
+	makerRate, takerRate = keeper.GetFeeRates(ctx, other)
+	require.EqualValues(t, types.DefaultMakerFeeRate, makerRate)
+	require.EqualValues(t, types.DefaultMarketFeeRate, takerRate)
+}
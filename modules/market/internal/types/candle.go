@@ -0,0 +1,133 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// The spans of candles
+const (
+	CandleSpanBlock  byte = 1
+	CandleSpanMinute byte = 2
+	CandleSpanHour   byte = 3
+	CandleSpanDay    byte = 4
+
+	SecondsPerDay = 24 * 60 * 60
+)
+
+var CandleSpans = []byte{CandleSpanBlock, CandleSpanMinute, CandleSpanHour, CandleSpanDay}
+
+var candleSpanNames = map[string]byte{
+	"block":  CandleSpanBlock,
+	"minute": CandleSpanMinute,
+	"hour":   CandleSpanHour,
+	"day":    CandleSpanDay,
+}
+
+// ParseCandleSpan returns the span with the given name, i.e. block, minute, hour or day
+func ParseCandleSpan(name string) (byte, bool) {
+	span, ok := candleSpanNames[name]
+	return span, ok
+}
+
+func IsValidCandleSpan(span byte) bool {
+	return span >= CandleSpanBlock && span <= CandleSpanDay
+}
+
+// GetSpanLength returns the length of a span, in blocks for block candles and in seconds for the others
+func GetSpanLength(span byte) int64 {
+	switch span {
+	case CandleSpanMinute:
+		return 60
+	case CandleSpanHour:
+		return 60 * 60
+	case CandleSpanDay:
+		return SecondsPerDay
+	default:
+		return 1
+	}
+}
+
+// GetSpanBegin returns where the span containing the given block begins
+func GetSpanBegin(span byte, height, unixTime int64) int64 {
+	if span == CandleSpanBlock {
+		return height
+	}
+	return unixTime - unixTime%GetSpanLength(span)
+}
+
+// Candle is the OHLCV statistics of the trades in a span. For block candles, Begin is the
+// height of the block; for the other candles, it is the unix time when the span begins.
+type Candle struct {
+	TradingPair string  `json:"trading_pair"`
+	Span        byte    `json:"span"`
+	Begin       int64   `json:"begin"`
+	Open        sdk.Dec `json:"open"`
+	High        sdk.Dec `json:"high"`
+	Low         sdk.Dec `json:"low"`
+	Close       sdk.Dec `json:"close"`
+	StockVolume sdk.Int `json:"stock_volume"`
+	MoneyVolume sdk.Int `json:"money_volume"`
+	TradeCount  int64   `json:"trade_count"`
+}
+
+func NewCandle(symbol string, span byte, begin int64) *Candle {
+	return &Candle{
+		TradingPair: symbol,
+		Span:        span,
+		Begin:       begin,
+		StockVolume: sdk.ZeroInt(),
+		MoneyVolume: sdk.ZeroInt(),
+	}
+}
+
+func (c *Candle) AddTrade(price sdk.Dec, stockAmount, moneyAmount int64) {
+	if c.TradeCount == 0 {
+		c.Open, c.High, c.Low = price, price, price
+	} else {
+		if price.GT(c.High) {
+			c.High = price
+		}
+		if price.LT(c.Low) {
+			c.Low = price
+		}
+	}
+	c.Close = price
+	c.StockVolume = c.StockVolume.AddRaw(stockAmount)
+	c.MoneyVolume = c.MoneyVolume.AddRaw(moneyAmount)
+	c.TradeCount++
+}
+
+// Merge the statistics of a later candle into this candle
+func (c *Candle) Merge(later *Candle) {
+	if later.TradeCount == 0 {
+		return
+	}
+	if c.TradeCount == 0 {
+		c.Open, c.High, c.Low = later.Open, later.High, later.Low
+	} else {
+		if later.High.GT(c.High) {
+			c.High = later.High
+		}
+		if later.Low.LT(c.Low) {
+			c.Low = later.Low
+		}
+	}
+	c.Close = later.Close
+	c.StockVolume = c.StockVolume.Add(later.StockVolume)
+	c.MoneyVolume = c.MoneyVolume.Add(later.MoneyVolume)
+	c.TradeCount += later.TradeCount
+}
+
+// Ticker is the statistics of the trades in the last 24 hours, from BeginTime to EndTime
+type Ticker struct {
+	TradingPair string  `json:"trading_pair"`
+	BeginTime   int64   `json:"begin_time"`
+	EndTime     int64   `json:"end_time"`
+	Open        sdk.Dec `json:"open"`
+	High        sdk.Dec `json:"high"`
+	Low         sdk.Dec `json:"low"`
+	Close       sdk.Dec `json:"close"`
+	StockVolume sdk.Int `json:"stock_volume"`
+	MoneyVolume sdk.Int `json:"money_volume"`
+	TradeCount  int64   `json:"trade_count"`
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCandle(t *testing.T) {
+	require.EqualValues(t, 100, GetSpanBegin(CandleSpanBlock, 100, 3725))
+	require.EqualValues(t, 3720, GetSpanBegin(CandleSpanMinute, 100, 3725))
+	require.EqualValues(t, 3600, GetSpanBegin(CandleSpanHour, 100, 3725))
+	require.EqualValues(t, 0, GetSpanBegin(CandleSpanDay, 100, 3725))
+	span, ok := ParseCandleSpan("hour")
+	require.True(t, ok)
+	require.Equal(t, CandleSpanHour, span)
+	_, ok = ParseCandleSpan("week")
+	require.False(t, ok)
+
+	c1 := NewCandle("abc/cet", CandleSpanMinute, 3720)
+	c1.AddTrade(sdk.NewDec(10), 10, 100)
+	c1.AddTrade(sdk.NewDec(8), 10, 80)
+	c2 := NewCandle("abc/cet", CandleSpanBlock, 100)
+	c2.Merge(NewCandle("abc/cet", CandleSpanBlock, 99))
+	require.EqualValues(t, 0, c2.TradeCount)
+	c2.AddTrade(sdk.NewDec(12), 5, 60)
+	c2.AddTrade(sdk.NewDec(9), 5, 45)
+
+	c1.Merge(c2)
+	require.Equal(t, sdk.NewDec(10), c1.Open)
+	require.Equal(t, sdk.NewDec(12), c1.High)
+	require.Equal(t, sdk.NewDec(8), c1.Low)
+	require.Equal(t, sdk.NewDec(9), c1.Close)
+	require.Equal(t, sdk.NewInt(30), c1.StockVolume)
+	require.Equal(t, sdk.NewInt(285), c1.MoneyVolume)
+	require.EqualValues(t, 4, c1.TradeCount)
+}
@@ -16,7 +16,13 @@ func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MarketInfo{}, "market/TradingPair", nil)
 	cdc.RegisterConcrete(MsgCreateTradingPair{}, "market/MsgCreateTradingPair", nil)
 	cdc.RegisterConcrete(MsgCreateOrder{}, "market/MsgCreateOrder", nil)
+	cdc.RegisterConcrete(MsgCreateOrders{}, "market/MsgCreateOrders", nil)
 	cdc.RegisterConcrete(MsgCancelOrder{}, "market/MsgCancelOrder", nil)
+	cdc.RegisterConcrete(MsgCancelAllOrders{}, "market/MsgCancelAllOrders", nil)
+	cdc.RegisterConcrete(MsgModifyOrder{}, "market/MsgModifyOrder", nil)
 	cdc.RegisterConcrete(MsgCancelTradingPair{}, "market/MsgCancelTradingPair", nil)
 	cdc.RegisterConcrete(MsgModifyPricePrecision{}, "market/MsgModifyPricePrecision", nil)
+	cdc.RegisterConcrete(MsgModifyMarketParams{}, "market/MsgModifyMarketParams", nil)
+	cdc.RegisterConcrete(MsgHaltMarket{}, "market/MsgHaltMarket", nil)
+	cdc.RegisterConcrete(MsgResumeMarket{}, "market/MsgResumeMarket", nil)
 }
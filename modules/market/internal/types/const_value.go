@@ -9,7 +9,10 @@ type OrderType = byte
 const (
 	MinTokenPricePrecision           = 0
 	MaxTokenPricePrecision           = 18
+	MarketOrder            OrderType = 1
 	LimitOrder             OrderType = 2
+	StopLimitOrder         OrderType = 3
+	StopMarketOrder        OrderType = 4
 	SymbolSeparator                  = dex.SymbolSeparator
 	OrderIDSeparator                 = "-"
 	ExtraFrozenMoney                 = 0 // 100
@@ -28,6 +31,8 @@ const (
 	DecByteCount = 40 // Dec's BitLen would not be larger than 255+60, so 40 bytes are enough
 	GTE          = 3
 	IOC          = 4
+	PostOnly     = 5 // a GTE order which never takes liquidity
+	FOK          = 6 // an IOC order which must be fully filled
 	LIMIT        = 2
 )
 
@@ -35,4 +40,5 @@ const (
 	IntegrationNetSubString       = "coinex-integrationtest"
 	MaxOrderAmount          int64 = 1e18
 	MaxOrderPrecision       byte  = 8
+	MaxOrdersInBatch              = 200
 )
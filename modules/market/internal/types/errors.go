@@ -37,6 +37,17 @@ const (
 	CodeOrderAlreadyExist      sdk.CodeType = 630
 	CodeDelistRequestExist     sdk.CodeType = 632
 	CodeInvalidMarket          sdk.CodeType = 633
+	CodeInvalidTriggerPrice    sdk.CodeType = 634
+	CodeNoReferencePrice       sdk.CodeType = 635
+	CodeInvalidModification    sdk.CodeType = 636
+	CodeInvalidBatch           sdk.CodeType = 637
+	CodeInvalidMatching        sdk.CodeType = 638
+	CodeInvalidMarketParams    sdk.CodeType = 639
+	CodeInvalidMarketStatus    sdk.CodeType = 640
+	CodeMarketCancelOnly       sdk.CodeType = 641
+	CodeInvalidExpireTime      sdk.CodeType = 642
+	CodeInvalidSelfTrade       sdk.CodeType = 643
+	CodeInvalidDisplayQuantity sdk.CodeType = 644
 )
 
 func ErrFailedParseParam() sdk.Error {
@@ -52,7 +63,7 @@ func ErrInvalidExistBlocks(eb int64) sdk.Error {
 }
 
 func ErrInvalidTimeInForce(tif int64) sdk.Error {
-	return sdk.NewError(CodeSpaceMarket, CodeInvalidTimeInForce, fmt.Sprintf("Invalid timeInForce : %d; The valid value : 3, 4", tif))
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidTimeInForce, fmt.Sprintf("Invalid timeInForce : %d; The valid value : 3, 4, 5, 6", tif))
 }
 
 func ErrDelistNotAllowed(s string) sdk.Error {
@@ -158,3 +169,47 @@ func ErrOrderAmountTooSmall(err string) sdk.Error {
 func ErrDelistRequestExist(market string) sdk.Error {
 	return sdk.NewError(CodeSpaceMarket, CodeDelistRequestExist, "The delist request for %s already exists", market)
 }
+
+func ErrInvalidTriggerPrice(price int64) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidTriggerPrice, "Invalid trigger price : %d", price)
+}
+
+func ErrInvalidModification(s string) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidModification, "Invalid order modification : %s", s)
+}
+
+func ErrNoReferencePrice(market string) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeNoReferencePrice, "Market %s has no reference price for market orders", market)
+}
+
+func ErrInvalidBatch(s string) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidBatch, "Invalid order batch : %s", s)
+}
+
+func ErrInvalidMatchingAlgorithm(algorithm byte) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidMatching, "Invalid matching algorithm : %d", algorithm)
+}
+
+func ErrInvalidMarketParams(s string) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidMarketParams, s)
+}
+
+func ErrInvalidMarketStatus(status byte) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidMarketStatus, "Invalid market status : %d", status)
+}
+
+func ErrMarketCancelOnly(symbol string) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeMarketCancelOnly, "The orders of market %s can only be cancelled", symbol)
+}
+
+func ErrInvalidExpireTime(expireTime int64) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidExpireTime, fmt.Sprintf("Invalid expire time : %d", expireTime))
+}
+
+func ErrInvalidSelfTradePrevention(mode byte) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidSelfTrade, fmt.Sprintf("Invalid self-trade prevention mode : %d", mode))
+}
+
+func ErrInvalidDisplayQuantity(quantity int64) sdk.Error {
+	return sdk.NewError(CodeSpaceMarket, CodeInvalidDisplayQuantity, fmt.Sprintf("Invalid display quantity : %d", quantity))
+}
@@ -10,6 +10,7 @@ type Keeper interface {
 	ExpectedChargeFeeKeeper
 	ExpectedAuthXKeeper
 	ExpectedBankxKeeper
+	ExpectedFeeRateKeeper
 }
 
 // Bankx Keeper will implement the interface
@@ -31,6 +32,11 @@ type ExpectedAssetStatusKeeper interface {
 	GetToken(ctx sdk.Context, symbol string) asset.Token
 }
 
+type ExpectedFeeRateKeeper interface {
+	// the trading volume of an account in the recent days, which decides its commission rates
+	GetUserVolume(ctx sdk.Context, addr sdk.AccAddress) sdk.Int
+}
+
 type ExpectedChargeFeeKeeper interface {
 	SubtractFeeAndCollectFee(ctx sdk.Context, addr sdk.AccAddress, amt int64) sdk.Error
 }
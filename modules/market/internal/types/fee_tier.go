@@ -0,0 +1,56 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// The trading volume of an account is accumulated in the recent days to pick its fee tier
+const VolumeDays = 30
+
+// FeeTier is the commission rates for the accounts whose trading volume in the last VolumeDays days,
+// which is measured in CET, reaches MinVolume. The rates share the precision of MarketFeeRate.
+type FeeTier struct {
+	MinVolume    int64 `json:"min_volume"`
+	MakerFeeRate int64 `json:"maker_fee_rate"`
+	TakerFeeRate int64 `json:"taker_fee_rate"`
+}
+
+func (tier FeeTier) String() string {
+	return fmt.Sprintf("{MinVolume: %d, MakerFeeRate: %d, TakerFeeRate: %d}",
+		tier.MinVolume, tier.MakerFeeRate, tier.TakerFeeRate)
+}
+
+// GetFeeRates returns the rates of the highest tier reached by the volume. The accounts which reach
+// no tier use MakerFeeRate and MarketFeeRate.
+func (p Params) GetFeeRates(volume sdk.Int) (makerRate, takerRate int64) {
+	makerRate, takerRate = p.MakerFeeRate, p.MarketFeeRate
+	for _, tier := range p.FeeTiers {
+		if volume.LT(sdk.NewInt(tier.MinVolume)) {
+			break
+		}
+		makerRate, takerRate = tier.MakerFeeRate, tier.TakerFeeRate
+	}
+	return
+}
+
+// The commission is frozen at MarketFeeRate, so no rate can be higher than it, and the tiers
+// must be sorted by their volumes.
+func (p Params) validateFeeRates() error {
+	if p.MakerFeeRate < 0 || p.MakerFeeRate > p.MarketFeeRate {
+		return fmt.Errorf("%s : %d must be between 0 and %s : %d", KeyMakerFeeRate,
+			p.MakerFeeRate, KeyMarketFeeRate, p.MarketFeeRate)
+	}
+	for i, tier := range p.FeeTiers {
+		if tier.MinVolume <= 0 || (i > 0 && tier.MinVolume <= p.FeeTiers[i-1].MinVolume) {
+			return fmt.Errorf("%s : the volumes must be positive and ascending, %s", KeyFeeTiers, tier)
+		}
+		if tier.MakerFeeRate < 0 || tier.MakerFeeRate > p.MarketFeeRate ||
+			tier.TakerFeeRate < 0 || tier.TakerFeeRate > p.MarketFeeRate {
+			return fmt.Errorf("%s : the rates must be between 0 and %s : %d, %s", KeyFeeTiers,
+				KeyMarketFeeRate, p.MarketFeeRate, tier)
+		}
+	}
+	return nil
+}
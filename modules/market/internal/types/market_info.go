@@ -14,6 +14,35 @@ type MarketInfo struct {
 	PricePrecision    byte    `json:"price_precision"`
 	LastExecutedPrice sdk.Dec `json:"last_executed_price"`
 	OrderPrecision    byte    `json:"order_precision"`
+	MatchingAlgorithm byte    `json:"matching_algorithm"`
+	// the overrides of the global params, which are set by the owner of the stock token
+	Params MarketParams `json:"params"`
+}
+
+// The algorithms to match the orders of a market
+const (
+	// periodic call auction at a single execution price, which is the default
+	CallAuction byte = 0
+	// continuous matching by price-time priority, at the price of the maker
+	ContinuousMatching byte = 1
+	// the orders at the marginal price are filled in proportion to their amounts
+	ProRataMatching byte = 2
+)
+
+var matchingAlgorithmNames = map[string]byte{
+	"call-auction": CallAuction,
+	"continuous":   ContinuousMatching,
+	"pro-rata":     ProRataMatching,
+}
+
+// ParseMatchingAlgorithm returns the algorithm with the given name, i.e. call-auction, continuous or pro-rata
+func ParseMatchingAlgorithm(name string) (byte, bool) {
+	algorithm, ok := matchingAlgorithmNames[name]
+	return algorithm, ok
+}
+
+func IsValidMatchingAlgorithm(algorithm byte) bool {
+	return algorithm <= ProRataMatching
 }
 
 func GetGranularityOfOrder(orderPrecision byte) int64 {
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MarketParams overrides the global params for a single market, the zero values mean the global
+// params are used. TickSize is in the unit of 10^-PricePrecision of the market.
+type MarketParams struct {
+	MaxExecutedPriceChangeRatio int64 `json:"max_executed_price_change_ratio,omitempty"`
+	MarketFeeRate               int64 `json:"market_fee_rate,omitempty"`
+	GTEOrderLifetime            int64 `json:"gte_order_lifetime,omitempty"`
+	MinOrderQuantity            int64 `json:"min_order_quantity,omitempty"`
+	TickSize                    int64 `json:"tick_size,omitempty"`
+}
+
+func (mp MarketParams) String() string {
+	return fmt.Sprintf("{MaxExecutedPriceChangeRatio: %d, MarketFeeRate: %d, GTEOrderLifetime: %d, "+
+		"MinOrderQuantity: %d, TickSize: %d}", mp.MaxExecutedPriceChangeRatio, mp.MarketFeeRate,
+		mp.GTEOrderLifetime, mp.MinOrderQuantity, mp.TickSize)
+}
+
+func (mp MarketParams) ValidateBasic() sdk.Error {
+	if mp.MaxExecutedPriceChangeRatio < 0 || mp.MarketFeeRate < 0 || mp.GTEOrderLifetime < 0 ||
+		mp.MinOrderQuantity < 0 || mp.TickSize < 0 {
+		return ErrInvalidMarketParams("market params can not be negative: " + mp.String())
+	}
+	if mp.MinOrderQuantity > MaxOrderAmount || mp.TickSize > MaxOrderAmount {
+		return ErrInvalidMarketParams("min order quantity and tick size can not exceed the max order amount")
+	}
+	return nil
+}
+
+// ValidateMarketParams checks the overrides of a market against the limits in the global params
+func (p Params) ValidateMarketParams(mp MarketParams) sdk.Error {
+	if mp.MaxExecutedPriceChangeRatio > p.MaxExecutedPriceChangeRatioLimit {
+		return ErrInvalidMarketParams(fmt.Sprintf("%s : %d exceeds the limit %d", KeyMaxExecutedPriceChangeRatio,
+			mp.MaxExecutedPriceChangeRatio, p.MaxExecutedPriceChangeRatioLimit))
+	}
+	if mp.MarketFeeRate > p.MarketFeeRateLimit {
+		return ErrInvalidMarketParams(fmt.Sprintf("%s : %d exceeds the limit %d", KeyMarketFeeRate,
+			mp.MarketFeeRate, p.MarketFeeRateLimit))
+	}
+	if mp.GTEOrderLifetime > p.GTEOrderLifetimeLimit {
+		return ErrInvalidMarketParams(fmt.Sprintf("%s : %d exceeds the limit %d", KeyGTEOrderLifetime,
+			mp.GTEOrderLifetime, p.GTEOrderLifetimeLimit))
+	}
+	return nil
+}
+
+// GetEffectiveParams returns the global params with the overrides of this market applied.
+// When MarketFeeRate is overridden, the maker rate and the tier rates keep their proportions to it.
+func (info MarketInfo) GetEffectiveParams(global Params) Params {
+	p := global
+	if info.Params.MaxExecutedPriceChangeRatio != 0 {
+		p.MaxExecutedPriceChangeRatio = info.Params.MaxExecutedPriceChangeRatio
+	}
+	if info.Params.GTEOrderLifetime != 0 {
+		p.GTEOrderLifetime = info.Params.GTEOrderLifetime
+	}
+	if rate := info.Params.MarketFeeRate; rate != 0 && rate != global.MarketFeeRate {
+		p.MarketFeeRate = rate
+		p.MakerFeeRate = scaleFeeRate(global.MakerFeeRate, rate, global.MarketFeeRate)
+		if len(global.FeeTiers) != 0 {
+			p.FeeTiers = make([]FeeTier, len(global.FeeTiers))
+			for i, tier := range global.FeeTiers {
+				p.FeeTiers[i] = FeeTier{
+					MinVolume:    tier.MinVolume,
+					MakerFeeRate: scaleFeeRate(tier.MakerFeeRate, rate, global.MarketFeeRate),
+					TakerFeeRate: scaleFeeRate(tier.TakerFeeRate, rate, global.MarketFeeRate),
+				}
+			}
+		}
+	}
+	return p
+}
+
+func scaleFeeRate(feeRate, newBase, oldBase int64) int64 {
+	if oldBase == 0 {
+		return 0
+	}
+	return sdk.NewInt(feeRate).MulRaw(newBase).QuoRaw(oldBase).Int64()
+}
+
+// CheckOrderQuantity returns an error if the quantity is less than MinOrderQuantity
+func (info MarketInfo) CheckOrderQuantity(quantity int64) sdk.Error {
+	if quantity < info.Params.MinOrderQuantity {
+		return ErrInvalidOrderAmount(fmt.Sprintf("order quantity %d is less than the min order quantity %d",
+			quantity, info.Params.MinOrderQuantity))
+	}
+	return nil
+}
+
+// CheckOrderPrice returns an error if the price, which is in the given precision, is not a multiple of TickSize
+func (info MarketInfo) CheckOrderPrice(price int64, pricePrecision byte) sdk.Error {
+	if info.Params.TickSize <= 1 || pricePrecision > info.PricePrecision {
+		return nil
+	}
+	scaled := sdk.NewInt(price).Mul(sdk.NewIntWithDecimal(1, int(info.PricePrecision-pricePrecision)))
+	if !scaled.ModRaw(info.Params.TickSize).IsZero() {
+		return ErrInvalidPrice(price)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEffectiveParams(t *testing.T) {
+	global := DefaultParams()
+	global.MakerFeeRate = DefaultMakerFeeRate
+	global.FeeTiers = []FeeTier{{MinVolume: 1000, MakerFeeRate: 4, TakerFeeRate: 8}}
+
+	info := MarketInfo{Stock: "abc", Money: "cet"}
+	require.True(t, global.Equal(info.GetEffectiveParams(global)))
+
+	info.Params = MarketParams{
+		MaxExecutedPriceChangeRatio: 40,
+		MarketFeeRate:               20,
+		GTEOrderLifetime:            1000,
+	}
+	p := info.GetEffectiveParams(global)
+	require.EqualValues(t, 40, p.MaxExecutedPriceChangeRatio)
+	require.EqualValues(t, 20, p.MarketFeeRate)
+	require.EqualValues(t, 1000, p.GTEOrderLifetime)
+	require.EqualValues(t, 10, p.MakerFeeRate)
+	require.Equal(t, []FeeTier{{MinVolume: 1000, MakerFeeRate: 8, TakerFeeRate: 16}}, p.FeeTiers)
+	require.Nil(t, p.ValidateGenesis())
+	// the global params are not changed
+	require.EqualValues(t, 4, global.FeeTiers[0].MakerFeeRate)
+	require.EqualValues(t, DefaultMarketFeeRate, global.MarketFeeRate)
+}
+
+func TestValidateMarketParams(t *testing.T) {
+	global := DefaultParams()
+	require.Nil(t, MarketParams{}.ValidateBasic())
+	require.Nil(t, global.ValidateMarketParams(MarketParams{}))
+
+	mp := MarketParams{
+		MaxExecutedPriceChangeRatio: DefaultMaxExecutedPriceChangeRatioLimit,
+		MarketFeeRate:               DefaultMarketFeeRateLimit,
+		GTEOrderLifetime:            DefaultGTEOrderLifetimeLimit,
+		MinOrderQuantity:            100,
+		TickSize:                    5,
+	}
+	require.Nil(t, mp.ValidateBasic())
+	require.Nil(t, global.ValidateMarketParams(mp))
+
+	mp1 := mp
+	mp1.TickSize = -1
+	require.Equal(t, CodeInvalidMarketParams, mp1.ValidateBasic().Code())
+	mp1 = mp
+	mp1.MarketFeeRate++
+	require.Equal(t, CodeInvalidMarketParams, global.ValidateMarketParams(mp1).Code())
+	mp1 = mp
+	mp1.MaxExecutedPriceChangeRatio++
+	require.Equal(t, CodeInvalidMarketParams, global.ValidateMarketParams(mp1).Code())
+	mp1 = mp
+	mp1.GTEOrderLifetime++
+	require.Equal(t, CodeInvalidMarketParams, global.ValidateMarketParams(mp1).Code())
+}
+
+func TestMarketInfoCheckOrder(t *testing.T) {
+	info := MarketInfo{Stock: "abc", Money: "cet", PricePrecision: 4}
+	require.Nil(t, info.CheckOrderQuantity(1))
+	require.Nil(t, info.CheckOrderPrice(12345, 4))
+
+	info.Params = MarketParams{MinOrderQuantity: 100, TickSize: 50}
+	require.Equal(t, CodeInvalidOrderAmount, info.CheckOrderQuantity(99).Code())
+	require.Nil(t, info.CheckOrderQuantity(100))
+	require.Nil(t, info.CheckOrderPrice(12350, 4))
+	require.Equal(t, CodeInvalidPrice, info.CheckOrderPrice(12345, 4).Code())
+	// 1235 with precision 3 is 12350 with precision 4
+	require.Nil(t, info.CheckOrderPrice(1235, 3))
+	require.Equal(t, CodeInvalidPrice, info.CheckOrderPrice(1234, 3).Code())
+}
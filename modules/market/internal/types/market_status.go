@@ -0,0 +1,93 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// The trading status of a market
+const (
+	// orders are accepted and matched, which is the default
+	MarketOpen byte = 0
+	// orders are accepted but not matched
+	MarketHalted byte = 1
+	// orders can only be cancelled, and they are not matched
+	MarketCancelOnly byte = 2
+	// orders are accepted and matched by call auction, whatever the matching algorithm of the market is
+	MarketAuctionOnly byte = 3
+)
+
+// The reasons of status changes
+const (
+	StatusChangedByOwner          = "Changed by the owner of the stock"
+	StatusChangedByCircuitBreaker = "The executed price moved too much"
+)
+
+var marketStatusNames = map[string]byte{
+	"open":         MarketOpen,
+	"halted":       MarketHalted,
+	"cancel-only":  MarketCancelOnly,
+	"auction-only": MarketAuctionOnly,
+}
+
+// ParseMarketStatus returns the status with the given name, i.e. open, halted, cancel-only or auction-only
+func ParseMarketStatus(name string) (byte, bool) {
+	status, ok := marketStatusNames[name]
+	return status, ok
+}
+
+func MarketStatusName(status byte) string {
+	for name, s := range marketStatusNames {
+		if s == status {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+func IsValidMarketStatus(status byte) bool {
+	return status <= MarketAuctionOnly
+}
+
+// MarketStatus is stored next to MarketInfo, the markets without it are open.
+// The circuit breaker compares the executed prices with RefPrice, which is
+// reset to the last executed price every CircuitBreakerBlocks blocks.
+// RefPrice is zero before the first match and after the market is resumed.
+type MarketStatus struct {
+	Status    byte    `json:"status"`
+	Reason    string  `json:"reason"`
+	Height    int64   `json:"height"`
+	RefPrice  sdk.Dec `json:"ref_price"`
+	RefHeight int64   `json:"ref_height"`
+}
+
+func (s MarketStatus) AcceptsOrders() bool {
+	return s.Status != MarketCancelOnly
+}
+
+func (s MarketStatus) IsMatching() bool {
+	return s.Status == MarketOpen || s.Status == MarketAuctionOnly
+}
+
+// UpdateRefPrice starts a new window of the circuit breaker with lastPrice when the current one
+// is older than the given blocks, and returns whether the reference price is changed. Without
+// a reference price, the window starts with newPrice, so the first match is never halted.
+func (s *MarketStatus) UpdateRefPrice(height, blocks int64, lastPrice, newPrice sdk.Dec) bool {
+	if s.RefPrice.IsNil() || s.RefPrice.IsZero() {
+		s.RefPrice, s.RefHeight = newPrice, height
+		return true
+	}
+	if height-s.RefHeight < blocks {
+		return false
+	}
+	s.RefPrice, s.RefHeight = lastPrice, height
+	return true
+}
+
+// ExceedsCircuitBreaker returns true if the price moves more than CircuitBreakerRatio percent from refPrice
+func (p Params) ExceedsCircuitBreaker(refPrice, price sdk.Dec) bool {
+	if p.CircuitBreakerRatio == 0 || refPrice.IsNil() || refPrice.IsZero() || price.IsZero() {
+		return false
+	}
+	limit := refPrice.MulInt64(p.CircuitBreakerRatio).QuoInt64(100)
+	return price.Sub(refPrice).Abs().GT(limit)
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestParseMarketStatus(t *testing.T) {
+	for _, name := range []string{"open", "halted", "cancel-only", "auction-only"} {
+		status, ok := ParseMarketStatus(name)
+		require.True(t, ok)
+		require.True(t, IsValidMarketStatus(status))
+		require.Equal(t, name, MarketStatusName(status))
+	}
+	_, ok := ParseMarketStatus("closed")
+	require.False(t, ok)
+	require.Equal(t, "unknown", MarketStatusName(MarketAuctionOnly+1))
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	params := DefaultParams()
+	status := MarketStatus{}
+	require.True(t, status.IsMatching())
+	require.True(t, status.AcceptsOrders())
+
+	require.True(t, status.UpdateRefPrice(10, 100, sdk.NewDec(50), sdk.NewDec(100)))
+	require.False(t, status.UpdateRefPrice(109, 100, sdk.NewDec(120), sdk.NewDec(130)))
+	require.Equal(t, sdk.NewDec(100), status.RefPrice)
+	// disabled by default
+	require.False(t, params.ExceedsCircuitBreaker(status.RefPrice, sdk.NewDec(1000)))
+
+	params.CircuitBreakerRatio = 20
+	require.False(t, params.ExceedsCircuitBreaker(status.RefPrice, sdk.NewDec(120)))
+	require.False(t, params.ExceedsCircuitBreaker(status.RefPrice, sdk.NewDec(80)))
+	require.True(t, params.ExceedsCircuitBreaker(status.RefPrice, sdk.NewDec(121)))
+	require.True(t, params.ExceedsCircuitBreaker(status.RefPrice, sdk.NewDec(79)))
+
+	require.True(t, status.UpdateRefPrice(110, 100, sdk.NewDec(120), sdk.NewDec(130)))
+	require.EqualValues(t, 110, status.RefHeight)
+	require.Equal(t, sdk.NewDec(120), status.RefPrice)
+
+	status.Status = MarketCancelOnly
+	require.False(t, status.IsMatching())
+	require.False(t, status.AcceptsOrders())
+	status.Status = MarketAuctionOnly
+	require.True(t, status.IsMatching())
+	require.True(t, status.AcceptsOrders())
+}
@@ -19,6 +19,9 @@ const (
 	CreateOrderInfoKey  = "create_order_info"
 	FillOrderInfoKey    = "fill_order_info"
 	CancelOrderInfoKey  = "del_order_info"
+	TriggerOrderInfoKey = "trigger_order_info"
+	ModifyOrderInfoKey  = "modify_order_info"
+	MarketStatusInfoKey = "market_status_info"
 )
 
 // cancel order of reasons
@@ -28,6 +31,10 @@ const (
 	CancelOrderByGteTimeOut    = "GTE order timeout"
 	CancelOrderByIocType       = "IOC order cancel "
 	CancelOrderByNoEnoughMoney = "Insufficient freeze money"
+	CancelOrderByFokNotFilled  = "FOK order was not fully filled"
+	CancelOrderByPostOnlyCross = "Post-only order would take liquidity"
+	CancelOrderByExpireTime    = "Order reached its expire time"
+	CancelOrderBySelfTrade     = "Order was cancelled to prevent self-trade"
 	CancelOrderByNotKnow       = "Don't know"
 )
 
@@ -37,11 +44,13 @@ const (
 var _ sdk.Msg = MsgCreateTradingPair{}
 
 type MsgCreateTradingPair struct {
-	Stock          string         `json:"stock"`
-	Money          string         `json:"money"`
-	Creator        sdk.AccAddress `json:"creator"`
-	PricePrecision byte           `json:"price_precision"`
-	OrderPrecision byte           `json:"order_precision"`
+	Stock          string         `json:"stock" pb:"1"`
+	Money          string         `json:"money" pb:"2"`
+	Creator        sdk.AccAddress `json:"creator" pb:"3"`
+	PricePrecision byte           `json:"price_precision" pb:"4"`
+	OrderPrecision byte           `json:"order_precision" pb:"5"`
+	// the zero value is omitted, so the signatures of the existing messages are kept
+	MatchingAlgorithm byte `json:"matching_algorithm,omitempty" pb:"6"`
 }
 
 func NewMsgCreateTradingPair(stock, money string, creator sdk.AccAddress, pricePrecision byte, orderPrecision byte) MsgCreateTradingPair {
@@ -82,6 +91,9 @@ func (msg MsgCreateTradingPair) ValidateBasic() sdk.Error {
 	if msg.Money == msg.Stock {
 		return ErrStockAndMoneyAreSame()
 	}
+	if !IsValidMatchingAlgorithm(msg.MatchingAlgorithm) {
+		return ErrInvalidMatchingAlgorithm(msg.MatchingAlgorithm)
+	}
 	return nil
 }
 
@@ -109,6 +121,13 @@ type MsgCreateOrder struct {
 	Side           byte           `json:"side"`
 	TimeInForce    int64          `json:"time_in_force"`
 	ExistBlocks    int64          `json:"exist_blocks"`
+	TriggerPrice   int64          `json:"trigger_price,omitempty"`
+	// the unix time after which the order is cancelled, zero means the order only expires by ExistBlocks
+	ExpireTime int64 `json:"expire_time,omitempty"`
+	// what to do when the order would deal with another order of the same sender, zero allows it
+	SelfTradePrevention byte `json:"self_trade_prevention,omitempty"`
+	// an iceberg order only shows DisplayQuantity in the order book, zero shows the whole quantity
+	DisplayQuantity int64 `json:"display_quantity,omitempty"`
 }
 
 func (msg *MsgCreateOrder) SetAccAddress(address sdk.AccAddress) {
@@ -126,27 +145,56 @@ func (msg MsgCreateOrder) ValidateBasic() sdk.Error {
 	if !IsValidTradingPair(strings.Split(msg.TradingPair, SymbolSeparator)) {
 		return ErrInvalidSymbol()
 	}
-	if msg.OrderType != LimitOrder {
+	if msg.OrderType < MarketOrder || msg.OrderType > StopMarketOrder {
 		return ErrInvalidOrderType()
 	}
 	if p := msg.PricePrecision; p > MaxTokenPricePrecision {
 		return ErrInvalidPricePrecision(p)
 	}
-	if msg.Price <= 0 {
+	if msg.IsMarketOrder() {
+		// the price of a market order is decided by the chain
+		if msg.Price != 0 {
+			return ErrInvalidPrice(msg.Price)
+		}
+	} else if msg.Price <= 0 {
 		return ErrInvalidPrice(msg.Price)
 	}
+	if msg.IsStopOrder() {
+		if msg.TriggerPrice <= 0 {
+			return ErrInvalidTriggerPrice(msg.TriggerPrice)
+		}
+	} else if msg.TriggerPrice != 0 {
+		return ErrInvalidTriggerPrice(msg.TriggerPrice)
+	}
 	if msg.Quantity <= 0 {
 		return ErrOrderAmountTooSmall(fmt.Sprintf("%d", msg.Quantity))
 	}
 	if msg.Side != BUY && msg.Side != SELL {
 		return ErrInvalidTradeSide()
 	}
-	if msg.TimeInForce != GTE && msg.TimeInForce != IOC {
+	if msg.TimeInForce < GTE || msg.TimeInForce > FOK {
+		return ErrInvalidTimeInForce(msg.TimeInForce)
+	}
+	if msg.IsMarketOrder() && !msg.IsImmediateOrder() {
+		return ErrInvalidTimeInForce(msg.TimeInForce)
+	}
+	if msg.IsStopOrder() && msg.TimeInForce == PostOnly {
 		return ErrInvalidTimeInForce(msg.TimeInForce)
 	}
 	if msg.ExistBlocks < 0 {
 		return ErrInvalidExistBlocks(msg.ExistBlocks)
 	}
+	if msg.ExpireTime < 0 || (msg.ExpireTime != 0 && msg.IsImmediateOrder() && !msg.IsStopOrder()) {
+		return ErrInvalidExpireTime(msg.ExpireTime)
+	}
+	if !IsValidSelfTradePrevention(msg.SelfTradePrevention) {
+		return ErrInvalidSelfTradePrevention(msg.SelfTradePrevention)
+	}
+	// only the limit orders resting in the order book can hide a part of their quantity
+	if msg.DisplayQuantity < 0 || (msg.DisplayQuantity != 0 &&
+		(msg.DisplayQuantity >= msg.Quantity || msg.OrderType != LimitOrder || msg.TimeInForce != GTE)) {
+		return ErrInvalidDisplayQuantity(msg.DisplayQuantity)
+	}
 
 	return nil
 }
@@ -163,6 +211,112 @@ func (msg MsgCreateOrder) IsGTEOrder() bool {
 	return msg.TimeInForce == GTE
 }
 
+// IOC orders and FOK orders are removed at the end of the block they entered
+func (msg MsgCreateOrder) IsImmediateOrder() bool {
+	return msg.TimeInForce == IOC || msg.TimeInForce == FOK
+}
+
+// Market orders and stop-market orders have no limit price
+func (msg MsgCreateOrder) IsMarketOrder() bool {
+	return msg.OrderType == MarketOrder || msg.OrderType == StopMarketOrder
+}
+
+func (msg MsgCreateOrder) IsStopOrder() bool {
+	return msg.OrderType == StopLimitOrder || msg.OrderType == StopMarketOrder
+}
+
+// /////////////////////////////////////////////////////////
+// MsgCreateOrders
+
+var _ sdk.Msg = MsgCreateOrders{}
+
+// OrderItem is one order in a MsgCreateOrders batch, and its fields have the same
+// meanings as those of MsgCreateOrder
+type OrderItem struct {
+	Identify       byte  `json:"identify"`
+	OrderType      byte  `json:"order_type"`
+	PricePrecision byte  `json:"price_precision"`
+	Price          int64 `json:"price"`
+	Quantity       int64 `json:"quantity"`
+	Side           byte  `json:"side"`
+	TimeInForce    int64 `json:"time_in_force"`
+	ExistBlocks    int64 `json:"exist_blocks"`
+	TriggerPrice   int64 `json:"trigger_price,omitempty"`
+	ExpireTime     int64 `json:"expire_time,omitempty"`
+
+	SelfTradePrevention byte  `json:"self_trade_prevention,omitempty"`
+	DisplayQuantity     int64 `json:"display_quantity,omitempty"`
+}
+
+// MsgCreateOrders creates a batch of orders in one trading pair. All the orders
+// are validated and frozen together, so either all of them or none is created.
+type MsgCreateOrders struct {
+	Sender      sdk.AccAddress `json:"sender"`
+	TradingPair string         `json:"trading_pair"`
+	Orders      []OrderItem    `json:"orders"`
+}
+
+func (msg *MsgCreateOrders) SetAccAddress(address sdk.AccAddress) {
+	msg.Sender = address
+}
+
+func (msg MsgCreateOrders) Route() string { return RouterKey }
+
+func (msg MsgCreateOrders) Type() string { return "create_orders" }
+
+func (msg MsgCreateOrders) ValidateBasic() sdk.Error {
+	if err := sdk.VerifyAddressFormat(msg.Sender); err != nil {
+		return ErrInvalidAddress()
+	}
+	if len(msg.Orders) == 0 || len(msg.Orders) > MaxOrdersInBatch {
+		return ErrInvalidBatch(fmt.Sprintf("the batch should have 1 to %d orders", MaxOrdersInBatch))
+	}
+	identifies := make(map[byte]struct{}, len(msg.Orders))
+	for _, order := range msg.GetCreateOrderMsgs() {
+		if _, ok := identifies[order.Identify]; ok {
+			return ErrInvalidBatch(fmt.Sprintf("duplicated identify : %d", order.Identify))
+		}
+		identifies[order.Identify] = struct{}{}
+		if err := order.ValidateBasic(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (msg MsgCreateOrders) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgCreateOrders) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
+// GetCreateOrderMsgs expands the batch into single-order messages
+func (msg MsgCreateOrders) GetCreateOrderMsgs() []MsgCreateOrder {
+	msgs := make([]MsgCreateOrder, len(msg.Orders))
+	for i, item := range msg.Orders {
+		msgs[i] = MsgCreateOrder{
+			Sender:         msg.Sender,
+			Identify:       item.Identify,
+			TradingPair:    msg.TradingPair,
+			OrderType:      item.OrderType,
+			PricePrecision: item.PricePrecision,
+			Price:          item.Price,
+			Quantity:       item.Quantity,
+			Side:           item.Side,
+			TimeInForce:    item.TimeInForce,
+			ExistBlocks:    item.ExistBlocks,
+			TriggerPrice:   item.TriggerPrice,
+			ExpireTime:     item.ExpireTime,
+
+			SelfTradePrevention: item.SelfTradePrevention,
+			DisplayQuantity:     item.DisplayQuantity,
+		}
+	}
+	return msgs
+}
+
 // /////////////////////////////////////////////////////////
 // MsgCancelOrder
 
@@ -215,6 +369,98 @@ func (msg MsgCancelOrder) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.Sender}
 }
 
+// /////////////////////////////////////////////////////////
+// MsgCancelAllOrders
+
+var _ sdk.Msg = MsgCancelAllOrders{}
+
+// MsgCancelAllOrders cancels all the sender's orders in a trading pair.
+// Side is BUY or SELL to cancel only the orders on one side, or 0 for both sides.
+type MsgCancelAllOrders struct {
+	Sender      sdk.AccAddress `json:"sender"`
+	TradingPair string         `json:"trading_pair"`
+	Side        byte           `json:"side"`
+}
+
+func (msg *MsgCancelAllOrders) SetAccAddress(address sdk.AccAddress) {
+	msg.Sender = address
+}
+
+func (msg MsgCancelAllOrders) Route() string { return RouterKey }
+
+func (msg MsgCancelAllOrders) Type() string { return "cancel_all_orders" }
+
+func (msg MsgCancelAllOrders) ValidateBasic() sdk.Error {
+	if err := sdk.VerifyAddressFormat(msg.Sender); err != nil {
+		return ErrInvalidAddress()
+	}
+	if !IsValidTradingPair(strings.Split(msg.TradingPair, SymbolSeparator)) {
+		return ErrInvalidSymbol()
+	}
+	if msg.Side != 0 && msg.Side != BUY && msg.Side != SELL {
+		return ErrInvalidTradeSide()
+	}
+	return nil
+}
+
+func (msg MsgCancelAllOrders) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgCancelAllOrders) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
+// /////////////////////////////////////////////////////////
+// MsgModifyOrder
+
+var _ sdk.Msg = MsgModifyOrder{}
+
+// MsgModifyOrder changes the price and the quantity of an order in place.
+// Quantity is the new total quantity, which can only be decreased.
+type MsgModifyOrder struct {
+	Sender         sdk.AccAddress `json:"sender"`
+	OrderID        string         `json:"order_id"`
+	PricePrecision byte           `json:"price_precision"`
+	Price          int64          `json:"price"`
+	Quantity       int64          `json:"quantity"`
+}
+
+func (msg *MsgModifyOrder) SetAccAddress(address sdk.AccAddress) {
+	msg.Sender = address
+}
+
+func (msg MsgModifyOrder) Route() string { return RouterKey }
+
+func (msg MsgModifyOrder) Type() string { return "modify_order" }
+
+func (msg MsgModifyOrder) ValidateBasic() sdk.Error {
+	if err := sdk.VerifyAddressFormat(msg.Sender); err != nil {
+		return ErrInvalidAddress()
+	}
+	if err := ValidateOrderID(msg.OrderID); err != nil {
+		return err
+	}
+	if p := msg.PricePrecision; p > MaxTokenPricePrecision {
+		return ErrInvalidPricePrecision(p)
+	}
+	if msg.Price <= 0 {
+		return ErrInvalidPrice(msg.Price)
+	}
+	if msg.Quantity <= 0 {
+		return ErrOrderAmountTooSmall(fmt.Sprintf("%d", msg.Quantity))
+	}
+	return nil
+}
+
+func (msg MsgModifyOrder) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgModifyOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
 // /////////////////////////////////////////////////////////
 // MsgCancelTradingPair
 
@@ -312,3 +558,125 @@ func (msg MsgModifyPricePrecision) GetSignBytes() []byte {
 func (msg MsgModifyPricePrecision) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.Sender}
 }
+
+// -------------------------------------------------
+// MsgModifyMarketParams
+
+// MsgModifyMarketParams replaces all the overrides of a market, the zero values restore the global params
+type MsgModifyMarketParams struct {
+	Sender      sdk.AccAddress `json:"sender"`
+	TradingPair string         `json:"trading_pair"`
+	Params      MarketParams   `json:"params"`
+}
+
+func (msg *MsgModifyMarketParams) SetAccAddress(address sdk.AccAddress) {
+	msg.Sender = address
+}
+
+func (msg MsgModifyMarketParams) Route() string {
+	return RouterKey
+}
+
+func (msg MsgModifyMarketParams) Type() string {
+	return "modify_market_params"
+}
+
+func (msg MsgModifyMarketParams) ValidateBasic() sdk.Error {
+	if err := sdk.VerifyAddressFormat(msg.Sender); err != nil {
+		return ErrInvalidAddress()
+	}
+	if !IsValidTradingPair(strings.Split(msg.TradingPair, SymbolSeparator)) {
+		return ErrInvalidSymbol()
+	}
+	return msg.Params.ValidateBasic()
+}
+
+func (msg MsgModifyMarketParams) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgModifyMarketParams) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
+// -------------------------------------------------
+// MsgHaltMarket
+
+// MsgHaltMarket changes the status of an open market to halted, cancel-only or auction-only
+type MsgHaltMarket struct {
+	Sender      sdk.AccAddress `json:"sender"`
+	TradingPair string         `json:"trading_pair"`
+	Status      byte           `json:"status"`
+}
+
+func (msg *MsgHaltMarket) SetAccAddress(address sdk.AccAddress) {
+	msg.Sender = address
+}
+
+func (msg MsgHaltMarket) Route() string {
+	return RouterKey
+}
+
+func (msg MsgHaltMarket) Type() string {
+	return "halt_market"
+}
+
+func (msg MsgHaltMarket) ValidateBasic() sdk.Error {
+	if err := sdk.VerifyAddressFormat(msg.Sender); err != nil {
+		return ErrInvalidAddress()
+	}
+	if !IsValidTradingPair(strings.Split(msg.TradingPair, SymbolSeparator)) {
+		return ErrInvalidSymbol()
+	}
+	if msg.Status == MarketOpen || !IsValidMarketStatus(msg.Status) {
+		return ErrInvalidMarketStatus(msg.Status)
+	}
+	return nil
+}
+
+func (msg MsgHaltMarket) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgHaltMarket) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
+
+// -------------------------------------------------
+// MsgResumeMarket
+
+// MsgResumeMarket reopens a market which is halted manually or by the circuit breaker
+type MsgResumeMarket struct {
+	Sender      sdk.AccAddress `json:"sender"`
+	TradingPair string         `json:"trading_pair"`
+}
+
+func (msg *MsgResumeMarket) SetAccAddress(address sdk.AccAddress) {
+	msg.Sender = address
+}
+
+func (msg MsgResumeMarket) Route() string {
+	return RouterKey
+}
+
+func (msg MsgResumeMarket) Type() string {
+	return "resume_market"
+}
+
+func (msg MsgResumeMarket) ValidateBasic() sdk.Error {
+	if err := sdk.VerifyAddressFormat(msg.Sender); err != nil {
+		return ErrInvalidAddress()
+	}
+	if !IsValidTradingPair(strings.Split(msg.TradingPair, SymbolSeparator)) {
+		return ErrInvalidSymbol()
+	}
+	return nil
+}
+
+func (msg MsgResumeMarket) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+func (msg MsgResumeMarket) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Sender}
+}
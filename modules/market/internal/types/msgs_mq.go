@@ -24,56 +24,90 @@ type CancelMarketInfo struct {
 }
 
 type CreateOrderInfo struct {
-	OrderID          string  `json:"order_id"`
-	Sender           string  `json:"sender"`
-	TradingPair      string  `json:"trading_pair"`
-	OrderType        byte    `json:"order_type"`
-	Price            sdk.Dec `json:"price"`
-	Quantity         int64   `json:"quantity"`
-	Side             byte    `json:"side"`
-	TimeInForce      int64   `json:"time_in_force"`
-	Height           int64   `json:"height"`
-	FrozenCommission int64   `json:"frozen_commission"`
-	FrozenFeatureFee int64   `json:"frozen_feature_fee"`
-	Freeze           int64   `json:"freeze"`
+	OrderID          string  `json:"order_id" pb:"1"`
+	Sender           string  `json:"sender" pb:"2"`
+	TradingPair      string  `json:"trading_pair" pb:"3"`
+	OrderType        byte    `json:"order_type" pb:"4"`
+	Price            sdk.Dec `json:"price" pb:"5"`
+	Quantity         int64   `json:"quantity" pb:"6"`
+	Side             byte    `json:"side" pb:"7"`
+	TimeInForce      int64   `json:"time_in_force" pb:"8"`
+	Height           int64   `json:"height" pb:"9"`
+	FrozenCommission int64   `json:"frozen_commission" pb:"10"`
+	FrozenFeatureFee int64   `json:"frozen_feature_fee" pb:"11"`
+	Freeze           int64   `json:"freeze" pb:"12"`
+	TriggerPrice     sdk.Dec `json:"trigger_price" pb:"13"`
+	ExpireTime       int64   `json:"expire_time,omitempty" pb:"14"`
+
+	SelfTradePrevention byte  `json:"self_trade_prevention,omitempty" pb:"15"`
+	DisplayQuantity     int64 `json:"display_quantity,omitempty" pb:"16"`
 }
 
 type FillOrderInfo struct {
-	OrderID     string  `json:"order_id"`
-	TradingPair string  `json:"trading_pair"`
-	Height      int64   `json:"height"`
-	Side        byte    `json:"side"`
-	Price       sdk.Dec `json:"price"`
+	OrderID     string  `json:"order_id" pb:"1"`
+	TradingPair string  `json:"trading_pair" pb:"2"`
+	Height      int64   `json:"height" pb:"3"`
+	OrderType   byte    `json:"order_type" pb:"4"`
+	Side        byte    `json:"side" pb:"5"`
+	Price       sdk.Dec `json:"price" pb:"6"`
 
 	// These fields will change when order was filled/canceled.
-	LeftStock int64   `json:"left_stock"`
-	Freeze    int64   `json:"freeze"`
-	DealStock int64   `json:"deal_stock"`
-	DealMoney int64   `json:"deal_money"`
-	CurrStock int64   `json:"curr_stock"`
-	CurrMoney int64   `json:"curr_money"`
-	FillPrice sdk.Dec `json:"fill_price"`
+	LeftStock int64   `json:"left_stock" pb:"7"`
+	Freeze    int64   `json:"freeze" pb:"8"`
+	DealStock int64   `json:"deal_stock" pb:"9"`
+	DealMoney int64   `json:"deal_money" pb:"10"`
+	CurrStock int64   `json:"curr_stock" pb:"11"`
+	CurrMoney int64   `json:"curr_money" pb:"12"`
+	FillPrice sdk.Dec `json:"fill_price" pb:"13"`
+}
+
+type TriggerOrderInfo struct {
+	OrderID           string  `json:"order_id" pb:"1"`
+	TradingPair       string  `json:"trading_pair" pb:"2"`
+	OrderType         byte    `json:"order_type" pb:"3"`
+	Side              byte    `json:"side" pb:"4"`
+	Price             sdk.Dec `json:"price" pb:"5"`
+	TriggerPrice      sdk.Dec `json:"trigger_price" pb:"6"`
+	LastExecutedPrice sdk.Dec `json:"last_executed_price" pb:"7"`
+	TriggerHeight     int64   `json:"trigger_height" pb:"8"`
+}
+
+type ModifyOrderInfo struct {
+	OrderID     string  `json:"order_id" pb:"1"`
+	Sender      string  `json:"sender" pb:"2"`
+	TradingPair string  `json:"trading_pair" pb:"3"`
+	Height      int64   `json:"height" pb:"4"`
+	Side        byte    `json:"side" pb:"5"`
+	OldPrice    sdk.Dec `json:"old_price" pb:"6"`
+	NewPrice    sdk.Dec `json:"new_price" pb:"7"`
+	OldQuantity int64   `json:"old_quantity" pb:"8"`
+	NewQuantity int64   `json:"new_quantity" pb:"9"`
+
+	// the fields after modification
+	LeftStock        int64 `json:"left_stock" pb:"10"`
+	Freeze           int64 `json:"freeze" pb:"11"`
+	FrozenCommission int64 `json:"frozen_commission" pb:"12"`
 }
 
 type CancelOrderInfo struct {
-	OrderID     string  `json:"order_id"`
-	TradingPair string  `json:"trading_pair"`
-	Height      int64   `json:"height"`
-	Side        byte    `json:"side"`
-	Price       sdk.Dec `json:"price"`
+	OrderID     string  `json:"order_id" pb:"1"`
+	TradingPair string  `json:"trading_pair" pb:"2"`
+	Height      int64   `json:"height" pb:"3"`
+	Side        byte    `json:"side" pb:"4"`
+	Price       sdk.Dec `json:"price" pb:"5"`
 
 	// Del infos
-	DelReason string `json:"del_reason"`
+	DelReason string `json:"del_reason" pb:"6"`
 
 	// Fields of amount
-	UsedCommission    int64  `json:"used_commission"`
-	UsedFeatureFee    int64  `json:"used_feature_fee"`
-	RebateAmount      int64  `json:"rebate_amount"`
-	RebateRefereeAddr string `json:"rebate_referee_addr"`
-	LeftStock         int64  `json:"left_stock"`
-	RemainAmount      int64  `json:"remain_amount"`
-	DealStock         int64  `json:"deal_stock"`
-	DealMoney         int64  `json:"deal_money"`
+	UsedCommission    int64  `json:"used_commission" pb:"7"`
+	UsedFeatureFee    int64  `json:"used_feature_fee" pb:"8"`
+	RebateAmount      int64  `json:"rebate_amount" pb:"9"`
+	RebateRefereeAddr string `json:"rebate_referee_addr" pb:"10"`
+	LeftStock         int64  `json:"left_stock" pb:"11"`
+	RemainAmount      int64  `json:"remain_amount" pb:"12"`
+	DealStock         int64  `json:"deal_stock" pb:"13"`
+	DealMoney         int64  `json:"deal_money" pb:"14"`
 }
 
 type ModifyPricePrecisionInfo struct {
@@ -82,3 +116,11 @@ type ModifyPricePrecisionInfo struct {
 	OldPricePrecision byte   `json:"old_price_precision"`
 	NewPricePrecision byte   `json:"new_price_precision"`
 }
+
+type MarketStatusInfo struct {
+	TradingPair string `json:"trading_pair" pb:"1"`
+	OldStatus   byte   `json:"old_status" pb:"2"`
+	NewStatus   byte   `json:"new_status" pb:"3"`
+	Reason      string `json:"reason" pb:"4"`
+	Height      int64  `json:"height" pb:"5"`
+}
@@ -34,7 +34,7 @@ func TestMsgCreateOrder(t *testing.T) {
 
 	// Invalid OrderType
 	msg.TradingPair = "chs/cet"
-	msg.OrderType = LimitOrder + 1
+	msg.OrderType = StopMarketOrder + 1
 	err = msg.ValidateBasic()
 	require.EqualValues(t, CodeInvalidOrderType, err.Code())
 
@@ -78,6 +78,120 @@ func TestMsgCreateOrder(t *testing.T) {
 	msg.ExistBlocks = 10000
 	err = msg.ValidateBasic()
 	require.EqualValues(t, nil, err)
+
+	// Post-only and fill-or-kill
+	msg.TimeInForce = PostOnly
+	require.Nil(t, msg.ValidateBasic())
+	msg.TimeInForce = FOK
+	require.Nil(t, msg.ValidateBasic())
+	require.True(t, msg.IsImmediateOrder())
+	msg.TimeInForce = FOK + 1
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidTimeInForce, err.Code())
+	msg.TimeInForce = GTE
+
+	// Only stop orders have trigger price
+	msg.TriggerPrice = 10
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidTriggerPrice, err.Code())
+}
+
+func TestMsgCreateMarketAndStopOrder(t *testing.T) {
+	addr, failed := sdk.AccAddressFromHex("0123456789012345678901234567890123423456")
+	require.Nil(t, failed)
+	msg := MsgCreateOrder{
+		Sender:      addr,
+		TradingPair: "chs/cet",
+		OrderType:   MarketOrder,
+		Price:       10,
+		Quantity:    100,
+		Side:        BUY,
+		TimeInForce: IOC,
+	}
+
+	// Market orders have no price
+	err := msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidPrice, err.Code())
+	msg.Price = 0
+	require.Nil(t, msg.ValidateBasic())
+
+	// Market orders must be IOC orders
+	msg.TimeInForce = GTE
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidTimeInForce, err.Code())
+
+	msg.TimeInForce = FOK
+	require.Nil(t, msg.ValidateBasic())
+
+	// Stop orders need trigger price
+	msg.TimeInForce = IOC
+	msg.OrderType = StopMarketOrder
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidTriggerPrice, err.Code())
+	msg.TriggerPrice = -1
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidTriggerPrice, err.Code())
+	msg.TriggerPrice = 20
+	require.Nil(t, msg.ValidateBasic())
+
+	// Stop-limit orders need price
+	msg.OrderType = StopLimitOrder
+	msg.TimeInForce = GTE
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidPrice, err.Code())
+	msg.Price = 21
+	require.Nil(t, msg.ValidateBasic())
+	msg.TimeInForce = PostOnly
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidTimeInForce, err.Code())
+	msg.TimeInForce = GTE
+	require.True(t, msg.IsStopOrder())
+	require.False(t, msg.IsMarketOrder())
+
+	// Expire time must be positive, and a stop order may wait until it
+	msg.ExpireTime = -1
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidExpireTime, err.Code())
+	msg.ExpireTime = 1600000000
+	require.Nil(t, msg.ValidateBasic())
+	msg.OrderType = StopMarketOrder
+	msg.Price = 0
+	msg.TimeInForce = IOC
+	require.Nil(t, msg.ValidateBasic())
+
+	// An immediate order never rests in the order book, so it has no expire time
+	msg.OrderType = MarketOrder
+	msg.TriggerPrice = 0
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidExpireTime, err.Code())
+
+	// Self-trade prevention mode must be known
+	msg.ExpireTime = 0
+	msg.SelfTradePrevention = SelfTradeDecrementAndCancel
+	require.Nil(t, msg.ValidateBasic())
+	msg.SelfTradePrevention = SelfTradeDecrementAndCancel + 1
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidSelfTrade, err.Code())
+
+	// Only GTE limit orders can be iceberg orders, and they must hide some quantity
+	msg.SelfTradePrevention = SelfTradeAllowed
+	msg.DisplayQuantity = 30
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidDisplayQuantity, err.Code())
+	msg.OrderType = LimitOrder
+	msg.Price = 10
+	msg.TimeInForce = GTE
+	require.Nil(t, msg.ValidateBasic())
+	msg.TimeInForce = PostOnly
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidDisplayQuantity, err.Code())
+	msg.TimeInForce = GTE
+	msg.DisplayQuantity = msg.Quantity
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidDisplayQuantity, err.Code())
+	msg.DisplayQuantity = -1
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidDisplayQuantity, err.Code())
 }
 
 func TestMsgCancelOrder(t *testing.T) {
@@ -147,8 +261,14 @@ func TestMsgCreateTradingPair(t *testing.T) {
 	err = msg.ValidateBasic()
 	require.EqualValues(t, CodeInvalidPricePrecision, err.Code())
 
-	// Success
+	// Invalid matching algorithm
 	msg.PricePrecision = MaxTokenPricePrecision - 1
+	msg.MatchingAlgorithm = ProRataMatching + 1
+	err = msg.ValidateBasic()
+	require.EqualValues(t, CodeInvalidMatching, err.Code())
+
+	// Success
+	msg.MatchingAlgorithm = ContinuousMatching
 	err = msg.ValidateBasic()
 	require.EqualValues(t, nil, err)
 }
@@ -187,6 +307,88 @@ func TestMsgCancelTradingPair(t *testing.T) {
 	require.EqualValues(t, ErrInvalidCancelTime(), err)
 }
 
+func TestMsgCreateOrders(t *testing.T) {
+	addr, failed := sdk.AccAddressFromHex("0123456789012345678901234567890123423456")
+	require.Nil(t, failed)
+	item := OrderItem{
+		Identify:       1,
+		OrderType:      LimitOrder,
+		PricePrecision: 8,
+		Price:          100,
+		Quantity:       100,
+		Side:           BUY,
+		TimeInForce:    GTE,
+	}
+	msg := MsgCreateOrders{
+		Sender:      addr,
+		TradingPair: "btc/cet",
+		Orders:      []OrderItem{item},
+	}
+	require.Nil(t, msg.ValidateBasic())
+	require.Equal(t, "btc/cet", msg.GetCreateOrderMsgs()[0].TradingPair)
+
+	msg.Orders = append(msg.Orders, item)
+	require.EqualValues(t, CodeInvalidBatch, msg.ValidateBasic().Code())
+
+	msg.Orders[1].Identify = 2
+	msg.Orders[1].Side = 0
+	require.EqualValues(t, CodeInvalidTradeSide, msg.ValidateBasic().Code())
+
+	msg.Orders = nil
+	require.EqualValues(t, CodeInvalidBatch, msg.ValidateBasic().Code())
+
+	msg.Orders = make([]OrderItem, MaxOrdersInBatch+1)
+	require.EqualValues(t, CodeInvalidBatch, msg.ValidateBasic().Code())
+}
+
+func TestMsgCancelAllOrders(t *testing.T) {
+	addr, failed := sdk.AccAddressFromHex("0123456789012345678901234567890123423456")
+	require.Nil(t, failed)
+	msg := MsgCancelAllOrders{
+		Sender:      addr,
+		TradingPair: "btc/cet",
+	}
+	require.Nil(t, msg.ValidateBasic())
+
+	msg.Side = SELL
+	require.Nil(t, msg.ValidateBasic())
+
+	msg.Side = 3
+	require.EqualValues(t, CodeInvalidTradeSide, msg.ValidateBasic().Code())
+
+	msg.Side = BUY
+	msg.TradingPair = "btc"
+	require.EqualValues(t, CodeInvalidSymbol, msg.ValidateBasic().Code())
+}
+
+func TestMsgModifyOrder(t *testing.T) {
+	addr, failed := sdk.AccAddressFromHex("0123456789012345678901234567890123423456")
+	require.Nil(t, failed)
+	msg := MsgModifyOrder{
+		Sender:         addr,
+		OrderID:        addr.String() + "-1",
+		PricePrecision: 8,
+		Price:          100,
+		Quantity:       100,
+	}
+	require.Nil(t, msg.ValidateBasic())
+
+	msg.OrderID = addr.String() + "-abc"
+	require.EqualValues(t, CodeInvalidOrderID, msg.ValidateBasic().Code())
+
+	msg.OrderID = addr.String() + "-1"
+	msg.PricePrecision = MaxTokenPricePrecision + 1
+	require.EqualValues(t, CodeInvalidPricePrecision, msg.ValidateBasic().Code())
+
+	msg.PricePrecision = 8
+	msg.Price = 0
+	require.EqualValues(t, CodeInvalidPrice, msg.ValidateBasic().Code())
+
+	msg.Price = 100
+	msg.Quantity = 0
+	require.EqualValues(t, CodeInvalidOrderAmount, msg.ValidateBasic().Code())
+}
+
 func TestMsgCancelOrder_GetSignBytes(t *testing.T) {
 	tmp := time.Now()
 	msg := MsgCancelTradingPair{EffectiveTime: tmp.UnixNano()}
@@ -227,3 +429,54 @@ func TestMsgModifyPricePrecision(t *testing.T) {
 	err = msg.ValidateBasic()
 	require.EqualValues(t, ErrInvalidPricePrecision(msg.PricePrecision), err)
 }
+
+func TestMsgModifyMarketParams(t *testing.T) {
+	addr, failed := sdk.AccAddressFromHex("0123456789012345678901234567890123423456")
+	require.Nil(t, failed)
+	msg := MsgModifyMarketParams{
+		Sender:      addr,
+		TradingPair: "abc/cet",
+		Params:      MarketParams{MarketFeeRate: 20, MinOrderQuantity: 100, TickSize: 5},
+	}
+	require.Nil(t, msg.ValidateBasic())
+	require.Equal(t, "modify_market_params", msg.Type())
+
+	msg.Sender = []byte("superman")
+	require.EqualValues(t, ErrInvalidAddress(), msg.ValidateBasic())
+
+	msg.Sender = addr
+	msg.TradingPair = "abc-cet"
+	require.EqualValues(t, ErrInvalidSymbol(), msg.ValidateBasic())
+
+	msg.TradingPair = "abc/cet"
+	msg.Params.GTEOrderLifetime = -1
+	require.Equal(t, CodeInvalidMarketParams, msg.ValidateBasic().Code())
+}
+
+func TestMsgHaltAndResumeMarket(t *testing.T) {
+	addr, failed := sdk.AccAddressFromHex("0123456789012345678901234567890123423456")
+	require.Nil(t, failed)
+	msg := MsgHaltMarket{
+		Sender:      addr,
+		TradingPair: "abc/cet",
+		Status:      MarketCancelOnly,
+	}
+	require.Nil(t, msg.ValidateBasic())
+
+	msg.Status = MarketOpen
+	require.EqualValues(t, ErrInvalidMarketStatus(MarketOpen), msg.ValidateBasic())
+	msg.Status = MarketAuctionOnly + 1
+	require.EqualValues(t, ErrInvalidMarketStatus(msg.Status), msg.ValidateBasic())
+
+	msg.Status = MarketHalted
+	msg.TradingPair = "abc-cet"
+	require.EqualValues(t, ErrInvalidSymbol(), msg.ValidateBasic())
+
+	resume := MsgResumeMarket{
+		Sender:      addr,
+		TradingPair: "abc/cet",
+	}
+	require.Nil(t, resume.ValidateBasic())
+	resume.Sender = []byte("superman")
+	require.EqualValues(t, ErrInvalidAddress(), resume.ValidateBasic())
+}
@@ -19,14 +19,29 @@ type Order struct {
 	Height           int64          `json:"height"`
 	FrozenCommission int64          `json:"frozen_commission"` // DEX2
 	ExistBlocks      int64          `json:"exist_blocks"`
-	FrozenFeatureFee int64          `json:"frozen_feature_fee"`   // DEX2
-	FrozenFee        int64          `json:"frozen_fee,omitempty"` // DEX2: -> frozen_commission
+	FrozenFeatureFee int64          `json:"frozen_feature_fee"`       // DEX2
+	FrozenFee        int64          `json:"frozen_fee,omitempty"`     // DEX2: -> frozen_commission
+	TriggerPrice     sdk.Dec        `json:"trigger_price,omitempty"`  // only for stop orders
+	TriggerHeight    int64          `json:"trigger_height,omitempty"` // the height at which a stop order was triggered
+	ModifyHeight     int64          `json:"modify_height,omitempty"`  // the height at which the price was modified
+	ExpireTime       int64          `json:"expire_time,omitempty"`    // the unix time after which the order is cancelled
+
+	// what to do when the order would deal with another order of the same sender
+	SelfTradePrevention byte `json:"self_trade_prevention,omitempty"`
+
+	// An iceberg order only shows DisplayQuantity in the order book. VisibleStock is the left stock of the
+	// visible slice, which is replenished from the hidden part at RefreshHeight after it is dealt.
+	DisplayQuantity int64 `json:"display_quantity,omitempty"`
+	VisibleStock    int64 `json:"visible_stock,omitempty"`
+	RefreshHeight   int64 `json:"refresh_height,omitempty"`
 
 	// These fields will change when order was filled/canceled.
 	LeftStock int64 `json:"left_stock"`
 	Freeze    int64 `json:"freeze"`
 	DealStock int64 `json:"deal_stock"`
 	DealMoney int64 `json:"deal_money"`
+	// the part of DealStock which is dealt when the order rested in the order book before the matching block
+	MakerDealStock int64 `json:"maker_deal_stock,omitempty"`
 }
 
 func (or *Order) OrderID() string {
@@ -34,11 +49,117 @@ func (or *Order) OrderID() string {
 	return orderID
 }
 
+// IOC orders and FOK orders are removed at the end of the block they entered
+func (or *Order) IsImmediateOrder() bool {
+	return or.TimeInForce == IOC || or.TimeInForce == FOK
+}
+
+func (or *Order) IsStopOrder() bool {
+	return or.OrderType == StopLimitOrder || or.OrderType == StopMarketOrder
+}
+
+// A dormant order is a stop order which has not been triggered yet, it does not take part in matching
+func (or *Order) IsDormant() bool {
+	return or.IsStopOrder() && or.TriggerHeight == 0
+}
+
+// A buy stop order is triggered when the last executed price rises to its trigger price,
+// and a sell stop order is triggered when the last executed price falls to its trigger price
+func (or *Order) IsTriggeredBy(lastPrice sdk.Dec) bool {
+	if !or.IsDormant() || lastPrice.IsNil() || lastPrice.IsZero() {
+		return false
+	}
+	if or.Side == BUY {
+		return lastPrice.GTE(or.TriggerPrice)
+	}
+	return lastPrice.LTE(or.TriggerPrice)
+}
+
+// The orders with smaller priority heights are matched first at the same price. An order
+// loses its time priority when it is triggered, its price is modified or its visible slice is replenished.
+func (or *Order) PriorityHeight() int64 {
+	height := or.Height
+	if or.TriggerHeight > height {
+		height = or.TriggerHeight
+	}
+	if or.ModifyHeight > height {
+		height = or.ModifyHeight
+	}
+	if or.RefreshHeight > height {
+		height = or.RefreshHeight
+	}
+	return height
+}
+
+func (or *Order) IsIceberg() bool {
+	return or.DisplayQuantity > 0
+}
+
+// The left stock which can be seen in the order book and dealt in the current block
+func (or *Order) VisibleLeftStock() int64 {
+	if or.IsIceberg() && or.VisibleStock < or.LeftStock {
+		return or.VisibleStock
+	}
+	return or.LeftStock
+}
+
+// Replenish refills the visible slice of an iceberg order from its hidden part after the slice
+// is dealt, and the order loses its time priority. It returns false if nothing is refilled.
+func (or *Order) Replenish(height int64) bool {
+	if !or.IsIceberg() {
+		return false
+	}
+	visible := or.DisplayQuantity
+	if visible > or.LeftStock {
+		visible = or.LeftStock
+	}
+	if or.VisibleStock >= visible {
+		return false
+	}
+	or.VisibleStock = visible
+	or.RefreshHeight = height
+	return true
+}
+
+// PublicView returns a copy of the order which is shown in the order book, where the hidden part
+// of an iceberg order is removed from its quantity, left stock and frozen amounts
+func (or *Order) PublicView() *Order {
+	view := *or
+	hidden := or.LeftStock - or.VisibleLeftStock()
+	if hidden <= 0 {
+		return &view
+	}
+	view.Quantity -= hidden
+	view.LeftStock -= hidden
+	view.Freeze = sdk.NewInt(or.Freeze).MulRaw(view.LeftStock).QuoRaw(or.LeftStock).Int64()
+	view.FrozenCommission = sdk.NewInt(or.FrozenCommission).MulRaw(view.Quantity).QuoRaw(or.Quantity).Int64()
+	view.DisplayQuantity, view.VisibleStock = 0, 0
+	return &view
+}
+
 func (or *Order) CalActualOrderCommissionInt64(feeForZeroDeal int64) int64 {
+	return or.CalActualOrderCommissionWithRates(feeForZeroDeal, 0, 0, 0, 0)
+}
+
+// The commission is frozen at marketFeeRate, which is the highest rate. For the dealt stock, the part dealt
+// as maker is charged at makerRate and the other part is charged at takerRate. The lower rates do not bring
+// the commission below marketFeeMin, unless it is already below marketFeeMin at the frozen rate. If
+// marketFeeRate is zero, the dealt stock is charged at the frozen rate.
+func (or *Order) CalActualOrderCommissionWithRates(feeForZeroDeal, marketFeeMin, marketFeeRate, makerRate, takerRate int64) int64 {
 	actualFee := sdk.NewDec(feeForZeroDeal)
 	if or.DealStock != 0 {
 		actualFee = sdk.NewDec(or.DealStock).Mul(sdk.NewDec(or.FrozenCommission)).Quo(sdk.NewDec(or.Quantity))
 	}
+	if or.DealStock != 0 && marketFeeRate > 0 {
+		minFee := sdk.MinDec(actualFee, sdk.NewDec(marketFeeMin))
+		weightedStock := sdk.NewInt(or.MakerDealStock).MulRaw(makerRate).
+			Add(sdk.NewInt(or.DealStock - or.MakerDealStock).MulRaw(takerRate))
+		actualFee = sdk.NewDecFromInt(weightedStock).MulInt64(or.FrozenCommission).
+			QuoInt64(or.Quantity).QuoInt64(marketFeeRate)
+		if actualFee.LT(minFee) {
+			actualFee = minFee
+		}
+	}
 	moa := sdk.NewDec(MaxOrderAmount)
 	if actualFee.GT(moa) {
 		//should not reach this clause in production, add it for safety
@@ -47,8 +168,17 @@ func (or *Order) CalActualOrderCommissionInt64(feeForZeroDeal int64) int64 {
 	return actualFee.TruncateInt64()
 }
 
+// The feature fee is frozen for the blocks beyond freeTimeBlocks in ExistBlocks, and it is charged
+// in proportion to the blocks the order actually lived, which are fewer than ExistBlocks when the
+// order is filled, cancelled or reaches its expire time earlier.
 func (or *Order) CalActualOrderFeatureFeeInt64(ctx sdk.Context, freeTimeBlocks int64) int64 {
+	if or.FrozenFeatureFee == 0 || or.ExistBlocks <= freeTimeBlocks {
+		return 0
+	}
 	existTime := ctx.BlockHeight() - or.Height + 1
+	if existTime > or.ExistBlocks {
+		existTime = or.ExistBlocks
+	}
 	if existTime < freeTimeBlocks {
 		return 0
 	}
@@ -57,6 +57,21 @@ func TestOrderCommission(t *testing.T) {
 	order.FrozenCommission = MaxOrderAmount + 10
 	order.DealStock = 100000
 	require.Equal(t, MaxOrderAmount, order.CalActualOrderCommissionInt64(100))
+
+	// 20000 stock is dealt as maker and 30000 stock is dealt as taker
+	order.FrozenCommission = 10000
+	order.DealStock = 50000
+	order.MakerDealStock = 20000
+	require.Equal(t, int64(4000), order.CalActualOrderCommissionWithRates(100, 0, 10, 5, 10))
+	require.Equal(t, int64(3400), order.CalActualOrderCommissionWithRates(100, 0, 10, 5, 8))
+	require.Equal(t, int64(5000), order.CalActualOrderCommissionWithRates(100, 0, 0, 5, 8))
+	// the lower rates do not bring the commission below the minimum fee
+	require.Equal(t, int64(4500), order.CalActualOrderCommissionWithRates(100, 4500, 10, 5, 8))
+	require.Equal(t, int64(5000), order.CalActualOrderCommissionWithRates(100, 6000, 10, 5, 8))
+	require.Equal(t, int64(4000), order.CalActualOrderCommissionWithRates(100, 3000, 10, 5, 10))
+	order.DealStock = 0
+	order.MakerDealStock = 0
+	require.Equal(t, int64(100), order.CalActualOrderCommissionWithRates(100, 0, 10, 5, 8))
 }
 
 func TestOrder_CalActualOrderFeatureFeeInt64(t *testing.T) {
@@ -91,4 +106,53 @@ func TestOrder_CalActualOrderFeatureFeeInt64(t *testing.T) {
 	ctx = ctx.WithBlockHeight(20800)
 	fee = order.CalActualOrderFeatureFeeInt64(ctx, 100)
 	require.EqualValues(t, 100, fee)
+
+	// no feature fee is frozen when the order does not live beyond the free blocks
+	order.ExistBlocks = 100
+	fee = order.CalActualOrderFeatureFeeInt64(ctx, 100)
+	require.EqualValues(t, 0, fee)
+}
+
+func TestIcebergOrder(t *testing.T) {
+	order := Order{
+		Quantity:         100,
+		LeftStock:        70,
+		DealStock:        30,
+		Freeze:           700,
+		FrozenCommission: 50,
+		Price:            sdk.NewDec(10),
+		Side:             BUY,
+		Height:           10,
+		DisplayQuantity:  20,
+		VisibleStock:     5,
+	}
+	require.True(t, order.IsIceberg())
+	require.EqualValues(t, 5, order.VisibleLeftStock())
+
+	// only the visible slice and the dealt stock are shown
+	view := order.PublicView()
+	require.EqualValues(t, 35, view.Quantity)
+	require.EqualValues(t, 5, view.LeftStock)
+	require.EqualValues(t, 50, view.Freeze)
+	require.EqualValues(t, 17, view.FrozenCommission)
+	require.EqualValues(t, 30, view.DealStock)
+	require.False(t, view.IsIceberg())
+	require.EqualValues(t, 70, order.LeftStock)
+
+	// the replenished order loses its time priority
+	require.True(t, order.Replenish(12))
+	require.EqualValues(t, 20, order.VisibleStock)
+	require.EqualValues(t, 12, order.PriorityHeight())
+	require.False(t, order.Replenish(13))
+
+	// the visible slice is no larger than the left stock
+	order.LeftStock, order.VisibleStock = 8, 0
+	require.True(t, order.Replenish(14))
+	require.EqualValues(t, 8, order.VisibleStock)
+	require.EqualValues(t, 8, order.PublicView().LeftStock)
+
+	order.DisplayQuantity = 0
+	require.False(t, order.IsIceberg())
+	require.False(t, order.Replenish(15))
+	require.EqualValues(t, 8, order.VisibleLeftStock())
 }
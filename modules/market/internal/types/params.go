@@ -15,9 +15,23 @@ const (
 	DefaultMaxExecutedPriceChangeRatio = 25
 	DefaultMarketFeeRatePrecision      = 4
 	DefaultMarketFeeRate               = 10
+	DefaultMakerFeeRate                = 5 // the makers who provide liquidity are charged at a lower rate on new chains
 	DefaultMarketFeeMin                = 1000000
 	DefaultFeeForZeroDeal              = 1000000
 	DefaultMarketMinExpiredTime        = 7 * 24 * time.Hour
+	DefaultCandleRetention             = 1440 // the 24h ticker is merged from the minute candles of one day
+
+	// the upper bounds of the params which can be overridden by a single market
+	DefaultMaxExecutedPriceChangeRatioLimit = 50
+	DefaultMarketFeeRateLimit               = 100
+	DefaultGTEOrderLifetimeLimit            = 2000000
+
+	// the circuit breaker is disabled by default
+	DefaultCircuitBreakerRatio  = 0
+	DefaultCircuitBreakerBlocks = 600
+
+	// the trade history is not kept by default
+	DefaultTradeRetention = 0
 )
 
 var (
@@ -29,17 +43,68 @@ var (
 	KeyMarketFeeRate               = []byte("MarketFeeRate")
 	KeyMarketFeeMin                = []byte("MarketFeeMin")
 	KeyFeeForZeroDeal              = []byte("FeeForZeroDeal")
+	KeyCandleRetention             = []byte("CandleRetention")
+	KeyMakerFeeRate                = []byte("MakerFeeRate")
+	KeyFeeTiers                    = []byte("FeeTiers")
+
+	KeyMaxExecutedPriceChangeRatioLimit = []byte("MaxExecutedPriceChangeRatioLimit")
+	KeyMarketFeeRateLimit               = []byte("MarketFeeRateLimit")
+	KeyGTEOrderLifetimeLimit            = []byte("GTEOrderLifetimeLimit")
+
+	KeyCircuitBreakerRatio  = []byte("CircuitBreakerRatio")
+	KeyCircuitBreakerBlocks = []byte("CircuitBreakerBlocks")
+
+	KeyTradeRetention = []byte("TradeRetention")
 )
 
+// the params added after the chain starts are not in the store of a running chain, so they are
+// read with their defaults until they are changed
+var upgradedParamKeys = [][]byte{
+	KeyCandleRetention,
+	KeyMakerFeeRate,
+	KeyFeeTiers,
+	KeyMaxExecutedPriceChangeRatioLimit,
+	KeyMarketFeeRateLimit,
+	KeyGTEOrderLifetimeLimit,
+	KeyCircuitBreakerRatio,
+	KeyCircuitBreakerBlocks,
+	KeyTradeRetention,
+}
+
+// IsUpgradedParamKey returns whether a param may be missing from the store
+func IsUpgradedParamKey(key []byte) bool {
+	for _, k := range upgradedParamKeys {
+		if bytes.Equal(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
 type Params struct {
-	CreateMarketFee             int64 `json:"create_market_fee"`
-	MarketMinExpiredTime        int64 `json:"market_min_expired_time"`
-	GTEOrderLifetime            int64 `json:"gte_order_lifetime"`
-	GTEOrderFeatureFeeByBlocks  int64 `json:"gte_order_feature_fee_by_blocks"`
-	MaxExecutedPriceChangeRatio int64 `json:"max_executed_price_change_ratio"`
-	MarketFeeRate               int64 `json:"market_fee_rate"`
-	MarketFeeMin                int64 `json:"market_fee_min"`
-	FeeForZeroDeal              int64 `json:"fee_for_zero_deal"`
+	CreateMarketFee             int64     `json:"create_market_fee"`
+	MarketMinExpiredTime        int64     `json:"market_min_expired_time"`
+	GTEOrderLifetime            int64     `json:"gte_order_lifetime"`
+	GTEOrderFeatureFeeByBlocks  int64     `json:"gte_order_feature_fee_by_blocks"`
+	MaxExecutedPriceChangeRatio int64     `json:"max_executed_price_change_ratio"`
+	MarketFeeRate               int64     `json:"market_fee_rate"`
+	MarketFeeMin                int64     `json:"market_fee_min"`
+	FeeForZeroDeal              int64     `json:"fee_for_zero_deal"`
+	CandleRetention             int64     `json:"candle_retention"`
+	MakerFeeRate                int64     `json:"maker_fee_rate"`
+	FeeTiers                    []FeeTier `json:"fee_tiers"`
+
+	MaxExecutedPriceChangeRatioLimit int64 `json:"max_executed_price_change_ratio_limit"`
+	MarketFeeRateLimit               int64 `json:"market_fee_rate_limit"`
+	GTEOrderLifetimeLimit            int64 `json:"gte_order_lifetime_limit"`
+
+	// a market is halted if a match moves the price by more than CircuitBreakerRatio percent
+	// within CircuitBreakerBlocks blocks, and zero ratio disables the circuit breaker
+	CircuitBreakerRatio  int64 `json:"circuit_breaker_ratio"`
+	CircuitBreakerBlocks int64 `json:"circuit_breaker_blocks"`
+
+	// the trades in the last TradeRetention blocks are kept, and zero disables the trade history
+	TradeRetention int64 `json:"trade_retention"`
 }
 
 // ParamKeyTable for market module
@@ -58,6 +123,15 @@ func DefaultParams() Params {
 		DefaultMarketFeeRate,
 		DefaultMarketFeeMin,
 		DefaultFeeForZeroDeal,
+		DefaultCandleRetention,
+		DefaultMarketFeeRate,
+		nil,
+		DefaultMaxExecutedPriceChangeRatioLimit,
+		DefaultMarketFeeRateLimit,
+		DefaultGTEOrderLifetimeLimit,
+		DefaultCircuitBreakerRatio,
+		DefaultCircuitBreakerBlocks,
+		DefaultTradeRetention,
 	}
 }
 
@@ -74,6 +148,15 @@ func (p *Params) ParamSetPairs() params.ParamSetPairs {
 		{Key: KeyMarketFeeRate, Value: &p.MarketFeeRate},
 		{Key: KeyMarketFeeMin, Value: &p.MarketFeeMin},
 		{Key: KeyFeeForZeroDeal, Value: &p.FeeForZeroDeal},
+		{Key: KeyCandleRetention, Value: &p.CandleRetention},
+		{Key: KeyMakerFeeRate, Value: &p.MakerFeeRate},
+		{Key: KeyFeeTiers, Value: &p.FeeTiers},
+		{Key: KeyMaxExecutedPriceChangeRatioLimit, Value: &p.MaxExecutedPriceChangeRatioLimit},
+		{Key: KeyMarketFeeRateLimit, Value: &p.MarketFeeRateLimit},
+		{Key: KeyGTEOrderLifetimeLimit, Value: &p.GTEOrderLifetimeLimit},
+		{Key: KeyCircuitBreakerRatio, Value: &p.CircuitBreakerRatio},
+		{Key: KeyCircuitBreakerBlocks, Value: &p.CircuitBreakerBlocks},
+		{Key: KeyTradeRetention, Value: &p.TradeRetention},
 	}
 }
 
@@ -94,7 +177,24 @@ func (p *Params) ValidateGenesis() error {
 			p.MarketFeeRate, p.MarketFeeMin, p.FeeForZeroDeal, p.GTEOrderLifetime,
 			p.GTEOrderFeatureFeeByBlocks)
 	}
-	return nil
+	if p.CandleRetention < DefaultCandleRetention {
+		return fmt.Errorf("%s must not be less than %d, is %d", KeyCandleRetention,
+			DefaultCandleRetention, p.CandleRetention)
+	}
+	if p.MaxExecutedPriceChangeRatioLimit < p.MaxExecutedPriceChangeRatio ||
+		p.MarketFeeRateLimit < p.MarketFeeRate || p.GTEOrderLifetimeLimit < p.GTEOrderLifetime {
+		return fmt.Errorf("the limits of market params can not be less than the global params, "+
+			"MaxExecutedPriceChangeRatioLimit : %d, MarketFeeRateLimit : %d, GTEOrderLifetimeLimit : %d",
+			p.MaxExecutedPriceChangeRatioLimit, p.MarketFeeRateLimit, p.GTEOrderLifetimeLimit)
+	}
+	if p.CircuitBreakerRatio < 0 || (p.CircuitBreakerRatio != 0 && p.CircuitBreakerBlocks <= 0) {
+		return fmt.Errorf("%s : %d must not be negative, and %s : %d must be positive when the circuit breaker is enabled",
+			KeyCircuitBreakerRatio, p.CircuitBreakerRatio, KeyCircuitBreakerBlocks, p.CircuitBreakerBlocks)
+	}
+	if p.TradeRetention < 0 {
+		return fmt.Errorf("%s must not be negative, is %d", KeyTradeRetention, p.TradeRetention)
+	}
+	return p.validateFeeRates()
 }
 
 // Equal returns a boolean determining if two Params types are identical.
@@ -106,14 +206,23 @@ func (p Params) Equal(p2 Params) bool {
 
 func (p Params) String() string {
 	return fmt.Sprintf(`Market Params:
-  CreateMarketFee:             %d
-  MarketMinExpiredTime:        %d
-  GTEOrderLifetime:            %d
-  GTEOrderFeatureFeeByBlocks:  %d
-  MaxExecutedPriceChangeRatio: %d
-  MarketFeeRate:               %d
-  MarketFeeMin:                %d
-  FeeForZeroDeal:              %d`,
+  CreateMarketFee:                  %d
+  MarketMinExpiredTime:             %d
+  GTEOrderLifetime:                 %d
+  GTEOrderFeatureFeeByBlocks:       %d
+  MaxExecutedPriceChangeRatio:      %d
+  MarketFeeRate:                    %d
+  MarketFeeMin:                     %d
+  FeeForZeroDeal:                   %d
+  CandleRetention:                  %d
+  MakerFeeRate:                     %d
+  FeeTiers:                         %v
+  MaxExecutedPriceChangeRatioLimit: %d
+  MarketFeeRateLimit:               %d
+  GTEOrderLifetimeLimit:            %d
+  CircuitBreakerRatio:              %d
+  CircuitBreakerBlocks:             %d
+  TradeRetention:                   %d`,
 		p.CreateMarketFee,
 		p.MarketMinExpiredTime,
 		p.GTEOrderLifetime,
@@ -121,5 +230,14 @@ func (p Params) String() string {
 		p.MaxExecutedPriceChangeRatio,
 		p.MarketFeeRate,
 		p.MarketFeeMin,
-		p.FeeForZeroDeal)
+		p.FeeForZeroDeal,
+		p.CandleRetention,
+		p.MakerFeeRate,
+		p.FeeTiers,
+		p.MaxExecutedPriceChangeRatioLimit,
+		p.MarketFeeRateLimit,
+		p.GTEOrderLifetimeLimit,
+		p.CircuitBreakerRatio,
+		p.CircuitBreakerBlocks,
+		p.TradeRetention)
 }
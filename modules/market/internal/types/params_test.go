@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 func TestParams(t *testing.T) {
@@ -16,6 +18,11 @@ func TestParams(t *testing.T) {
 		MarketFeeRate:               100,
 		MarketFeeMin:                100,
 		FeeForZeroDeal:              100,
+		CandleRetention:             DefaultCandleRetention,
+
+		MaxExecutedPriceChangeRatioLimit: 100,
+		MarketFeeRateLimit:               100,
+		GTEOrderLifetimeLimit:            100,
 	}
 	require.Equal(t, nil, params.ValidateGenesis())
 	params1 := params
@@ -39,4 +46,63 @@ func TestParams(t *testing.T) {
 	params1 = params
 	params1.FeeForZeroDeal = -1
 	require.NotNil(t, params1.ValidateGenesis())
+	params1 = params
+	params1.CandleRetention = 0
+	require.NotNil(t, params1.ValidateGenesis())
+	// the 24h ticker needs the minute candles of one day
+	params1.CandleRetention = DefaultCandleRetention - 1
+	require.NotNil(t, params1.ValidateGenesis())
+	params1 = params
+	params1.MakerFeeRate = params.MarketFeeRate + 1
+	require.NotNil(t, params1.ValidateGenesis())
+	params1 = params
+	params1.FeeTiers = []FeeTier{{MinVolume: 1000, MakerFeeRate: 5, TakerFeeRate: 101}}
+	require.NotNil(t, params1.ValidateGenesis())
+	params1 = params
+	params1.FeeTiers = []FeeTier{{MinVolume: 1000, MakerFeeRate: 5, TakerFeeRate: 9}, {MinVolume: 1000, MakerFeeRate: 3, TakerFeeRate: 8}}
+	require.NotNil(t, params1.ValidateGenesis())
+	params1 = params
+	params1.MarketFeeRateLimit = params.MarketFeeRate - 1
+	require.NotNil(t, params1.ValidateGenesis())
+	params1 = params
+	params1.GTEOrderLifetimeLimit = params.GTEOrderLifetime - 1
+	require.NotNil(t, params1.ValidateGenesis())
+	params1 = params
+	params1.CircuitBreakerRatio = -1
+	require.NotNil(t, params1.ValidateGenesis())
+	params1 = params
+	params1.CircuitBreakerRatio = 50
+	require.NotNil(t, params1.ValidateGenesis())
+	params1.CircuitBreakerBlocks = 100
+	require.Nil(t, params1.ValidateGenesis())
+	params1 = params
+	params1.TradeRetention = -1
+	require.NotNil(t, params1.ValidateGenesis())
+}
+
+func TestGetFeeRates(t *testing.T) {
+	params := DefaultParams()
+	params.MakerFeeRate = DefaultMakerFeeRate
+	params.FeeTiers = []FeeTier{
+		{MinVolume: 1000, MakerFeeRate: 4, TakerFeeRate: 9},
+		{MinVolume: 5000, MakerFeeRate: 2, TakerFeeRate: 8},
+	}
+	require.Nil(t, params.ValidateGenesis())
+	tests := []struct {
+		volume    int64
+		makerRate int64
+		takerRate int64
+	}{
+		{0, DefaultMakerFeeRate, DefaultMarketFeeRate},
+		{999, DefaultMakerFeeRate, DefaultMarketFeeRate},
+		{1000, 4, 9},
+		{4999, 4, 9},
+		{5000, 2, 8},
+		{1e15, 2, 8},
+	}
+	for _, tc := range tests {
+		makerRate, takerRate := params.GetFeeRates(sdk.NewInt(tc.volume))
+		require.Equal(t, tc.makerRate, makerRate)
+		require.Equal(t, tc.takerRate, takerRate)
+	}
 }
@@ -0,0 +1,34 @@
+package types
+
+// The self-trade prevention modes, which apply when two orders of the same sender would deal with
+// each other. The mode of the newer order is used, and its zero value allows self-trades.
+const (
+	SelfTradeAllowed byte = 0
+	// cancel the left amount of the newer order
+	SelfTradeCancelNewest byte = 1
+	// cancel the left amount of the older order
+	SelfTradeCancelOldest byte = 2
+	// cancel the left amounts of both orders
+	SelfTradeCancelBoth byte = 3
+	// decrease both orders by the smaller left amount, so the smaller one is cancelled
+	SelfTradeDecrementAndCancel byte = 4
+)
+
+var selfTradePreventionNames = map[string]byte{
+	"none":                 SelfTradeAllowed,
+	"cancel-newest":        SelfTradeCancelNewest,
+	"cancel-oldest":        SelfTradeCancelOldest,
+	"cancel-both":          SelfTradeCancelBoth,
+	"decrement-and-cancel": SelfTradeDecrementAndCancel,
+}
+
+// ParseSelfTradePrevention returns the mode with the given name, i.e. none, cancel-newest,
+// cancel-oldest, cancel-both or decrement-and-cancel
+func ParseSelfTradePrevention(name string) (byte, bool) {
+	mode, ok := selfTradePreventionNames[name]
+	return mode, ok
+}
+
+func IsValidSelfTradePrevention(mode byte) bool {
+	return mode <= SelfTradeDecrementAndCancel
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Trade records one fill between a buy order and a sell order. Sequence is the index of
+// the fill among the fills of the same market in the same block.
+type Trade struct {
+	TradingPair string         `json:"trading_pair"`
+	Height      int64          `json:"height"`
+	Time        int64          `json:"time"`
+	Sequence    int64          `json:"sequence"`
+	Price       sdk.Dec        `json:"price"`
+	Amount      int64          `json:"amount"`
+	Money       int64          `json:"money"`
+	BuyOrderID  string         `json:"buy_order_id"`
+	SellOrderID string         `json:"sell_order_id"`
+	Buyer       sdk.AccAddress `json:"buyer"`
+	Seller      sdk.AccAddress `json:"seller"`
+}
+
+func NewTrade(ctx sdk.Context, sequence int64, buyer, seller *Order, price sdk.Dec, amount, money int64) *Trade {
+	return &Trade{
+		TradingPair: buyer.TradingPair,
+		Height:      ctx.BlockHeight(),
+		Time:        ctx.BlockHeader().Time.Unix(),
+		Sequence:    sequence,
+		Price:       price,
+		Amount:      amount,
+		Money:       money,
+		BuyOrderID:  buyer.OrderID(),
+		SellOrderID: seller.OrderID(),
+		Buyer:       buyer.Sender,
+		Seller:      seller.Sender,
+	}
+}
@@ -0,0 +1,226 @@
+package match
+
+import (
+	"bytes"
+	"math"
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+)
+
+// Algorithm matches the bid list against the ask list. Both lists are sorted by price-time priority,
+// and the post-only and fill-or-kill orders which can not be satisfied have been removed from them.
+type Algorithm interface {
+	MatchSortedLists(highPrice, midPrice, lowPrice sdk.Dec, bidList []OrderForTrade, askList []OrderForTrade)
+}
+
+var _ Algorithm = CallAuction{}
+var _ Algorithm = Continuous{}
+var _ Algorithm = ProRata{}
+
+// GetAlgorithm returns the algorithm recorded in MarketInfo, the call auction is used for unknown values
+func GetAlgorithm(algorithm byte) Algorithm {
+	switch algorithm {
+	case types.ContinuousMatching:
+		return Continuous{}
+	case types.ProRataMatching:
+		return ProRata{}
+	default:
+		return CallAuction{}
+	}
+}
+
+// CallAuction executes all the crossed orders at the price which maximizes the executed amount
+type CallAuction struct{}
+
+func (CallAuction) MatchSortedLists(highPrice, midPrice, lowPrice sdk.Dec, bidList []OrderForTrade, askList []OrderForTrade) {
+	matchSortedLists(highPrice, midPrice, lowPrice, bidList, askList)
+}
+
+// Continuous replays the orders in the sequence of their arrival. Each order takes liquidity from
+// the earlier orders by price-time priority, at the price of the maker, and then rests in the order book.
+// The deals are kept in [lowPrice, highPrice] like the call auction: a maker price out of the range is
+// moved into it if the taker accepts that price, and otherwise the orders are left crossed.
+type Continuous struct{}
+
+func (Continuous) MatchSortedLists(highPrice, midPrice, lowPrice sdk.Dec, bidList []OrderForTrade, askList []OrderForTrade) {
+	orders := make([]OrderForTrade, 0, len(bidList)+len(askList))
+	orders = append(append(orders, bidList...), askList...)
+	sort.Slice(orders, func(i, j int) bool {
+		return arriveEarlier(orders[i], orders[j])
+	})
+	var restingBids, restingAsks []OrderForTrade
+	for _, order := range orders {
+		if order.GetSide() == types.BID {
+			restingAsks = takeLiquidity(order, restingAsks, highPrice, midPrice, lowPrice)
+			restingBids = insertOrder(restingBids, order)
+		} else {
+			restingBids = takeLiquidity(order, restingBids, highPrice, midPrice, lowPrice)
+			restingAsks = insertOrder(restingAsks, order)
+		}
+	}
+}
+
+// return true if a entered the order book earlier than b
+func arriveEarlier(a, b OrderForTrade) bool {
+	if a.GetHeight() != b.GetHeight() {
+		return a.GetHeight() < b.GetHeight()
+	}
+	return bytes.Compare(a.GetHash(), b.GetHash()) < 0
+}
+
+// the taker deals with the sorted makers until they do not cross, and the left makers are returned.
+// The makers after one which can not deal in the price range can not either, as their prices are worse.
+func takeLiquidity(taker OrderForTrade, makers []OrderForTrade, highPrice, midPrice, lowPrice sdk.Dec) []OrderForTrade {
+	for len(makers) != 0 && taker.GetAmount() != 0 {
+		maker := makers[0]
+		if (taker.GetSide() == types.BID && taker.GetPrice().LT(maker.GetPrice())) ||
+			(taker.GetSide() == types.ASK && taker.GetPrice().GT(maker.GetPrice())) {
+			break
+		}
+		price := maker.GetPrice()
+		if !midPrice.IsZero() {
+			if price.GT(highPrice) {
+				price = highPrice
+			} else if price.LT(lowPrice) {
+				price = lowPrice
+			}
+			bidPrice, askPrice := taker.GetPrice(), maker.GetPrice()
+			if taker.GetSide() == types.ASK {
+				bidPrice, askPrice = askPrice, bidPrice
+			}
+			if price.GT(bidPrice) || price.LT(askPrice) {
+				break
+			}
+		}
+		if !preventSelfTrade(taker, maker) {
+			amount := maker.GetAmount()
+			if taker.GetAmount() < amount {
+				amount = taker.GetAmount()
+			}
+			taker.Deal(maker, amount, price)
+		}
+		if maker.GetAmount() == 0 {
+			makers = makers[1:]
+		}
+	}
+	return makers
+}
+
+// insert an order with left amount into the sorted list
+func insertOrder(orderList []OrderForTrade, order OrderForTrade) []OrderForTrade {
+	if order.GetAmount() == 0 {
+		return orderList
+	}
+	i := sort.Search(len(orderList), func(i int) bool {
+		return precede(order, orderList[i])
+	})
+	orderList = append(orderList, nil)
+	copy(orderList[i+1:], orderList[i:])
+	orderList[i] = order
+	return orderList
+}
+
+// ProRata executes the crossed orders at the same price as the call auction. The orders with better
+// prices are fully filled, and the orders at the marginal price are filled in proportion to their
+// amounts, the remainder of rounding is given to the earlier orders one by one.
+type ProRata struct{}
+
+func (ProRata) MatchSortedLists(highPrice, midPrice, lowPrice sdk.Dec, bidList []OrderForTrade, askList []OrderForTrade) {
+	bidList, askList = removeFilled(bidList), removeFilled(askList)
+	for len(bidList) != 0 && len(askList) != 0 && askList[0].GetPrice().LTE(bidList[0].GetPrice()) {
+		price := GetExecutionPrice(highPrice, midPrice, lowPrice, append(bidList, askList...))
+		bids := bidList[:sort.Search(len(bidList), func(i int) bool {
+			return bidList[i].GetPrice().LT(price)
+		})]
+		asks := askList[:sort.Search(len(askList), func(i int) bool {
+			return askList[i].GetPrice().GT(price)
+		})]
+		volume := sdk.MinInt(sumAmount(bids), sumAmount(asks))
+		if volume.IsZero() {
+			// should not reach here, the execution price always has some amount to execute
+			break
+		}
+		if volume.GT(sdk.NewInt(math.MaxInt64)) {
+			// the left amount will be executed in the next round
+			volume = sdk.NewInt(math.MaxInt64)
+		}
+		bidFills := allocate(bids, volume.Int64())
+		askFills := allocate(asks, volume.Int64())
+		for i, j := 0, 0; i < len(bids) && j < len(asks); {
+			if bidFills[i] == 0 {
+				i++
+				continue
+			}
+			if askFills[j] == 0 {
+				j++
+				continue
+			}
+			if preventSelfTrade(bids[i], asks[j]) {
+				// the allocation is stale, so the left orders are allocated again in the next round
+				break
+			}
+			amount := bidFills[i]
+			if askFills[j] < amount {
+				amount = askFills[j]
+			}
+			bids[i].Deal(asks[j], amount, price)
+			bidFills[i] -= amount
+			askFills[j] -= amount
+		}
+		bidList, askList = removeFilled(bidList), removeFilled(askList)
+	}
+}
+
+func removeFilled(orderList []OrderForTrade) []OrderForTrade {
+	result := make([]OrderForTrade, 0, len(orderList))
+	for _, order := range orderList {
+		if order.GetAmount() != 0 {
+			result = append(result, order)
+		}
+	}
+	return result
+}
+
+func sumAmount(orderList []OrderForTrade) sdk.Int {
+	sum := sdk.ZeroInt()
+	for _, order := range orderList {
+		sum = sum.AddRaw(order.GetAmount())
+	}
+	return sum
+}
+
+// allocate the volume to the sorted orders, price level by price level
+func allocate(orderList []OrderForTrade, volume int64) []int64 {
+	fills := make([]int64, len(orderList))
+	for begin := 0; begin < len(orderList) && volume != 0; {
+		end := begin
+		total := sdk.ZeroInt()
+		for end < len(orderList) && orderList[end].GetPrice().Equal(orderList[begin].GetPrice()) {
+			total = total.AddRaw(orderList[end].GetAmount())
+			end++
+		}
+		if total.LTE(sdk.NewInt(volume)) {
+			for i := begin; i < end; i++ {
+				fills[i] = orderList[i].GetAmount()
+			}
+			volume -= total.Int64()
+		} else {
+			allocated := int64(0)
+			for i := begin; i < end; i++ {
+				fills[i] = sdk.NewInt(orderList[i].GetAmount()).MulRaw(volume).Quo(total).Int64()
+				allocated += fills[i]
+			}
+			// every order is rounded down by less than one, so the remainder is less than the order count
+			for i := begin; allocated < volume; i++ {
+				fills[i]++
+				allocated++
+			}
+			volume = 0
+		}
+		begin = end
+	}
+	return fills
+}
@@ -21,18 +21,37 @@ type OrderForTrade interface {
 	GetHash() []byte
 	GetSide() int
 	GetOwner() Account
+	GetTimeInForce() int64
 	Deal(otherSide OrderForTrade, amount int64, price sdk.Dec)
+	// Reject is called when the order is excluded from matching, because its time-in-force can not be satisfied
+	Reject()
+	GetSelfTradePrevention() byte
+	// CancelSelfTrade is called to cancel some left amount of the order instead of dealing with an order
+	// of the same owner, and the order is cancelled when all its left amount is cancelled
+	CancelSelfTrade(amount int64)
 	String() string
 }
 
-// match bid order list against ask order list
+// match bid order list against ask order list, with the periodic call auction
 func Match(highPrice, midPrice, lowPrice sdk.Dec, bidList []OrderForTrade, askList []OrderForTrade) {
+	MatchWith(CallAuction{}, highPrice, midPrice, lowPrice, bidList, askList)
+}
+
+// match bid order list against ask order list, with the specified algorithm
+func MatchWith(algorithm Algorithm, highPrice, midPrice, lowPrice sdk.Dec, bidList []OrderForTrade, askList []OrderForTrade) {
 	sort.Slice(bidList, func(i, j int) bool {
 		return precede(bidList[i], bidList[j])
 	})
 	sort.Slice(askList, func(i, j int) bool {
 		return precede(askList[i], askList[j])
 	})
+	bidList, askList = rejectPostOnlyOrders(bidList, askList)
+	bidList, askList = rejectFillOrKillOrders(algorithm, highPrice, midPrice, lowPrice, bidList, askList)
+	algorithm.MatchSortedLists(highPrice, midPrice, lowPrice, bidList, askList)
+}
+
+// match the sorted bid order list against the sorted ask order list, with the call auction
+func matchSortedLists(highPrice, midPrice, lowPrice sdk.Dec, bidList []OrderForTrade, askList []OrderForTrade) {
 	//for _, order := range bidList {
 	//	fmt.Printf("bid %s\n", order.String())
 	//}
@@ -50,6 +69,121 @@ func Match(highPrice, midPrice, lowPrice sdk.Dec, bidList []OrderForTrade, askLi
 	}
 }
 
+// A post-only order is rejected if it crosses an order which entered the order book no later than it,
+// because it would take liquidity instead of providing liquidity.
+func rejectPostOnlyOrders(bidList []OrderForTrade, askList []OrderForTrade) ([]OrderForTrade, []OrderForTrade) {
+	rejected := make(map[OrderForTrade]bool)
+	markCrossing(bidList, askList, rejected)
+	markCrossing(askList, bidList, rejected)
+	if len(rejected) == 0 {
+		return bidList, askList
+	}
+	return removeRejected(bidList, rejected), removeRejected(askList, rejected)
+}
+
+// mark the post-only orders in orderList which cross some order in the sorted otherList
+func markCrossing(orderList []OrderForTrade, otherList []OrderForTrade, rejected map[OrderForTrade]bool) {
+	// minHeights[i] is the lowest height among otherList[0:i+1], whose prices are better and better
+	minHeights := make([]int64, len(otherList))
+	for i, other := range otherList {
+		minHeights[i] = other.GetHeight()
+		if i > 0 && minHeights[i-1] < minHeights[i] {
+			minHeights[i] = minHeights[i-1]
+		}
+	}
+	for _, order := range orderList {
+		if order.GetTimeInForce() != types.PostOnly {
+			continue
+		}
+		// count the orders in otherList which cross this order
+		n := sort.Search(len(otherList), func(i int) bool {
+			if order.GetSide() == types.BID {
+				return otherList[i].GetPrice().GT(order.GetPrice())
+			}
+			return otherList[i].GetPrice().LT(order.GetPrice())
+		})
+		if n > 0 && minHeights[n-1] <= order.GetHeight() {
+			rejected[order] = true
+		}
+	}
+}
+
+// A fill-or-kill order must be fully filled, or it is rejected. The matching is simulated repeatedly,
+// and in each round the first fill-or-kill order of each side which is not fully filled is rejected,
+// until all the remained fill-or-kill orders can be fully filled.
+func rejectFillOrKillOrders(algorithm Algorithm, highPrice, midPrice, lowPrice sdk.Dec,
+	bidList []OrderForTrade, askList []OrderForTrade) ([]OrderForTrade, []OrderForTrade) {
+	if !hasFillOrKillOrder(bidList) && !hasFillOrKillOrder(askList) {
+		return bidList, askList
+	}
+	for {
+		rejected := make(map[OrderForTrade]bool)
+		simBidList, simAskList := newSimulatedList(bidList), newSimulatedList(askList)
+		algorithm.MatchSortedLists(highPrice, midPrice, lowPrice, simBidList, simAskList)
+		for _, simList := range [][]OrderForTrade{simBidList, simAskList} {
+			for _, simOrder := range simList {
+				order := simOrder.(*simulatedOrder)
+				if order.GetTimeInForce() == types.FOK && order.amount != 0 {
+					rejected[order.OrderForTrade] = true
+					break
+				}
+			}
+		}
+		if len(rejected) == 0 {
+			return bidList, askList
+		}
+		bidList, askList = removeRejected(bidList, rejected), removeRejected(askList, rejected)
+	}
+}
+
+func hasFillOrKillOrder(orderList []OrderForTrade) bool {
+	for _, order := range orderList {
+		if order.GetTimeInForce() == types.FOK {
+			return true
+		}
+	}
+	return false
+}
+
+func removeRejected(orderList []OrderForTrade, rejected map[OrderForTrade]bool) []OrderForTrade {
+	result := make([]OrderForTrade, 0, len(orderList))
+	for _, order := range orderList {
+		if rejected[order] {
+			order.Reject()
+		} else {
+			result = append(result, order)
+		}
+	}
+	return result
+}
+
+// simulatedOrder only records the left amount when it is dealt
+type simulatedOrder struct {
+	OrderForTrade
+	amount int64
+}
+
+func newSimulatedList(orderList []OrderForTrade) []OrderForTrade {
+	result := make([]OrderForTrade, len(orderList))
+	for i, order := range orderList {
+		result[i] = &simulatedOrder{OrderForTrade: order, amount: order.GetAmount()}
+	}
+	return result
+}
+
+func (order *simulatedOrder) GetAmount() int64 {
+	return order.amount
+}
+
+func (order *simulatedOrder) Deal(otherSide OrderForTrade, amount int64, price sdk.Dec) {
+	order.amount -= amount
+	otherSide.(*simulatedOrder).amount -= amount
+}
+
+func (order *simulatedOrder) CancelSelfTrade(amount int64) {
+	order.amount -= amount
+}
+
 // return true if a should precede b in a sorted list, i.e. index of a is smaller
 func precede(a, b OrderForTrade) bool {
 	if (a.GetSide() == types.ASK && a.GetPrice().LT(b.GetPrice())) || //for ask, lower price has priority
@@ -106,11 +240,13 @@ func ExecuteOrder(price sdk.Dec, currOrder OrderForTrade, orderList []OrderForTr
 				break
 			}
 		}
-		minAmount := otherSide.GetAmount()
-		if currOrder.GetAmount() < otherSide.GetAmount() {
-			minAmount = currOrder.GetAmount()
+		if !preventSelfTrade(currOrder, otherSide) {
+			minAmount := otherSide.GetAmount()
+			if currOrder.GetAmount() < otherSide.GetAmount() {
+				minAmount = currOrder.GetAmount()
+			}
+			currOrder.Deal(otherSide, minAmount, price)
 		}
-		currOrder.Deal(otherSide, minAmount, price)
 		if otherSide.GetAmount() == 0 {
 			firstNonZeroIndex++
 		}
@@ -126,6 +262,38 @@ func ExecuteOrder(price sdk.Dec, currOrder OrderForTrade, orderList []OrderForTr
 	return nil
 }
 
+// When the two orders have the same owner, the self-trade prevention mode of the newer order cancels
+// the left amount of one or both of them, and true is returned if they must not deal with each other.
+// At least one of them is cancelled, so the matching always makes progress.
+func preventSelfTrade(a, b OrderForTrade) bool {
+	if a.GetOwner().String() != b.GetOwner().String() {
+		return false
+	}
+	newer, older := a, b
+	if arriveEarlier(a, b) {
+		newer, older = b, a
+	}
+	switch newer.GetSelfTradePrevention() {
+	case types.SelfTradeCancelNewest:
+		newer.CancelSelfTrade(newer.GetAmount())
+	case types.SelfTradeCancelOldest:
+		older.CancelSelfTrade(older.GetAmount())
+	case types.SelfTradeCancelBoth:
+		newer.CancelSelfTrade(newer.GetAmount())
+		older.CancelSelfTrade(older.GetAmount())
+	case types.SelfTradeDecrementAndCancel:
+		amount := newer.GetAmount()
+		if older.GetAmount() < amount {
+			amount = older.GetAmount()
+		}
+		newer.CancelSelfTrade(amount)
+		older.CancelSelfTrade(amount)
+	default:
+		return false
+	}
+	return true
+}
+
 type PricePoint struct {
 	price                sdk.Dec
 	accumulatedAskAmount sdk.Int
@@ -61,6 +61,10 @@ type mocOrder struct {
 	remainAmount int64
 	side         int
 	owner        mocAccount
+	timeInForce  int64
+	rejected     bool
+	selfTrade    byte
+	cancelled    int64
 }
 
 var _ OrderForTrade = (*mocOrder)(nil)
@@ -91,6 +95,23 @@ func (order *mocOrder) GetOwner() Account {
 	return &order.owner
 }
 
+func (order *mocOrder) GetTimeInForce() int64 {
+	return order.timeInForce
+}
+
+func (order *mocOrder) Reject() {
+	order.rejected = true
+}
+
+func (order *mocOrder) GetSelfTradePrevention() byte {
+	return order.selfTrade
+}
+
+func (order *mocOrder) CancelSelfTrade(amount int64) {
+	order.remainAmount -= amount
+	order.cancelled += amount
+}
+
 func (order *mocOrder) Deal(otherSide OrderForTrade, amount int64, price sdk.Dec) {
 	other := otherSide.(*mocOrder)
 	fmt.Printf("Deal: %s|%d-%s|%d %d price:%s\n", order.GetOwner(), order.GetAmount(), other.GetOwner(), other.GetAmount(), amount, price.String())
@@ -372,6 +393,10 @@ func testGetExecutionPrice(mid int64, orders []OrderForTrade) sdk.Dec {
 }
 
 func testMatch(tag string, mid int64, orders []OrderForTrade, dealRecordList []dealRecord) {
+	testMatchWith(CallAuction{}, tag, mid, orders, dealRecordList)
+}
+
+func testMatchWith(algorithm Algorithm, tag string, mid int64, orders []OrderForTrade, dealRecordList []dealRecord) {
 	currDealRecordList = dealRecordList
 	currDealRecordIndex = 0
 	fmt.Printf("=======================%s===============================\n", tag)
@@ -388,7 +413,7 @@ func testMatch(tag string, mid int64, orders []OrderForTrade, dealRecordList []d
 	midPrice := sdk.NewDec(mid)
 	highPrice := midPrice.MulInt(sdk.NewInt(105)).QuoInt(sdk.NewInt(100))
 	lowPrice := midPrice.MulInt(sdk.NewInt(95)).QuoInt(sdk.NewInt(100))
-	Match(highPrice, midPrice, lowPrice, bidList, askList)
+	MatchWith(algorithm, highPrice, midPrice, lowPrice, bidList, askList)
 	if currDealRecordIndex != len(currDealRecordList) {
 		testHandler.Errorf("Missmatch in the count of deals")
 	}
@@ -492,3 +517,175 @@ func TestMatch_1(t *testing.T) {
 	testMatch("6_4", 110, createOrders6(), createDealRecord6_4())
 	testMatch("6_5", 0, createOrders6(), createDealRecord6_5())
 }
+
+func newMocOrderWithTif(price int64, height int64, totalAmount int64, side int, owner string, tif int64) *mocOrder {
+	order := newMocOrder(price, height, totalAmount, side, owner).(*mocOrder)
+	order.timeInForce = tif
+	return order
+}
+
+func TestPostOnlyAndFillOrKill(t *testing.T) {
+	testHandler = t
+	seller1 := newMocOrderWithTif(98, 1, 100, SELL, "seller1", types.GTE)
+	buyer1 := newMocOrderWithTif(100, 2, 50, BUY, "buyer1", types.PostOnly)
+	seller2 := newMocOrderWithTif(101, 1, 100, SELL, "seller2", types.PostOnly)
+	buyer3 := newMocOrderWithTif(99, 2, 200, BUY, "buyer3", types.FOK)
+	buyer4 := newMocOrderWithTif(98, 2, 60, BUY, "buyer4", types.FOK)
+	orders := []OrderForTrade{seller1, buyer1, seller2, buyer3, buyer4}
+	testMatch("post-only and fill-or-kill", 100, orders, []dealRecord{
+		newDR("buyer4", "seller1", 60, 98),
+	})
+	// buyer1 would take liquidity from seller1
+	if !buyer1.rejected || seller2.rejected {
+		t.Errorf("Error in rejecting post-only orders")
+	}
+	// buyer3 can not be fully filled, while buyer4 can
+	if !buyer3.rejected || buyer4.rejected || buyer3.GetAmount() != 200 || buyer4.GetAmount() != 0 {
+		t.Errorf("Error in rejecting fill-or-kill orders")
+	}
+
+	// a post-only order which is older than the crossing order takes part in matching
+	seller1 = newMocOrderWithTif(98, 1, 100, SELL, "seller1", types.PostOnly)
+	buyer1 = newMocOrderWithTif(100, 2, 50, BUY, "buyer1", types.GTE)
+	testMatch("post-only maker", 100, []OrderForTrade{seller1, buyer1}, []dealRecord{
+		newDR("buyer1", "seller1", 50, 98),
+	})
+	if seller1.rejected || buyer1.rejected {
+		t.Errorf("Error in rejecting post-only orders")
+	}
+}
+
+func TestGetAlgorithm(t *testing.T) {
+	if _, ok := GetAlgorithm(types.CallAuction).(CallAuction); !ok {
+		t.Errorf("Wrong algorithm for call auction")
+	}
+	if _, ok := GetAlgorithm(types.ContinuousMatching).(Continuous); !ok {
+		t.Errorf("Wrong algorithm for continuous matching")
+	}
+	if _, ok := GetAlgorithm(types.ProRataMatching).(ProRata); !ok {
+		t.Errorf("Wrong algorithm for pro-rata matching")
+	}
+}
+
+func TestContinuousMatching(t *testing.T) {
+	testHandler = t
+	seller1 := newMocOrderWithTif(101, 1, 100, SELL, "seller1", types.GTE)
+	seller2 := newMocOrderWithTif(100, 1, 50, SELL, "seller2", types.GTE)
+	buyer1 := newMocOrderWithTif(102, 2, 120, BUY, "buyer1", types.GTE)
+	buyer2 := newMocOrderWithTif(99, 3, 100, BUY, "buyer2", types.GTE)
+	seller3 := newMocOrderWithTif(98, 4, 150, SELL, "seller3", types.GTE)
+	orders := []OrderForTrade{seller1, seller2, buyer1, buyer2, seller3}
+	// the later orders take liquidity at the prices of the earlier orders
+	testMatchWith(Continuous{}, "continuous", 100, orders, []dealRecord{
+		newDR("buyer1", "seller2", 50, 100),
+		newDR("buyer1", "seller1", 70, 101),
+		newDR("seller3", "buyer2", 100, 99),
+	})
+	if seller1.GetAmount() != 30 || seller3.GetAmount() != 50 || buyer1.GetAmount() != 0 || buyer2.GetAmount() != 0 {
+		t.Errorf("Error in continuous matching")
+	}
+}
+
+func TestContinuousMatchingInPriceRange(t *testing.T) {
+	testHandler = t
+	seller1 := newMocOrderWithTif(110, 1, 50, SELL, "seller1", types.GTE)
+	seller2 := newMocOrderWithTif(90, 1, 30, SELL, "seller2", types.GTE)
+	buyer1 := newMocOrderWithTif(120, 2, 100, BUY, "buyer1", types.GTE)
+	seller3 := newMocOrderWithTif(80, 3, 100, SELL, "seller3", types.GTE)
+	orders := []OrderForTrade{seller1, seller2, buyer1, seller3}
+	// the prices are kept in [95, 105], and seller1 can not deal at 105
+	testMatchWith(Continuous{}, "continuous in price range", 100, orders, []dealRecord{
+		newDR("buyer1", "seller2", 30, 95),
+		newDR("seller3", "buyer1", 70, 105),
+	})
+	if seller1.GetAmount() != 50 || seller2.GetAmount() != 0 || buyer1.GetAmount() != 0 || seller3.GetAmount() != 30 {
+		t.Errorf("Error in continuous matching in price range")
+	}
+}
+
+func TestProRataMatching(t *testing.T) {
+	testHandler = t
+	seller1 := newMocOrderWithTif(95, 1, 100, SELL, "seller1", types.GTE)
+	seller2 := newMocOrderWithTif(98, 1, 300, SELL, "seller2", types.GTE)
+	seller3 := newMocOrderWithTif(98, 2, 100, SELL, "seller3", types.GTE)
+	buyer1 := newMocOrderWithTif(100, 1, 250, BUY, "buyer1", types.GTE)
+	orders := []OrderForTrade{seller1, seller2, seller3, buyer1}
+	// seller1 has a better price, and the left 150 is allocated to seller2 and seller3 as 3:1
+	testMatchWith(ProRata{}, "pro-rata", 100, orders, []dealRecord{
+		newDR("buyer1", "seller1", 100, 98),
+		newDR("buyer1", "seller2", 113, 98),
+		newDR("buyer1", "seller3", 37, 98),
+	})
+	if seller1.GetAmount() != 0 || seller2.GetAmount() != 187 || seller3.GetAmount() != 63 || buyer1.GetAmount() != 0 {
+		t.Errorf("Error in pro-rata matching")
+	}
+}
+
+func newMocOrderWithSelfTrade(price int64, height int64, totalAmount int64, side int, owner string, mode byte) *mocOrder {
+	order := newMocOrder(price, height, totalAmount, side, owner).(*mocOrder)
+	order.selfTrade = mode
+	return order
+}
+
+func TestPreventSelfTrade(t *testing.T) {
+	expected := []struct {
+		mode        byte
+		prevented   bool
+		newerAmount int64
+		olderAmount int64
+	}{
+		{types.SelfTradeAllowed, false, 60, 100},
+		{types.SelfTradeCancelNewest, true, 0, 100},
+		{types.SelfTradeCancelOldest, true, 60, 0},
+		{types.SelfTradeCancelBoth, true, 0, 0},
+		{types.SelfTradeDecrementAndCancel, true, 0, 40},
+	}
+	for _, e := range expected {
+		older := newMocOrderWithSelfTrade(98, 1, 100, SELL, "alice", types.SelfTradeCancelBoth)
+		newer := newMocOrderWithSelfTrade(100, 2, 60, BUY, "alice", e.mode)
+		// the mode of the newer order is used, whichever is the current order
+		if preventSelfTrade(older, newer) != e.prevented || newer.GetAmount() != e.newerAmount ||
+			older.GetAmount() != e.olderAmount {
+			t.Errorf("Error in preventing self-trade with mode %d", e.mode)
+		}
+	}
+	other := newMocOrderWithSelfTrade(100, 2, 60, BUY, "bob", types.SelfTradeCancelBoth)
+	older := newMocOrderWithSelfTrade(98, 1, 100, SELL, "alice", types.SelfTradeCancelBoth)
+	if preventSelfTrade(other, older) || other.GetAmount() != 60 || older.GetAmount() != 100 {
+		t.Errorf("Error in preventing self-trade between different owners")
+	}
+}
+
+func TestSelfTradePreventionInMatching(t *testing.T) {
+	testHandler = t
+	// alice's buy order is decreased to zero and cancelled, and her sell order deals with bob's
+	seller := newMocOrderWithSelfTrade(98, 1, 100, SELL, "alice", types.SelfTradeAllowed)
+	buyer1 := newMocOrderWithSelfTrade(100, 2, 40, BUY, "alice", types.SelfTradeDecrementAndCancel)
+	buyer2 := newMocOrderWithSelfTrade(99, 1, 50, BUY, "bob", types.SelfTradeAllowed)
+	testMatch("decrement and cancel", 100, []OrderForTrade{seller, buyer1, buyer2}, []dealRecord{
+		newDR("alice", "bob", 50, 98),
+	})
+	if seller.cancelled != 40 || seller.GetAmount() != 10 || buyer1.cancelled != 40 || buyer2.GetAmount() != 0 {
+		t.Errorf("Error in decrement-and-cancel")
+	}
+
+	// alice's older sell order is cancelled, and her buy order takes liquidity from bob
+	seller1 := newMocOrderWithSelfTrade(100, 1, 50, SELL, "alice", types.SelfTradeAllowed)
+	seller2 := newMocOrderWithSelfTrade(101, 1, 50, SELL, "bob", types.SelfTradeAllowed)
+	buyer := newMocOrderWithSelfTrade(102, 2, 80, BUY, "alice", types.SelfTradeCancelOldest)
+	testMatchWith(Continuous{}, "cancel oldest", 100, []OrderForTrade{seller1, seller2, buyer}, []dealRecord{
+		newDR("alice", "bob", 50, 101),
+	})
+	if seller1.cancelled != 50 || buyer.cancelled != 0 || buyer.GetAmount() != 30 {
+		t.Errorf("Error in cancel-oldest")
+	}
+
+	// bob's order is allocated after alice's orders cancel each other
+	seller1 = newMocOrderWithSelfTrade(98, 1, 100, SELL, "alice", types.SelfTradeAllowed)
+	seller2 = newMocOrderWithSelfTrade(98, 1, 100, SELL, "bob", types.SelfTradeAllowed)
+	buyer = newMocOrderWithSelfTrade(100, 2, 100, BUY, "alice", types.SelfTradeCancelBoth)
+	testMatchWith(ProRata{}, "cancel both", 100, []OrderForTrade{seller1, seller2, buyer}, nil)
+	if seller1.cancelled != 100 || buyer.cancelled != 100 || seller2.GetAmount() != 100 {
+		t.Errorf("Error in cancel-both")
+	}
+}
@@ -36,6 +36,11 @@ type Order struct {
 	Height int64   `json:"height"`
 	ID     int64   `json:"id"`
 	Side   int     `json:"side"`
+
+	// the amount before the current round of matching, and the stock and money dealt in this round
+	OrigAmount int64   `json:"-"`
+	DealStock  int64   `json:"-"`
+	DealMoney  sdk.Dec `json:"-"`
 }
 
 func RandOrder(r *rand.Rand, priceRange int64, amountRange int64) *Order {
@@ -44,12 +49,15 @@ func RandOrder(r *rand.Rand, priceRange int64, amountRange int64) *Order {
 	if r.Int31n(2) == 1 {
 		side = market.BUY
 	}
+	amount := r.Int63n(amountRange) + 1
 	return &Order{
-		Price:  sdk.NewDec(r.Int63n(priceRange)),
-		Amount: r.Int63n(amountRange),
-		Height: Height,
-		ID:     OrderCount,
-		Side:   side,
+		Price:      sdk.NewDec(r.Int63n(priceRange)),
+		Amount:     amount,
+		Height:     Height,
+		ID:         OrderCount,
+		Side:       side,
+		OrigAmount: amount,
+		DealMoney:  sdk.ZeroDec(),
 	}
 }
 
@@ -63,7 +71,8 @@ func (order *Order) GetHeight() int64 {
 	return order.Height
 }
 func (order *Order) GetHash() []byte {
-	bz, err := json.Marshal(order)
+	// the hash must not change when the order is dealt, because some algorithms sort orders while matching
+	bz, err := json.Marshal(order.ID)
 	if err != nil {
 		panic(err.Error())
 	}
@@ -76,6 +85,18 @@ func (order *Order) GetSide() int {
 func (order *Order) GetOwner() match.Account {
 	return &Account{ID: order.ID % 10000}
 }
+func (order *Order) GetTimeInForce() int64 {
+	return market.GTE
+}
+func (order *Order) Reject() {
+	panic("GTE orders can not be rejected")
+}
+func (order *Order) GetSelfTradePrevention() byte {
+	return market.SelfTradeAllowed
+}
+func (order *Order) CancelSelfTrade(amount int64) {
+	panic("self-trades are allowed")
+}
 func (order *Order) String() string {
 	return fmt.Sprintf("%d", order.ID)
 }
@@ -212,6 +233,11 @@ func (order *Order) Deal(otherSide match.OrderForTrade, amount int64, price sdk.
 	}
 	otherOrder.Amount -= amount
 	order.Amount -= amount
+	money := price.MulInt64(amount)
+	for _, o := range []*Order{order, otherOrder} {
+		o.DealStock += amount
+		o.DealMoney = o.DealMoney.Add(money)
+	}
 	if otherOrder.Amount == 0 {
 		Keeper.RemoveOrder(otherOrder)
 	}
@@ -222,7 +248,40 @@ func (order *Order) Deal(otherSide match.OrderForTrade, amount int64, price sdk.
 	DealCount++
 }
 
-func runTest(seed int64, priceRange int64, amountRange int64, delStep int32, liveOrderUpper, liveOrderLower int, heightLimit int) {
+func startRound(orderLists ...[]match.OrderForTrade) {
+	for _, orderList := range orderLists {
+		for _, o := range orderList {
+			order := o.(*Order)
+			order.OrigAmount = order.Amount
+			order.DealStock = 0
+			order.DealMoney = sdk.ZeroDec()
+		}
+	}
+}
+
+// The stock sold by the asks must be exactly the stock bought by the bids, and so is the money
+func checkConservation(bidList, askList []match.OrderForTrade) {
+	stock := [2]int64{}
+	money := [2]sdk.Dec{sdk.ZeroDec(), sdk.ZeroDec()}
+	for i, orderList := range [][]match.OrderForTrade{bidList, askList} {
+		for _, o := range orderList {
+			order := o.(*Order)
+			if order.Amount < 0 || order.Amount+order.DealStock != order.OrigAmount {
+				panic("Amount is not conserved")
+			}
+			stock[i] += order.DealStock
+			money[i] = money[i].Add(order.DealMoney)
+		}
+	}
+	if stock[0] != stock[1] {
+		panic("Stock is not conserved")
+	}
+	if !money[0].Equal(money[1]) {
+		panic("Money is not conserved")
+	}
+}
+
+func runTest(algorithm match.Algorithm, seed int64, priceRange int64, amountRange int64, delStep int32, liveOrderUpper, liveOrderLower int, heightLimit int) {
 	DealCount = 0
 	LastPrice = sdk.ZeroDec()
 	Keeper = &OrderKeeper{
@@ -253,7 +312,9 @@ func runTest(seed int64, priceRange int64, amountRange int64, delStep int32, liv
 		lowPrice := LastPrice.Mul(sdk.NewDec(int64(100 - ratio))).Quo(sdk.NewDec(100))
 		highPrice := LastPrice.Mul(sdk.NewDec(int64(100 + ratio))).Quo(sdk.NewDec(100))
 
-		match.Match(highPrice, LastPrice, lowPrice, bidList, askList)
+		startRound(bidList, askList)
+		match.MatchWith(algorithm, highPrice, LastPrice, lowPrice, bidList, askList)
+		checkConservation(bidList, askList)
 
 		highBuy = Keeper.GetHighestBuy().Price
 		lowSell = Keeper.GetLowestSell().Price
@@ -269,6 +330,10 @@ func runTest(seed int64, priceRange int64, amountRange int64, delStep int32, liv
 }
 
 func main() {
-	//     seed, priceRange, amountRange, delStep, liveOrderUpper, liveOrderLower, heightLimit
-	runTest(0, 100, 1000, 3, 8000, 6000, 1000)
+	algorithms := []match.Algorithm{match.CallAuction{}, match.Continuous{}, match.ProRata{}}
+	for _, algorithm := range algorithms {
+		fmt.Printf("Algorithm: %T\n", algorithm)
+		//     algorithm, seed, priceRange, amountRange, delStep, liveOrderUpper, liveOrderLower, heightLimit
+		runTest(algorithm, 0, 100, 1000, 3, 8000, 6000, 1000)
+	}
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/coinexchain/cet-sdk/modules/market"
+	dex "github.com/coinexchain/cet-sdk/types"
+)
+
+// matchreplay replays the matching of the market module on a snapshot of mainnet, such that the
+// bugs can be reproduced locally and the changes of matching can be regression-tested:
+//
+//	matchreplay -genesis exported.json -blocks blocks.jsonl -expected fills.jsonl > actual.jsonl
+//
+// The snapshot is the exported genesis file, or only its market section, or a dump of the market
+// store. Each line of the blocks file is a Block, and without it one block is replayed after the
+// snapshot. The FillOrderInfo and CancelOrderInfo messages are written as JSON lines, and their
+// differences against the expected stream are reported to stderr.
+func main() {
+	genesisFile := flag.String("genesis", "", "the exported genesis file, or its market section")
+	kvFile := flag.String("kvdump", "", "the dump of the market store, a JSON array of hex keys and values")
+	blocksFile := flag.String("blocks", "", "the blocks to replay, one JSON object per line")
+	expectedFile := flag.String("expected", "", "the expected messages, one JSON object per line")
+	chainID := flag.String("chain-id", "coinexdex", "the chain ID, which decides how often the expired orders are removed")
+	height := flag.Int64("height", 1, "the height of the block replayed when no blocks are given")
+	unixTime := flag.Int64("time", 0, "the unix time of the block replayed when no blocks are given")
+	flag.Parse()
+
+	if err := run(*genesisFile, *kvFile, *blocksFile, *expectedFile, *chainID, *height, *unixTime); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(genesisFile, kvFile, blocksFile, expectedFile, chainID string, height, unixTime int64) error {
+	dex.InitSdkConfig()
+	replayer := NewReplayer(chainID)
+	if genesisFile == "" && kvFile == "" {
+		return fmt.Errorf("either -genesis or -kvdump is required")
+	}
+	if genesisFile != "" {
+		state, err := readGenesis(genesisFile)
+		if err != nil {
+			return err
+		}
+		if err := replayer.LoadGenesis(state); err != nil {
+			return err
+		}
+	}
+	if kvFile != "" {
+		var pairs []KVPair
+		if err := readJSON(kvFile, &pairs); err != nil {
+			return err
+		}
+		if err := replayer.LoadKVPairs(pairs); err != nil {
+			return err
+		}
+	}
+
+	blocks := []Block{{Height: height, Time: unixTime}}
+	if blocksFile != "" {
+		blocks = nil
+		if err := readJSONLines(blocksFile, func(line []byte) error {
+			var block Block
+			if err := market.ModuleCdc.UnmarshalJSON(line, &block); err != nil {
+				return err
+			}
+			blocks = append(blocks, block)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	actual, err := replayer.Replay(blocks, os.Stdout)
+	if err != nil || expectedFile == "" {
+		return err
+	}
+	var expected []Record
+	if err := readJSONLines(expectedFile, func(line []byte) error {
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return err
+		}
+		expected = append(expected, record)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if n := DiffRecords(expected, actual, os.Stderr); n != 0 {
+		return fmt.Errorf("%d of the messages are different from the expected ones", n)
+	}
+	return nil
+}
+
+// the market section is taken from app_state when the file is an exported genesis file
+func readGenesis(path string) (market.GenesisState, error) {
+	var state market.GenesisState
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	var doc struct {
+		AppState map[string]json.RawMessage `json:"app_state"`
+	}
+	if err := json.Unmarshal(bz, &doc); err == nil && doc.AppState != nil {
+		section, ok := doc.AppState[market.ModuleName]
+		if !ok {
+			return state, fmt.Errorf("no %s section in the genesis file", market.ModuleName)
+		}
+		bz = section
+	}
+	err = market.ModuleCdc.UnmarshalJSON(bz, &state)
+	return state, err
+}
+
+func readJSON(path string, v interface{}) error {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bz, v)
+}
+
+// call fn with each non-empty line of the file
+func readJSONLines(path string, fn func(line []byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) != 0 && len(line) != 1 {
+			if e := fn(line); e != nil {
+				return fmt.Errorf("%s:%d : %s", path, lineNum, e.Error())
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
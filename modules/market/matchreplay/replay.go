@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/params"
+
+	"github.com/coinexchain/cet-sdk/modules/asset"
+	"github.com/coinexchain/cet-sdk/modules/market"
+	"github.com/coinexchain/cet-sdk/modules/market/internal/keepers"
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	"github.com/coinexchain/cet-sdk/msgqueue"
+)
+
+// KVPair is one entry of a dump of the market store, whose key and value are hex strings
+type KVPair struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Block carries the changes of the order book before the matching of one block. The new orders
+// are added as if they were created in this block, and the cancelled orders are removed silently,
+// because their CancelOrderInfo is sent by the handler instead of the EndBlocker.
+type Block struct {
+	Height  int64          `json:"height"`
+	Time    int64          `json:"time"`
+	Orders  []*types.Order `json:"orders,omitempty"`
+	Cancels []string       `json:"cancels,omitempty"`
+}
+
+// Record is one message sent by the EndBlocker, in the same format as the message queue
+type Record struct {
+	Height int64           `json:"height"`
+	Key    string          `json:"key"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// the messages about the matching results are recorded
+var recordedKeys = map[string]bool{
+	types.FillOrderInfoKey:   true,
+	types.CancelOrderInfoKey: true,
+}
+
+// Replayer runs the EndBlocker of the market module block by block on a snapshot of the market
+// store. The other modules are replaced by mock keepers, which have no balances and forbid nothing.
+type Replayer struct {
+	chainID string
+	keeper  keepers.Keeper
+	ms      store.CommitMultiStore
+}
+
+func NewReplayer(chainID string) *Replayer {
+	db := dbm.NewMemDB()
+	ms := store.NewCommitMultiStore(db)
+	marketKey := sdk.NewKVStoreKey(types.StoreKey)
+	keyParams := sdk.NewKVStoreKey(params.StoreKey)
+	tkeyParams := sdk.NewTransientStoreKey(params.TStoreKey)
+	ms.MountStoreWithDB(marketKey, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyParams, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(tkeyParams, sdk.StoreTypeTransient, db)
+	if err := ms.LoadLatestVersion(); err != nil {
+		panic(err)
+	}
+
+	cdc := types.ModuleCdc
+	paramsKeeper := params.NewKeeper(cdc, keyParams, tkeyParams, params.DefaultCodespace)
+	keeper := keepers.NewKeeper(marketKey, mockAssetKeeper{}, mockBankxKeeper{}, cdc,
+		mockMsgSender{}, paramsKeeper.Subspace(types.StoreKey), auth.AccountKeeper{}, mockAuthXKeeper{})
+	return &Replayer{
+		chainID: chainID,
+		keeper:  keeper,
+		ms:      ms,
+	}
+}
+
+func (r *Replayer) newContext(height, unixTime int64) sdk.Context {
+	header := abci.Header{ChainID: r.chainID, Height: height, Time: time.Unix(unixTime, 0)}
+	return sdk.NewContext(r.ms, header, false, log.NewNopLogger())
+}
+
+// LoadGenesis imports a genesis state of the market module, and all its orders are marked as
+// newly added, so they are matched in the first block
+func (r *Replayer) LoadGenesis(state market.GenesisState) error {
+	if err := state.Validate(); err != nil {
+		return err
+	}
+	market.InitGenesis(r.newContext(0, 0), r.keeper, state)
+	return nil
+}
+
+// LoadKVPairs writes a dump of the market store, which also keeps the stop orders, the market
+// status and other states missing in the genesis state
+func (r *Replayer) LoadKVPairs(pairs []KVPair) error {
+	kvStore := r.newContext(0, 0).KVStore(r.keeper.GetMarketKey())
+	for _, pair := range pairs {
+		key, err := hex.DecodeString(pair.Key)
+		if err != nil {
+			return fmt.Errorf("invalid key %s : %s", pair.Key, err.Error())
+		}
+		value, err := hex.DecodeString(pair.Value)
+		if err != nil {
+			return fmt.Errorf("invalid value of key %s : %s", pair.Key, err.Error())
+		}
+		kvStore.Set(key, value)
+	}
+	return nil
+}
+
+// ReplayBlock applies the changes in the block, runs the EndBlocker and returns the recorded messages
+func (r *Replayer) ReplayBlock(block Block) ([]Record, error) {
+	ctx := r.newContext(block.Height, block.Time)
+	globalKeeper := keepers.NewGlobalOrderKeeper(r.keeper.GetMarketKey(), types.ModuleCdc)
+	for _, orderID := range block.Cancels {
+		order := globalKeeper.QueryOrder(ctx, orderID)
+		if order == nil {
+			return nil, fmt.Errorf("order %s to be cancelled at height %d does not exist", orderID, block.Height)
+		}
+		if err := keepers.NewOrderKeeper(r.keeper.GetMarketKey(), order.TradingPair, types.ModuleCdc).Remove(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+	for _, order := range block.Orders {
+		order.Height = block.Height
+		if err := r.keeper.SetOrder(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	market.EndBlocker(ctx, r.keeper)
+
+	var records []Record
+	for _, event := range ctx.EventManager().Events() {
+		if event.Type != msgqueue.EventTypeMsgQueue {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if recordedKeys[string(attr.Key)] {
+				records = append(records, Record{Height: block.Height, Key: string(attr.Key), Value: attr.Value})
+			}
+		}
+	}
+	return records, nil
+}
+
+// Replay runs the blocks in sequence, and writes the recorded messages as JSON lines
+func (r *Replayer) Replay(blocks []Block, w io.Writer) ([]Record, error) {
+	var all []Record
+	for _, block := range blocks {
+		records, err := r.ReplayBlock(block)
+		if err != nil {
+			return all, err
+		}
+		for _, record := range records {
+			bz, err := json.Marshal(record)
+			if err != nil {
+				return all, err
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", bz); err != nil {
+				return all, err
+			}
+		}
+		all = append(all, records...)
+	}
+	return all, nil
+}
+
+// DiffRecords writes the differences between the expected and actual streams in a unified style,
+// and returns the count of different records
+func DiffRecords(expected, actual []Record, w io.Writer) int {
+	count := 0
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		var e, a []byte
+		if i < len(expected) {
+			e = compactRecord(expected[i])
+		}
+		if i < len(actual) {
+			a = compactRecord(actual[i])
+		}
+		if bytes.Equal(e, a) {
+			continue
+		}
+		count++
+		fmt.Fprintf(w, "@@ record %d @@\n", i)
+		if e != nil {
+			fmt.Fprintf(w, "- %s\n", e)
+		}
+		if a != nil {
+			fmt.Fprintf(w, "+ %s\n", a)
+		}
+	}
+	return count
+}
+
+// the records are compared in the compact form, so the spaces in the expected stream do not matter
+func compactRecord(record Record) []byte {
+	var value bytes.Buffer
+	if err := json.Compact(&value, record.Value); err != nil {
+		value.Reset()
+		value.Write(record.Value)
+	}
+	record.Value = value.Bytes()
+	bz, err := json.Marshal(record)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// ------------------------------------------------------------------
+// mock keepers of the other modules
+
+type mockBankxKeeper struct{}
+
+func (mockBankxKeeper) SubtractCoins(ctx sdk.Context, addr sdk.AccAddress, amt sdk.Coins) sdk.Error {
+	return nil
+}
+func (mockBankxKeeper) DeductInt64CetFee(ctx sdk.Context, addr sdk.AccAddress, amt int64) sdk.Error {
+	return nil
+}
+func (mockBankxKeeper) HasCoins(ctx sdk.Context, addr sdk.AccAddress, amt sdk.Coins) bool {
+	return true
+}
+func (mockBankxKeeper) SendCoins(ctx sdk.Context, from sdk.AccAddress, to sdk.AccAddress, amt sdk.Coins) sdk.Error {
+	return nil
+}
+func (mockBankxKeeper) FreezeCoins(ctx sdk.Context, acc sdk.AccAddress, amt sdk.Coins) sdk.Error {
+	return nil
+}
+func (mockBankxKeeper) UnFreezeCoins(ctx sdk.Context, acc sdk.AccAddress, amt sdk.Coins) sdk.Error {
+	return nil
+}
+
+type mockAssetKeeper struct{}
+
+func (mockAssetKeeper) IsTokenForbidden(ctx sdk.Context, denom string) bool {
+	return false
+}
+func (mockAssetKeeper) IsTokenExists(ctx sdk.Context, denom string) bool {
+	return true
+}
+func (mockAssetKeeper) IsTokenIssuer(ctx sdk.Context, denom string, addr sdk.AccAddress) bool {
+	return false
+}
+func (mockAssetKeeper) IsForbiddenByTokenIssuer(ctx sdk.Context, denom string, addr sdk.AccAddress) bool {
+	return false
+}
+func (mockAssetKeeper) GetToken(ctx sdk.Context, symbol string) asset.Token {
+	return nil
+}
+
+type mockAuthXKeeper struct{}
+
+func (mockAuthXKeeper) GetRefereeAddr(ctx sdk.Context, accAddr sdk.AccAddress) sdk.AccAddress {
+	return nil
+}
+func (mockAuthXKeeper) GetRebateRatio(ctx sdk.Context) int64 {
+	return 0
+}
+func (mockAuthXKeeper) GetRebateRatioBase(ctx sdk.Context) int64 {
+	return 10000
+}
+
+// the EndBlocker only fills the messages when the market topic is subscribed
+type mockMsgSender struct{}
+
+func (mockMsgSender) SendMsg(key []byte, v []byte) {}
+func (mockMsgSender) CommitBlock(height int64)     {}
+func (mockMsgSender) IsSubscribed(topic string) bool {
+	return topic == types.Topic
+}
+func (mockMsgSender) IsOpenToggle() bool {
+	return true
+}
+func (mockMsgSender) GetMode() []string {
+	return nil
+}
+func (mockMsgSender) Close() {}
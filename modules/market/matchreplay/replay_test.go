@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/coinexchain/cet-sdk/modules/market"
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+)
+
+func newOrder(sender string, seq uint64, price, quantity int64, side byte, height int64) *types.Order {
+	addr, _ := sdk.AccAddressFromHex("01234567890123456789012345678901234" + sender)
+	freeze := quantity
+	if side == types.BUY {
+		freeze = price * quantity
+	}
+	return &types.Order{
+		Sender:      addr,
+		Sequence:    seq,
+		TradingPair: "abc/cet",
+		OrderType:   types.LIMIT,
+		Price:       sdk.NewDec(price),
+		Quantity:    quantity,
+		Side:        side,
+		TimeInForce: types.GTE,
+		Height:      height,
+		Freeze:      freeze,
+		LeftStock:   quantity,
+	}
+}
+
+func newGenesisState() market.GenesisState {
+	orders := []*types.Order{
+		newOrder("00001", 1, 10, 100, types.BUY, 5),
+		newOrder("00002", 1, 10, 60, types.SELL, 5),
+	}
+	infos := []types.MarketInfo{{
+		Stock:             "abc",
+		Money:             "cet",
+		PricePrecision:    8,
+		OrderPrecision:    0,
+		LastExecutedPrice: sdk.NewDec(10),
+	}}
+	return market.NewGenesisState(types.DefaultParams(), orders, infos, 0)
+}
+
+func decodeFill(t *testing.T, record Record) types.FillOrderInfo {
+	var info types.FillOrderInfo
+	require.Nil(t, json.Unmarshal(record.Value, &info))
+	return info
+}
+
+func TestReplay(t *testing.T) {
+	replayer := NewReplayer("coinexdex")
+	state := newGenesisState()
+	require.Nil(t, replayer.LoadGenesis(state))
+	buyID := state.Orders[0].OrderID()
+
+	blocks := []Block{
+		{Height: 10, Time: 1000},
+		// the new sell order fills the rest of the buy order, and is partially filled
+		{Height: 11, Time: 1005, Orders: []*types.Order{newOrder("00003", 1, 10, 50, types.SELL, 0)}},
+	}
+	var out bytes.Buffer
+	records, err := replayer.Replay(blocks, &out)
+	require.Nil(t, err)
+	require.Equal(t, len(records), strings.Count(out.String(), "\n"))
+
+	// the fully filled orders are also removed with CancelOrderInfo
+	var fills []types.FillOrderInfo
+	cancels := 0
+	for _, record := range records {
+		if record.Key == types.CancelOrderInfoKey {
+			cancels++
+			continue
+		}
+		fills = append(fills, decodeFill(t, record))
+	}
+	require.Equal(t, 4, len(fills))
+	require.Equal(t, 2, cancels)
+	require.EqualValues(t, 10, records[0].Height)
+	require.EqualValues(t, 11, records[len(records)-1].Height)
+
+	require.Equal(t, buyID, fills[1].OrderID)
+	require.EqualValues(t, 60, fills[1].DealStock)
+	require.EqualValues(t, 40, fills[1].LeftStock)
+	require.EqualValues(t, 11, fills[2].Height)
+	require.EqualValues(t, 40, fills[2].DealStock)
+	require.EqualValues(t, 10, fills[2].LeftStock)
+	require.Equal(t, buyID, fills[3].OrderID)
+	require.EqualValues(t, 100, fills[3].DealStock)
+	require.EqualValues(t, 0, fills[3].LeftStock)
+
+	// the cancelled order does not take part in the matching
+	replayer = NewReplayer("coinexdex")
+	require.Nil(t, replayer.LoadGenesis(newGenesisState()))
+	records, err = replayer.Replay([]Block{{Height: 10, Time: 1000, Cancels: []string{buyID}}}, ioutil.Discard)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(records))
+	_, err = replayer.Replay([]Block{{Height: 11, Time: 1005, Cancels: []string{buyID}}}, ioutil.Discard)
+	require.NotNil(t, err)
+}
+
+func TestDiffRecords(t *testing.T) {
+	expected := []Record{
+		{Height: 1, Key: types.FillOrderInfoKey, Value: json.RawMessage(`{"order_id": "a", "deal_stock": 1}`)},
+		{Height: 1, Key: types.FillOrderInfoKey, Value: json.RawMessage(`{"order_id":"b","deal_stock":1}`)},
+	}
+	actual := []Record{
+		{Height: 1, Key: types.FillOrderInfoKey, Value: json.RawMessage(`{"order_id":"a","deal_stock":1}`)},
+		{Height: 1, Key: types.FillOrderInfoKey, Value: json.RawMessage(`{"order_id":"b","deal_stock":2}`)},
+		{Height: 2, Key: types.CancelOrderInfoKey, Value: json.RawMessage(`{"order_id":"c"}`)},
+	}
+	var out bytes.Buffer
+	require.Equal(t, 0, DiffRecords(expected, expected, &out))
+	require.Equal(t, "", out.String())
+	require.Equal(t, 2, DiffRecords(expected, actual, &out))
+	require.Equal(t, `@@ record 1 @@
+- {"height":1,"key":"fill_order_info","value":{"order_id":"b","deal_stock":1}}
++ {"height":1,"key":"fill_order_info","value":{"order_id":"b","deal_stock":2}}
+@@ record 2 @@
++ {"height":2,"key":"del_order_info","value":{"order_id":"c"}}
+`, out.String())
+}
+
+func TestReadGenesis(t *testing.T) {
+	dir, err := ioutil.TempDir("", "matchreplay")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	state := newGenesisState()
+	section, err := market.ModuleCdc.MarshalJSON(state)
+	require.Nil(t, err)
+	exported, err := json.Marshal(map[string]interface{}{
+		"chain_id":  "coinexdex",
+		"app_state": map[string]json.RawMessage{market.ModuleName: section},
+	})
+	require.Nil(t, err)
+
+	// both the exported genesis file and its market section can be read
+	for i, bz := range [][]byte{section, exported} {
+		path := filepath.Join(dir, string('a'+rune(i)))
+		require.Nil(t, ioutil.WriteFile(path, bz, 0644))
+		readState, err := readGenesis(path)
+		require.Nil(t, err)
+		require.Equal(t, 2, len(readState.Orders))
+		require.Equal(t, state.Orders[1].OrderID(), readState.Orders[1].OrderID())
+		require.Equal(t, "abc/cet", readState.MarketInfos[0].GetSymbol())
+	}
+}
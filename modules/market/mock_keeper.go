@@ -20,6 +20,9 @@ func (k *mockKeeper) GetRefereeAddr(ctx sdk.Context, accAddr sdk.AccAddress) sdk
 	}
 	return addr
 }
+func (k *mockKeeper) GetUserVolume(ctx sdk.Context, addr sdk.AccAddress) sdk.Int {
+	return sdk.ZeroInt()
+}
 func (k *mockKeeper) GetRebateRatio(ctx sdk.Context) int64 {
 	return 100
 }
@@ -0,0 +1,16 @@
+package market
+
+import (
+	"github.com/coinexchain/cet-sdk/modules/market/internal/types"
+	"github.com/coinexchain/cet-sdk/msgqueue"
+)
+
+func init() {
+	msgqueue.RegisterPayload(ModuleName, types.CreateMarketInfoKey, 1, types.MsgCreateTradingPair{})
+	msgqueue.RegisterPayload(ModuleName, types.CreateOrderInfoKey, 1, types.CreateOrderInfo{})
+	msgqueue.RegisterPayload(ModuleName, types.FillOrderInfoKey, 1, types.FillOrderInfo{})
+	msgqueue.RegisterPayload(ModuleName, types.CancelOrderInfoKey, 1, types.CancelOrderInfo{})
+	msgqueue.RegisterPayload(ModuleName, types.TriggerOrderInfoKey, 1, types.TriggerOrderInfo{})
+	msgqueue.RegisterPayload(ModuleName, types.ModifyOrderInfoKey, 1, types.ModifyOrderInfo{})
+	msgqueue.RegisterPayload(ModuleName, types.MarketStatusInfoKey, 1, types.MarketStatusInfo{})
+}
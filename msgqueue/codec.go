@@ -0,0 +1,424 @@
+package msgqueue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+	FormatAvro     = "avro"
+)
+
+// Codec encodes the JSON payloads sent with a key into the format expected by the consumers of a writer
+type Codec interface {
+	Encode(key string, payload []byte) ([]byte, error)
+	// EncodeEnvelope encodes the envelope of a payload, which is already encoded by Encode
+	EncodeEnvelope(env Envelope, payload []byte) ([]byte, error)
+	Name() string
+}
+
+func NewCodec(format string) (Codec, error) {
+	switch format {
+	case "", FormatJSON:
+		return jsonCodec{}, nil
+	case FormatProtobuf:
+		return protobufCodec{}, nil
+	case FormatAvro:
+		return avroCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+type jsonCodec struct{}
+
+func (c jsonCodec) Encode(key string, payload []byte) ([]byte, error) {
+	return payload, nil
+}
+
+func (c jsonCodec) EncodeEnvelope(env Envelope, payload []byte) ([]byte, error) {
+	env.Payload = payload
+	return env.Bytes(), nil
+}
+
+func (c jsonCodec) Name() string {
+	return FormatJSON
+}
+
+// EncodeError is returned by the writers when the payload of a registered key can not be encoded.
+// Writing the payload again fails too, so the message is logged and skipped.
+type EncodeError struct {
+	Key string
+	Err error
+}
+
+func (e EncodeError) Error() string {
+	return fmt.Sprintf("encode payload %s failed, err : %s", e.Key, e.Err.Error())
+}
+
+func isEncodeError(err error) bool {
+	_, ok := err.(EncodeError)
+	return ok
+}
+
+// encodePayload encodes the value of a message with a registered key, which is the payload or the
+// envelope of the payload sent by SendEvents. The envelope is encoded with its payload encoded inside.
+// The values of the unregistered keys are returned as JSON, and since the consumers look up the schema
+// of a payload by its key, they know the keys without a schema carry JSON.
+func encodePayload(codec Codec, key, value []byte) ([]byte, error) {
+	if _, ok := GetPayloadType(string(key)); !ok {
+		return value, nil
+	}
+	env, isEnvelope := unwrapEnvelope(key, value)
+	if isEnvelope {
+		value = env.Payload
+	}
+	bz, err := codec.Encode(string(key), value)
+	if err == nil && isEnvelope {
+		bz, err = codec.EncodeEnvelope(env, bz)
+	}
+	if err != nil {
+		return nil, EncodeError{Key: string(key), Err: err}
+	}
+	return bz, nil
+}
+
+var _ MsgWriter = codecMsgWriter{}
+
+// codecMsgWriter encodes the values before writing them. The payloads of the unregistered keys are
+// written as JSON.
+type codecMsgWriter struct {
+	MsgWriter
+	codec Codec
+}
+
+func withCodec(w MsgWriter, format string) (MsgWriter, error) {
+	codec, err := NewCodec(format)
+	if err != nil {
+		w.Close()
+		return w, err
+	}
+	if codec.Name() == FormatJSON {
+		return w, nil
+	}
+	return codecMsgWriter{MsgWriter: w, codec: codec}, nil
+}
+
+func (w codecMsgWriter) WriteKV(k, v []byte) error {
+	bz, err := encodePayload(w.codec, k, v)
+	if err != nil {
+		return err
+	}
+	return w.MsgWriter.WriteKV(k, bz)
+}
+
+func (w codecMsgWriter) CommitBlock(height int64) error {
+	return commitBlock(w.MsgWriter, height)
+}
+
+func (w codecMsgWriter) String() string {
+	return w.MsgWriter.String() + "?format=" + w.codec.Name()
+}
+
+// how a Go type is encoded by the codecs, which follows how encoding/json marshals it
+type codecKind int
+
+const (
+	// the JSON text of the value is encoded as a string, for the types without a fixed layout
+	kindJSON codecKind = iota
+	kindString
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindBytes
+	kindRecord
+	kindList
+)
+
+// codecTypeOf returns the kind of a type, the type of its elements or fields for the lists and
+// records, and whether its value may be null
+func codecTypeOf(t reflect.Type) (kind codecKind, elem reflect.Type, nullable bool) {
+	for t.Kind() == reflect.Ptr {
+		t, nullable = t.Elem(), true
+	}
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return kindJSON, nil, true
+	}
+	if t == coinsType || t == decCoinsType {
+		return kindList, t.Elem(), nullable
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return kindString, nil, nullable
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return kindBool, nil, nullable
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return kindInt, nil, nullable
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return kindUint, nil, nullable
+	case reflect.Float32, reflect.Float64:
+		return kindFloat, nil, nullable
+	case reflect.String:
+		return kindString, nil, nullable
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return kindBytes, nil, true
+		}
+		return kindList, t.Elem(), true
+	case reflect.Array:
+		return kindList, t.Elem(), nullable
+	case reflect.Struct:
+		return kindRecord, t, nullable
+	case reflect.Map:
+		return kindJSON, nil, true
+	default:
+		return kindJSON, nil, true
+	}
+}
+
+// CodecNumberTag is the tag of the protobuf field numbers, such as `json:"order_id" pb:"1"`. The numbers
+// are given to the fields of all the structs in the payloads, such that they do not change when the
+// fields are added or removed. The fields of the structs without the tags, such as those of cosmos-sdk,
+// are numbered from 1 in the order of the JSON encoding.
+const CodecNumberTag = "pb"
+
+// codecField is a field of a record
+type codecField struct {
+	Name   string
+	Number int
+	Type   reflect.Type
+	// the ",string" option makes the value a string
+	Quoted    bool
+	OmitEmpty bool
+}
+
+var codecFieldsCache sync.Map
+
+// codecFields returns the fields of a struct marshaled by encoding/json, where the fields of the
+// embedded structs are flattened
+func codecFields(t reflect.Type) []codecField {
+	if fields, ok := codecFieldsCache.Load(t); ok {
+		return fields.([]codecField)
+	}
+	var fields []codecField
+	appendCodecFields(t, &fields)
+	if !isNumbered(fields) {
+		for i := range fields {
+			fields[i].Number = i + 1
+		}
+	}
+	codecFieldsCache.Store(t, fields)
+	return fields
+}
+
+// checkUnknownFields returns an error if the JSON object has a field which is not in the struct,
+// which would be dropped by the codecs
+func checkUnknownFields(t reflect.Type, obj map[string]interface{}) error {
+	fields := codecFields(t)
+	for name := range obj {
+		known := false
+		for _, f := range fields {
+			if f.Name == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown field %s of %s", name, t)
+		}
+	}
+	return nil
+}
+
+// hasCodecNumbers returns true if the fields of the struct are numbered by their CodecNumberTag tags
+func hasCodecNumbers(t reflect.Type) bool {
+	var fields []codecField
+	appendCodecFields(t, &fields)
+	return isNumbered(fields)
+}
+
+func isNumbered(fields []codecField) bool {
+	for _, f := range fields {
+		if f.Number != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCodecType returns an error if the fields of a struct in the type are not all numbered, or two
+// of them have the same number
+func checkCodecType(t reflect.Type) error {
+	checked := make(map[reflect.Type]bool)
+	var check func(t reflect.Type) error
+	check = func(t reflect.Type) error {
+		kind, elem, _ := codecTypeOf(t)
+		if kind == kindList {
+			return check(elem)
+		}
+		if kind != kindRecord || checked[elem] {
+			return nil
+		}
+		checked[elem] = true
+		fields := codecFields(elem)
+		numbers := make(map[int]string, len(fields))
+		for _, f := range fields {
+			if f.Number <= 0 {
+				return fmt.Errorf("field %s of %s has no valid %s tag", f.Name, elem, CodecNumberTag)
+			}
+			if other, ok := numbers[f.Number]; ok {
+				return fmt.Errorf("fields %s and %s of %s have the same number %d", other, f.Name, elem, f.Number)
+			}
+			numbers[f.Number] = f.Name
+			if err := check(f.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(t)
+}
+
+func appendCodecFields(t reflect.Type, fields *[]codecField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				appendCodecFields(ft, fields)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		// an invalid number is -1, which is reported by checkCodecType
+		number := 0
+		if tag, ok := field.Tag.Lookup(CodecNumberTag); ok {
+			if number, _ = strconv.Atoi(tag); number <= 0 {
+				number = -1
+			}
+		}
+		*fields = append(*fields, codecField{
+			Name:      name,
+			Number:    number,
+			Type:      field.Type,
+			Quoted:    strings.Contains(opts, "string"),
+			OmitEmpty: strings.Contains(opts, "omitempty"),
+		})
+	}
+}
+
+// the payloads are decoded into maps, slices, strings, json.Numbers and bools
+func decodeJSONValue(payload []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// jsonText returns the strings as they are, and the JSON text of the other values
+func jsonText(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	bz, _ := json.Marshal(v)
+	return string(bz)
+}
+
+func jsonObject(v interface{}) (map[string]interface{}, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expect an object, but got %s", jsonText(v))
+	}
+	return obj, nil
+}
+
+func jsonArray(v interface{}) ([]interface{}, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expect an array, but got %s", jsonText(v))
+	}
+	return arr, nil
+}
+
+func jsonNumber(v interface{}) (json.Number, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return "", fmt.Errorf("expect a number, but got %s", jsonText(v))
+	}
+	return n, nil
+}
+
+func jsonBool(v interface{}) (bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expect a bool, but got %s", jsonText(v))
+	}
+	return b, nil
+}
+
+// schemaNames gives the named types in a schema unique names, which are the names of the Go types
+// if they do not collide
+type schemaNames struct {
+	names map[reflect.Type]string
+	used  map[string]bool
+}
+
+func newSchemaNames() *schemaNames {
+	return &schemaNames{names: make(map[reflect.Type]string), used: make(map[string]bool)}
+}
+
+// name returns the name of the type, and whether it is named the first time
+func (sn *schemaNames) name(t reflect.Type) (string, bool) {
+	if name, ok := sn.names[t]; ok {
+		return name, false
+	}
+	base := schemaIdentifier(t.Name())
+	if t.Name() == "" {
+		base = "Record"
+	}
+	name := base
+	for i := 2; sn.used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	sn.names[t] = name
+	sn.used[name] = true
+	return name, true
+}
+
+// schemaIdentifier replaces the characters not allowed in the names of protobuf and Avro
+func schemaIdentifier(name string) string {
+	id := []byte(name)
+	for i, c := range id {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			id[i] = '_'
+		}
+	}
+	return string(id)
+}
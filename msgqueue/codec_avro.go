@@ -0,0 +1,260 @@
+package msgqueue
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// avroCodec encodes a payload in the Avro binary encoding of the record described by AvroSchema.
+// The values which may be null are unions of null and their types, and the missing values which
+// can not be null are encoded as the zero values.
+type avroCodec struct{}
+
+func (c avroCodec) Encode(key string, payload []byte) ([]byte, error) {
+	pt, ok := GetPayloadType(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown payload type : %s", key)
+	}
+	v, err := decodeJSONValue(payload)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := jsonObject(v); err != nil {
+		return nil, err
+	}
+	return appendAvroValue(nil, pt.Type, v, false)
+}
+
+func (c avroCodec) EncodeEnvelope(env Envelope, payload []byte) ([]byte, error) {
+	obj, err := codecEnvelopeObject(env, payload)
+	if err != nil {
+		return nil, err
+	}
+	return appendAvroRecord(nil, codecEnvelopeType.Type, obj)
+}
+
+func (c avroCodec) Name() string {
+	return FormatAvro
+}
+
+func appendAvroValue(buf []byte, t reflect.Type, v interface{}, nullable bool) ([]byte, error) {
+	if nullable {
+		if v == nil {
+			return appendAvroLong(buf, 0), nil
+		}
+		buf = appendAvroLong(buf, 1)
+	}
+	kind, elem, _ := codecTypeOf(t)
+	switch kind {
+	case kindString, kindJSON:
+		if v == nil && kind == kindString {
+			v = ""
+		}
+		return appendAvroBytes(buf, []byte(jsonText(v))), nil
+	case kindBool:
+		if v == nil {
+			v = false
+		}
+		b, err := jsonBool(v)
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case kindInt, kindUint:
+		if v == nil {
+			return appendAvroLong(buf, 0), nil
+		}
+		n, err := jsonNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		// the unsigned integers greater than math.MaxInt64 wrap around
+		i, err := strconv.ParseInt(n.String(), 10, 64)
+		if err != nil {
+			u, uerr := strconv.ParseUint(n.String(), 10, 64)
+			if uerr != nil {
+				return nil, err
+			}
+			i = int64(u)
+		}
+		return appendAvroLong(buf, i), nil
+	case kindFloat:
+		f := 0.0
+		if v != nil {
+			n, err := jsonNumber(v)
+			if err != nil {
+				return nil, err
+			}
+			if f, err = n.Float64(); err != nil {
+				return nil, err
+			}
+		}
+		var bz [8]byte
+		binary.LittleEndian.PutUint64(bz[:], math.Float64bits(f))
+		return append(buf, bz[:]...), nil
+	case kindBytes:
+		var bz []byte
+		if v != nil {
+			var err error
+			if bz, err = base64.StdEncoding.DecodeString(jsonText(v)); err != nil {
+				return nil, err
+			}
+		}
+		return appendAvroBytes(buf, bz), nil
+	case kindList:
+		var arr []interface{}
+		if v != nil {
+			var err error
+			if arr, err = jsonArray(v); err != nil {
+				return nil, err
+			}
+		}
+		return appendAvroArray(buf, elem, arr)
+	default:
+		obj := map[string]interface{}{}
+		if v != nil {
+			var err error
+			if obj, err = jsonObject(v); err != nil {
+				return nil, err
+			}
+		}
+		return appendAvroRecord(buf, elem, obj)
+	}
+}
+
+func appendAvroRecord(buf []byte, t reflect.Type, obj map[string]interface{}) ([]byte, error) {
+	if err := checkUnknownFields(t, obj); err != nil {
+		return nil, err
+	}
+	var err error
+	for _, f := range codecFields(t) {
+		v := obj[f.Name]
+		if f.Quoted {
+			if f.OmitEmpty && v == nil {
+				buf = appendAvroLong(buf, 0)
+				continue
+			}
+			if f.OmitEmpty {
+				buf = appendAvroLong(buf, 1)
+			}
+			if v == nil {
+				v = ""
+			}
+			buf = appendAvroBytes(buf, []byte(jsonText(v)))
+			continue
+		}
+		_, _, nullable := codecTypeOf(f.Type)
+		if buf, err = appendAvroValue(buf, f.Type, v, nullable || f.OmitEmpty); err != nil {
+			return nil, fmt.Errorf("%s: %s", f.Name, err.Error())
+		}
+	}
+	return buf, nil
+}
+
+// the items are written in one block, and the lists in lists are encoded as JSON text
+func appendAvroArray(buf []byte, elem reflect.Type, arr []interface{}) ([]byte, error) {
+	if len(arr) == 0 {
+		return appendAvroLong(buf, 0), nil
+	}
+	buf = appendAvroLong(buf, int64(len(arr)))
+	kind, _, nullable := codecTypeOf(elem)
+	for _, item := range arr {
+		var err error
+		if kind == kindList {
+			buf, err = appendAvroValue(buf, reflect.TypeOf(""), jsonText(item), false)
+		} else {
+			buf, err = appendAvroValue(buf, elem, item, nullable)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return appendAvroLong(buf, 0), nil
+}
+
+// the longs are zig-zag encoded varints
+func appendAvroLong(buf []byte, i int64) []byte {
+	var bz [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(bz[:], i)
+	return append(buf, bz[:n]...)
+}
+
+func appendAvroBytes(buf []byte, bz []byte) []byte {
+	buf = appendAvroLong(buf, int64(len(bz)))
+	return append(buf, bz...)
+}
+
+// AvroSchema describes the payloads of this type as an Avro record
+func (pt PayloadType) AvroSchema() map[string]interface{} {
+	names := newSchemaNames()
+	schema := avroRecordSchema(pt.Type, names).(map[string]interface{})
+	schema["namespace"] = fmt.Sprintf("coinexchain.%s.v%d", schemaIdentifier(pt.Module), pt.Version)
+	schema["doc"] = pt.Key
+	return schema
+}
+
+// a record is defined where it first appears, and referred to by its name afterwards
+func avroRecordSchema(t reflect.Type, names *schemaNames) interface{} {
+	name, isNew := names.name(t)
+	if !isNew {
+		return name
+	}
+	fields := make([]interface{}, 0)
+	for _, f := range codecFields(t) {
+		var typ interface{}
+		nullable := f.OmitEmpty
+		if f.Quoted {
+			typ = "string"
+		} else {
+			var typeNullable bool
+			typ = avroTypeSchema(f.Type, names)
+			_, _, typeNullable = codecTypeOf(f.Type)
+			nullable = nullable || typeNullable
+		}
+		field := map[string]interface{}{"name": schemaIdentifier(f.Name), "type": typ}
+		if nullable {
+			field["type"] = []interface{}{"null", typ}
+			field["default"] = nil
+		}
+		fields = append(fields, field)
+	}
+	return map[string]interface{}{
+		"type":   "record",
+		"name":   name,
+		"fields": fields,
+	}
+}
+
+func avroTypeSchema(t reflect.Type, names *schemaNames) interface{} {
+	kind, elem, _ := codecTypeOf(t)
+	switch kind {
+	case kindBool:
+		return "boolean"
+	case kindInt, kindUint:
+		return "long"
+	case kindFloat:
+		return "double"
+	case kindBytes:
+		return "bytes"
+	case kindRecord:
+		return avroRecordSchema(elem, names)
+	case kindList:
+		var items interface{} = "string"
+		if elemKind, _, nullable := codecTypeOf(elem); elemKind != kindList {
+			items = avroTypeSchema(elem, names)
+			if nullable {
+				items = []interface{}{"null", items}
+			}
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	default:
+		return "string"
+	}
+}
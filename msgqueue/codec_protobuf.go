@@ -0,0 +1,256 @@
+package msgqueue
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+)
+
+// protobufCodec encodes a payload as the proto3 message described by ProtoSchema. The null values
+// are omitted, and the repeated fields are not packed.
+type protobufCodec struct{}
+
+func (c protobufCodec) Encode(key string, payload []byte) ([]byte, error) {
+	pt, ok := GetPayloadType(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown payload type : %s", key)
+	}
+	v, err := decodeJSONValue(payload)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := jsonObject(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendProtoMessage(nil, pt.Type, obj)
+}
+
+func (c protobufCodec) EncodeEnvelope(env Envelope, payload []byte) ([]byte, error) {
+	obj, err := codecEnvelopeObject(env, payload)
+	if err != nil {
+		return nil, err
+	}
+	return appendProtoMessage(nil, codecEnvelopeType.Type, obj)
+}
+
+func (c protobufCodec) Name() string {
+	return FormatProtobuf
+}
+
+func appendProtoMessage(buf []byte, t reflect.Type, obj map[string]interface{}) ([]byte, error) {
+	if err := checkUnknownFields(t, obj); err != nil {
+		return nil, err
+	}
+	var err error
+	for _, f := range codecFields(t) {
+		v, ok := obj[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+		if f.Quoted {
+			buf = appendProtoBytes(buf, f.Number, []byte(jsonText(v)))
+			continue
+		}
+		kind, elem, _ := codecTypeOf(f.Type)
+		if kind != kindList {
+			if buf, err = appendProtoField(buf, f.Number, f.Type, v); err != nil {
+				return nil, fmt.Errorf("%s: %s", f.Name, err.Error())
+			}
+			continue
+		}
+		arr, err := jsonArray(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", f.Name, err.Error())
+		}
+		for _, item := range arr {
+			if buf, err = appendProtoField(buf, f.Number, elem, item); err != nil {
+				return nil, fmt.Errorf("%s: %s", f.Name, err.Error())
+			}
+		}
+	}
+	return buf, nil
+}
+
+// appendProtoField appends a value which is not a list. The lists in lists are encoded as JSON text.
+func appendProtoField(buf []byte, number int, t reflect.Type, v interface{}) ([]byte, error) {
+	kind, _, _ := codecTypeOf(t)
+	switch kind {
+	case kindString, kindJSON, kindList:
+		if v == nil && kind == kindString {
+			v = ""
+		}
+		return appendProtoBytes(buf, number, []byte(jsonText(v))), nil
+	case kindBool:
+		b, err := jsonBool(v)
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return appendProtoVarint(buf, number, 1), nil
+		}
+		return appendProtoVarint(buf, number, 0), nil
+	case kindInt:
+		n, err := jsonNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		i, err := strconv.ParseInt(n.String(), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return appendProtoVarint(buf, number, uint64(i)), nil
+	case kindUint:
+		n, err := jsonNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		u, err := strconv.ParseUint(n.String(), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return appendProtoVarint(buf, number, u), nil
+	case kindFloat:
+		n, err := jsonNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, err
+		}
+		buf = appendProtoTag(buf, number, protoWireFixed64)
+		var bz [8]byte
+		binary.LittleEndian.PutUint64(bz[:], math.Float64bits(f))
+		return append(buf, bz[:]...), nil
+	case kindBytes:
+		bz, err := base64.StdEncoding.DecodeString(jsonText(v))
+		if err != nil {
+			return nil, err
+		}
+		return appendProtoBytes(buf, number, bz), nil
+	default:
+		// the null items of the repeated messages are encoded as empty messages
+		obj := map[string]interface{}{}
+		if v != nil {
+			var err error
+			if obj, err = jsonObject(v); err != nil {
+				return nil, err
+			}
+		}
+		msg, err := appendProtoMessage(nil, derefType(t), obj)
+		if err != nil {
+			return nil, err
+		}
+		return appendProtoBytes(buf, number, msg), nil
+	}
+}
+
+func appendProtoTag(buf []byte, number int, wireType int) []byte {
+	return appendUvarint(buf, uint64(number)<<3|uint64(wireType))
+}
+
+func appendProtoVarint(buf []byte, number int, u uint64) []byte {
+	return appendUvarint(appendProtoTag(buf, number, protoWireVarint), u)
+}
+
+func appendProtoBytes(buf []byte, number int, bz []byte) []byte {
+	buf = appendUvarint(appendProtoTag(buf, number, protoWireBytes), uint64(len(bz)))
+	return append(buf, bz...)
+}
+
+func appendUvarint(buf []byte, u uint64) []byte {
+	var bz [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(bz[:], u)
+	return append(buf, bz[:n]...)
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// ProtoSchema describes the payloads of this type as proto3 messages, whose field numbers follow
+// the order of the fields in the JSON encoding
+func (pt PayloadType) ProtoSchema() string {
+	var sb strings.Builder
+	sb.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&sb, "package coinexchain.%s.v%d;\n", schemaIdentifier(pt.Module), pt.Version)
+
+	names := newSchemaNames()
+	queue := []reflect.Type{pt.Type}
+	names.name(pt.Type)
+	for len(queue) != 0 {
+		t := queue[0]
+		queue = queue[1:]
+		name, _ := names.name(t)
+		if t == pt.Type {
+			fmt.Fprintf(&sb, "\n// %s\nmessage %s {\n", pt.Key, name)
+		} else {
+			fmt.Fprintf(&sb, "\nmessage %s {\n", name)
+		}
+		for _, f := range codecFields(t) {
+			typ, repeated, record := protoFieldType(f, names)
+			if record != nil {
+				queue = append(queue, record)
+			}
+			if repeated {
+				typ = "repeated " + typ
+			}
+			fmt.Fprintf(&sb, "  %s %s = %d;\n", typ, schemaIdentifier(f.Name), f.Number)
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+// protoFieldType returns the type of a field, whether it is repeated, and the struct whose message
+// needs to be defined
+func protoFieldType(f codecField, names *schemaNames) (string, bool, reflect.Type) {
+	if f.Quoted {
+		return "string", false, nil
+	}
+	kind, elem, _ := codecTypeOf(f.Type)
+	if kind != kindList {
+		typ, record := protoScalarType(f.Type, names)
+		return typ, false, record
+	}
+	typ, record := protoScalarType(elem, names)
+	return typ, true, record
+}
+
+func protoScalarType(t reflect.Type, names *schemaNames) (string, reflect.Type) {
+	kind, elem, _ := codecTypeOf(t)
+	switch kind {
+	case kindBool:
+		return "bool", nil
+	case kindInt:
+		return "int64", nil
+	case kindUint:
+		return "uint64", nil
+	case kindFloat:
+		return "double", nil
+	case kindBytes:
+		return "bytes", nil
+	case kindRecord:
+		name, isNew := names.name(elem)
+		if isNew {
+			return name, elem
+		}
+		return name, nil
+	default:
+		return "string", nil
+	}
+}
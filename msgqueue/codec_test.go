@@ -0,0 +1,184 @@
+package msgqueue
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// the field numbered 6 has been removed
+type codecPayload struct {
+	ID     uint64    `json:"id" pb:"1"`
+	Price  sdk.Dec   `json:"price" pb:"2"`
+	Buy    bool      `json:"buy" pb:"3"`
+	Amount sdk.Coins `json:"amount" pb:"4"`
+	Memo   *string   `json:"memo,omitempty" pb:"5"`
+	Delta  int64     `json:"delta,string" pb:"7"`
+}
+
+const codecPayloadJSON = `{"id":300,"price":"1.5","buy":true,"amount":[{"denom":"cet","amount":"10"}],"delta":"-2"}`
+
+func TestProtobufCodec(t *testing.T) {
+	RegisterPayload("test", "codec_payload", 1, codecPayload{})
+	codec, err := NewCodec(FormatProtobuf)
+	require.NoError(t, err)
+
+	bz, err := codec.Encode("codec_payload", []byte(codecPayloadJSON))
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		0x08, 0xac, 0x02,
+		0x12, 0x03, '1', '.', '5',
+		0x18, 0x01,
+		0x22, 0x09, 0x0a, 0x03, 'c', 'e', 't', 0x12, 0x02, '1', '0',
+		0x3a, 0x02, '-', '2',
+	}, bz)
+
+	_, err = codec.Encode("unknown", []byte(codecPayloadJSON))
+	require.Error(t, err)
+	_, err = codec.Encode("codec_payload", []byte(`{"id":"x"}`))
+	require.Error(t, err)
+
+	pt, _ := GetPayloadType("codec_payload")
+	require.Equal(t, `syntax = "proto3";
+
+package coinexchain.test.v1;
+
+// codec_payload
+message codecPayload {
+  uint64 id = 1;
+  string price = 2;
+  bool buy = 3;
+  repeated Coin amount = 4;
+  string memo = 5;
+  string delta = 7;
+}
+
+message Coin {
+  string denom = 1;
+  string amount = 2;
+}
+`, pt.ProtoSchema())
+}
+
+func TestAvroCodec(t *testing.T) {
+	RegisterPayload("test", "codec_payload", 1, codecPayload{})
+	codec, err := NewCodec(FormatAvro)
+	require.NoError(t, err)
+
+	bz, err := codec.Encode("codec_payload", []byte(codecPayloadJSON))
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		0xd8, 0x04,
+		0x06, '1', '.', '5',
+		0x01,
+		0x02, 0x06, 'c', 'e', 't', 0x04, '1', '0', 0x00,
+		0x00,
+		0x04, '-', '2',
+	}, bz)
+
+	// the missing values are zero
+	bz, err = codec.Encode("codec_payload", []byte(`{"memo":"hi"}`))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x00, 0x00, 0x00, 0x00, 0x02, 0x04, 'h', 'i', 0x00}, bz)
+
+	pt, _ := GetPayloadType("codec_payload")
+	schema, err := json.Marshal(pt.AvroSchema())
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "record",
+		"name": "codecPayload",
+		"namespace": "coinexchain.test.v1",
+		"doc": "codec_payload",
+		"fields": [
+			{"name": "id", "type": "long"},
+			{"name": "price", "type": "string"},
+			{"name": "buy", "type": "boolean"},
+			{"name": "amount", "type": {"type": "array", "items": {"type": "record", "name": "Coin", "fields": [
+				{"name": "denom", "type": "string"},
+				{"name": "amount", "type": "string"}
+			]}}},
+			{"name": "memo", "type": ["null", "string"], "default": null},
+			{"name": "delta", "type": "string"}
+		]
+	}`, string(schema))
+}
+
+func TestEncodeEnvelope(t *testing.T) {
+	RegisterPayload("test", "codec_payload", 1, codecPayload{})
+	env := NewEnvelope(7, "AB", 2, "codec_payload", []byte(`{"buy":true}`)).Bytes()
+
+	// the payload is encoded inside the envelope
+	bz, err := encodePayload(protobufCodec{}, []byte("codec_payload"), env)
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		0x08, 0x01,
+		0x12, 0x04, 't', 'e', 's', 't',
+		0x1a, 0x0d, 'c', 'o', 'd', 'e', 'c', '_', 'p', 'a', 'y', 'l', 'o', 'a', 'd',
+		0x20, 0x07,
+		0x2a, 0x02, 'A', 'B',
+		0x30, 0x02,
+		0x3a, 0x02, 0x18, 0x01,
+	}, bz)
+	bz, err = encodePayload(avroCodec{}, []byte("codec_payload"), env)
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		0x02,
+		0x08, 't', 'e', 's', 't',
+		0x1a, 'c', 'o', 'd', 'e', 'c', '_', 'p', 'a', 'y', 'l', 'o', 'a', 'd',
+		0x0e,
+		0x04, 'A', 'B',
+		0x04,
+		0x02, 0x0c, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+	}, bz)
+	bz, err = encodePayload(jsonCodec{}, []byte("codec_payload"), env)
+	require.NoError(t, err)
+	require.Equal(t, env, bz)
+
+	// the codecs do not drop the fields which are not in the payloads
+	_, err = protobufCodec{}.Encode("codec_payload", env)
+	require.Error(t, err)
+	_, err = avroCodec{}.Encode("codec_payload", env)
+	require.Error(t, err)
+	_, err = encodePayload(protobufCodec{}, []byte("codec_payload"),
+		NewEnvelope(7, "AB", 2, "codec_payload", []byte(`{"buy":true,"sell":false}`)).Bytes())
+	require.True(t, isEncodeError(err))
+
+	// the envelopes of the unregistered payloads are written as JSON
+	env = NewEnvelope(7, "AB", 2, "unknown", []byte(`{"buy":true}`)).Bytes()
+	bz, err = encodePayload(protobufCodec{}, []byte("unknown"), env)
+	require.NoError(t, err)
+	require.Equal(t, env, bz)
+}
+
+func TestCodecMsgWriter(t *testing.T) {
+	RegisterPayload("test", "codec_payload", 1, codecPayload{})
+
+	_, err := createMsgWriter("os:stdout?format=xml")
+	require.Error(t, err)
+
+	defer os.Remove("messages.txt")
+	w, err := createMsgWriter("file:messages.txt?format=protobuf")
+	require.NoError(t, err)
+	require.Equal(t, "file?format=protobuf", w.String())
+	require.NoError(t, w.WriteKV([]byte("codec_payload"), []byte(`{"buy":true}`)))
+	// the unregistered payloads are written as JSON
+	require.NoError(t, w.WriteKV([]byte("unknown"), []byte(`{"buy":true}`)))
+	// the registered payloads which can not be encoded are not written
+	err = w.WriteKV([]byte("codec_payload"), []byte(`{"buy":"yes"}`))
+	require.Error(t, err)
+	require.True(t, isEncodeError(err))
+	require.NoError(t, w.Close())
+
+	data, err := ioutil.ReadFile("messages.txt")
+	require.NoError(t, err)
+	require.Equal(t, "codec_payload#\x18\x01\r\nunknown#{\"buy\":true}\r\n", string(data))
+
+	w, err = createMsgWriter("nop?format=json")
+	require.NoError(t, err)
+	require.Equal(t, "nop", w.String())
+}
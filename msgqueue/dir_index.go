@@ -0,0 +1,170 @@
+package msgqueue
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// IndexFileName is the name of the file in the dir which maps the block heights to the segments
+const IndexFileName = "index.json"
+
+// SegmentInfo tells which blocks have messages in a segment file of the dir writer. A block may have
+// messages in two adjacent segments, if the first one is rotated by size in the middle of the block.
+type SegmentInfo struct {
+	Index int `json:"index"`
+	// the name of the file in the dir, with a suffix if it is compressed
+	File string `json:"file"`
+	// the heights are zero until the messages of a block are committed in the segment
+	FirstHeight int64 `json:"first_height"`
+	LastHeight  int64 `json:"last_height"`
+	// the unix time when the segment is created
+	CreatedAt int64 `json:"created_at"`
+}
+
+// Contains returns whether the segment may have the messages of the block at 'height'
+func (s SegmentInfo) Contains(height int64) bool {
+	return s.FirstHeight != 0 && s.FirstHeight <= height && height <= s.LastHeight
+}
+
+// SegmentIndex is kept in the index file, which is replaced atomically whenever it is saved.
+// The last height of the open segment is saved only when the segment is closed.
+type SegmentIndex struct {
+	path     string
+	mtx      sync.Mutex
+	segments []SegmentInfo
+	// the writer and its archiving goroutine may save the index at the same time
+	saveMtx sync.Mutex
+}
+
+// LoadSegmentIndex loads the index file in the dir, and the index is empty if the file does not exist
+func LoadSegmentIndex(dir string) (*SegmentIndex, error) {
+	idx := &SegmentIndex{path: filepath.Join(dir, IndexFileName)}
+	bz, err := ioutil.ReadFile(idx.path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var content struct {
+		Segments []SegmentInfo `json:"segments"`
+	}
+	if err := json.Unmarshal(bz, &content); err != nil {
+		return nil, err
+	}
+	idx.segments = content.Segments
+	sort.Slice(idx.segments, func(i, j int) bool {
+		return idx.segments[i].Index < idx.segments[j].Index
+	})
+	return idx, nil
+}
+
+func (idx *SegmentIndex) Save() error {
+	idx.saveMtx.Lock()
+	defer idx.saveMtx.Unlock()
+	idx.mtx.Lock()
+	content := struct {
+		Segments []SegmentInfo `json:"segments"`
+	}{Segments: append([]SegmentInfo{}, idx.segments...)}
+	idx.mtx.Unlock()
+
+	bz, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := idx.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, bz, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, idx.path)
+}
+
+// Segments returns all the segments in the order of their indexes
+func (idx *SegmentIndex) Segments() []SegmentInfo {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return append([]SegmentInfo{}, idx.segments...)
+}
+
+// Lookup returns the first segment which may have the messages of the block at 'height'
+func (idx *SegmentIndex) Lookup(height int64) (SegmentInfo, bool) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	for _, s := range idx.segments {
+		if s.Contains(height) {
+			return s, true
+		}
+	}
+	return SegmentInfo{}, false
+}
+
+// segment returns the segment numbered 'index'
+func (idx *SegmentIndex) segment(index int) (SegmentInfo, bool) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	if i := idx.find(index); i >= 0 {
+		return idx.segments[i], true
+	}
+	return SegmentInfo{}, false
+}
+
+func (idx *SegmentIndex) find(index int) int {
+	for i, s := range idx.segments {
+		if s.Index == index {
+			return i
+		}
+	}
+	return -1
+}
+
+// open adds a segment unless it is already in the index, and returns the segment in the index
+func (idx *SegmentIndex) open(index int, file string, createdAt int64) SegmentInfo {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	if i := idx.find(index); i >= 0 {
+		return idx.segments[i]
+	}
+	s := SegmentInfo{Index: index, File: file, CreatedAt: createdAt}
+	idx.segments = append(idx.segments, s)
+	sort.Slice(idx.segments, func(i, j int) bool {
+		return idx.segments[i].Index < idx.segments[j].Index
+	})
+	return s
+}
+
+// commit records that the segment has messages of the block at 'height', and returns the segment
+func (idx *SegmentIndex) commit(index int, height int64) SegmentInfo {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	i := idx.find(index)
+	if i < 0 {
+		return SegmentInfo{}
+	}
+	s := &idx.segments[i]
+	if s.FirstHeight == 0 || height < s.FirstHeight {
+		s.FirstHeight = height
+	}
+	if height > s.LastHeight {
+		s.LastHeight = height
+	}
+	return *s
+}
+
+func (idx *SegmentIndex) rename(index int, file string) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	if i := idx.find(index); i >= 0 {
+		idx.segments[i].File = file
+	}
+}
+
+func (idx *SegmentIndex) remove(index int) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	if i := idx.find(index); i >= 0 {
+		idx.segments = append(idx.segments[:i], idx.segments[i+1:]...)
+	}
+}
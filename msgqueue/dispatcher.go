@@ -0,0 +1,178 @@
+package msgqueue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+const (
+	dispatchBatchSize = 256
+	maxRetryInterval  = 10 * time.Second
+)
+
+// dispatcher drains the outbox into one writer in its own goroutine, such that a slow or broken
+// writer never blocks the consensus. The offset of the writer is committed after every message is
+// written, so a message is written again only if the node crashes right after writing it.
+type dispatcher struct {
+	name   string
+	writer MsgWriter
+	outbox *Outbox
+	offset Position
+	log    log.Logger
+	// the height of the last block committed to the writer
+	committed int64
+	// the position of the last message written to a MsgFlusher, which is acked after it is flushed
+	unflushed *Position
+
+	notify chan struct{}
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+// the name identifies the offset of the writer in the outbox, so it must not change after restarts
+func newDispatcher(name string, writer MsgWriter, outbox *Outbox, log log.Logger) *dispatcher {
+	return &dispatcher{
+		name:   name,
+		writer: writer,
+		outbox: outbox,
+		offset: outbox.Offset(name),
+		log:    log,
+		notify: make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (d *dispatcher) start() {
+	go d.run()
+}
+
+// wake tells the dispatcher that new messages are stored in the outbox
+func (d *dispatcher) wake() {
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+}
+
+// stop waits for the dispatcher to finish the message being written
+func (d *dispatcher) stop() {
+	close(d.quit)
+	<-d.done
+}
+
+func (d *dispatcher) run() {
+	defer close(d.done)
+	for d.drain() {
+		select {
+		case <-d.notify:
+		case <-d.quit:
+			return
+		}
+	}
+}
+
+// write all the messages after the offset, and return false if the dispatcher is stopped
+func (d *dispatcher) drain() bool {
+	for {
+		msgs, err := d.outbox.Read(d.offset, dispatchBatchSize)
+		if err != nil && d.log != nil {
+			d.log.Error(fmt.Sprintf("read msg after %s for %s failed, err : %s\n", d.offset, d.name, err.Error()))
+		}
+		if len(msgs) == 0 {
+			// the blocks are appended to the outbox atomically, so the block at the offset is complete
+			if !d.flush() {
+				return false
+			}
+			d.commitBlock(d.offset.Height)
+			return true
+		}
+		for _, msg := range msgs {
+			if msg.Height > d.offset.Height {
+				if !d.flush() {
+					return false
+				}
+				d.commitBlock(d.offset.Height)
+			}
+			if !d.write(msg) {
+				return false
+			}
+			if _, ok := d.writer.(MsgFlusher); ok {
+				pos := msg.Position
+				d.unflushed = &pos
+			} else {
+				d.outbox.Ack(d.name, msg.Position)
+			}
+			d.offset = msg.Position
+		}
+	}
+}
+
+// tell the writer that all the messages of the block have been written
+func (d *dispatcher) commitBlock(height int64) {
+	if height <= d.committed {
+		return
+	}
+	if err := commitBlock(d.writer, height); err != nil && d.log != nil {
+		d.log.Error(fmt.Sprintf("commit block %d to %s failed, err : %s\n", height, d.name, err.Error()))
+	}
+	d.committed = height
+}
+
+// flush the messages written to a MsgFlusher and ack them, retrying with exponential back-off until
+// they are flushed. It returns false if the dispatcher is stopped, and the messages are not acked.
+func (d *dispatcher) flush() bool {
+	if d.unflushed == nil {
+		return true
+	}
+	flusher := d.writer.(MsgFlusher)
+	interval := time.Millisecond
+	for {
+		err := flusher.Flush()
+		if err == nil {
+			break
+		}
+		if d.log != nil {
+			d.log.Error(fmt.Sprintf("flush msgs to %s failed, err : %s\n", d.name, err.Error()))
+		}
+		select {
+		case <-d.quit:
+			return false
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+	d.outbox.Ack(d.name, *d.unflushed)
+	d.unflushed = nil
+	return true
+}
+
+// retry with exponential back-off until the message is written or the dispatcher is stopped.
+// The message whose payload can not be encoded is skipped.
+func (d *dispatcher) write(msg OutboxMsg) bool {
+	interval := time.Millisecond
+	for {
+		err := d.writer.WriteKV(msg.Key, msg.Value)
+		if err == nil {
+			return true
+		}
+		if d.log != nil {
+			d.log.Error(fmt.Sprintf("write msg %s to %s failed, err : %s\n", msg.Position, d.name, err.Error()))
+		}
+		if isEncodeError(err) {
+			return true
+		}
+		select {
+		case <-d.quit:
+			return false
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
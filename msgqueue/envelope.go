@@ -0,0 +1,218 @@
+package msgqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// Envelope wraps the payload of a message with the information about where it comes from, such that
+// the consumers can tell the messages of different versions apart and locate them in the chain
+type Envelope struct {
+	// the version of the payload's schema, and zero for the payloads which are not registered
+	SchemaVersion int    `json:"schema_version"`
+	Module        string `json:"module"`
+	Type          string `json:"type"`
+	Height        int64  `json:"height"`
+	// empty for the messages sent in BeginBlock and EndBlock
+	TxHash string `json:"tx_hash,omitempty"`
+	// the index of this message among all the messages of the block
+	Index   int             `json:"index"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func NewEnvelope(height int64, txHash string, index int, key string, payload []byte) Envelope {
+	env := Envelope{
+		Type:    key,
+		Height:  height,
+		TxHash:  txHash,
+		Index:   index,
+		Payload: json.RawMessage(payload),
+	}
+	if pt, ok := GetPayloadType(key); ok {
+		env.SchemaVersion = pt.Version
+		env.Module = pt.Module
+	}
+	return env
+}
+
+func (env Envelope) Bytes() []byte {
+	bz, err := json.Marshal(env)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func ParseEnvelope(bz []byte) (env Envelope, err error) {
+	err = json.Unmarshal(bz, &env)
+	return
+}
+
+// DecodePayload unmarshals the payload into a new value of its registered type
+func (env Envelope) DecodePayload() (interface{}, error) {
+	pt, ok := GetPayloadType(env.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown payload type : %s", env.Type)
+	}
+	if pt.Version != env.SchemaVersion {
+		return nil, fmt.Errorf("payload %s is of version %d, but version %d is registered", env.Type, env.SchemaVersion, pt.Version)
+	}
+	ptr := reflect.New(pt.Type)
+	if err := json.Unmarshal(env.Payload, ptr.Interface()); err != nil {
+		return nil, err
+	}
+	return ptr.Interface(), nil
+}
+
+// WrapEvents wraps the messages in the events emitted by a transaction, or by BeginBlock and EndBlock,
+// into envelopes. 'index' is the index in the block of the first message in these events.
+func WrapEvents(events []abci.Event, height int64, txHash string, index int) []Envelope {
+	var envs []Envelope
+	for _, event := range events {
+		if event.Type != EventTypeMsgQueue {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			envs = append(envs, NewEnvelope(height, txHash, index, string(attr.Key), attr.Value))
+			index++
+		}
+	}
+	return envs
+}
+
+// SendEvents sends the messages in the events emitted by a transaction, or by BeginBlock and EndBlock, in
+// envelopes with their types as the keys. The app calls it for the events of every transaction and of
+// BeginBlock and EndBlock, and the index of the next message in the block is returned.
+func SendEvents(sender MsgSender, events []abci.Event, height int64, txHash string, index int) int {
+	envs := WrapEvents(events, height, txHash, index)
+	for _, env := range envs {
+		sender.SendMsg([]byte(env.Type), env.Bytes())
+	}
+	return index + len(envs)
+}
+
+// unwrapEnvelope returns the envelope in which SendEvents has sent the payload with the key. It returns
+// false if the value is not an envelope but the payload itself.
+func unwrapEnvelope(key, value []byte) (Envelope, bool) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(value, &fields) != nil {
+		return Envelope{}, false
+	}
+	for _, name := range []string{"schema_version", "type", "height", "index", "payload"} {
+		if _, ok := fields[name]; !ok {
+			return Envelope{}, false
+		}
+	}
+	env, err := ParseEnvelope(value)
+	if err != nil || env.Type != string(key) {
+		return Envelope{}, false
+	}
+	return env, true
+}
+
+// payloadOf returns the payload in the value of a message, whose fields are used to encode, route
+// and filter the message
+func payloadOf(key, value []byte) []byte {
+	if env, ok := unwrapEnvelope(key, value); ok {
+		return env.Payload
+	}
+	return value
+}
+
+// CodecEnvelope is an envelope encoded by the protobuf and Avro codecs, whose payload is encoded by
+// the same codec. Its schemas are EnvelopeProtoSchema and EnvelopeAvroSchema.
+type CodecEnvelope struct {
+	SchemaVersion int    `json:"schema_version" pb:"1"`
+	Module        string `json:"module" pb:"2"`
+	Type          string `json:"type" pb:"3"`
+	Height        int64  `json:"height" pb:"4"`
+	TxHash        string `json:"tx_hash" pb:"5"`
+	Index         int    `json:"index" pb:"6"`
+	Payload       []byte `json:"payload" pb:"7"`
+}
+
+var codecEnvelopeType = PayloadType{Module: "envelope", Key: "envelope", Version: 1, Type: reflect.TypeOf(CodecEnvelope{})}
+
+func EnvelopeProtoSchema() string {
+	return codecEnvelopeType.ProtoSchema()
+}
+
+func EnvelopeAvroSchema() map[string]interface{} {
+	return codecEnvelopeType.AvroSchema()
+}
+
+// codecEnvelopeObject returns the decoded JSON of the CodecEnvelope with the encoded payload
+func codecEnvelopeObject(env Envelope, payload []byte) (map[string]interface{}, error) {
+	bz, err := json.Marshal(CodecEnvelope{
+		SchemaVersion: env.SchemaVersion,
+		Module:        env.Module,
+		Type:          env.Type,
+		Height:        env.Height,
+		TxHash:        env.TxHash,
+		Index:         env.Index,
+		Payload:       payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	v, err := decodeJSONValue(bz)
+	if err != nil {
+		return nil, err
+	}
+	return jsonObject(v)
+}
+
+// PayloadType is the registered Go type of the payloads sent with one key
+type PayloadType struct {
+	Module  string
+	Key     string
+	Version int
+	Type    reflect.Type
+}
+
+var payloadTypes = make(map[string]PayloadType)
+
+// RegisterPayload registers the type of the payloads sent by a module with the key. It is called in
+// init functions, and the version must be increased whenever the JSON encoding of the type changes.
+// The fields of the type must be numbered by their CodecNumberTag tags.
+func RegisterPayload(module, key string, version int, payload interface{}) {
+	t := reflect.TypeOf(payload)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || !hasCodecNumbers(t) {
+		panic(fmt.Sprintf("payload %s is not a struct with the %s tags", key, CodecNumberTag))
+	}
+	if err := checkCodecType(t); err != nil {
+		panic(fmt.Sprintf("payload %s: %s", key, err.Error()))
+	}
+	pt := PayloadType{Module: module, Key: key, Version: version, Type: t}
+	if old, ok := payloadTypes[key]; ok && old != pt {
+		panic(fmt.Sprintf("payload %s is registered by both %s and %s", key, old.Module, module))
+	}
+	payloadTypes[key] = pt
+}
+
+func GetPayloadType(key string) (PayloadType, bool) {
+	pt, ok := payloadTypes[key]
+	return pt, ok
+}
+
+// GetPayloadTypes returns all the registered payload types sorted by module and key
+func GetPayloadTypes() []PayloadType {
+	types := make([]PayloadType, 0, len(payloadTypes))
+	for _, pt := range payloadTypes {
+		types = append(types, pt)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Module != types[j].Module {
+			return types[i].Module < types[j].Module
+		}
+		return types[i].Key < types[j].Key
+	})
+	return types
+}
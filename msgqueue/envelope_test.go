@@ -0,0 +1,191 @@
+package msgqueue
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+type testPayload struct {
+	Sender  sdk.AccAddress `json:"sender" pb:"1"`
+	Price   sdk.Dec        `json:"price" pb:"2"`
+	Amount  sdk.Coins      `json:"amount" pb:"3"`
+	Side    byte           `json:"side" pb:"4"`
+	Tags    []string       `json:"tags,omitempty" pb:"5"`
+	Ignored int64          `json:"-"`
+	testEmbedded
+	hidden int64
+}
+
+type testEmbedded struct {
+	Height int64 `json:"height" pb:"6"`
+}
+
+func TestEnvelope(t *testing.T) {
+	RegisterPayload("test", "test_payload", 2, testPayload{})
+	// registering the same type again is harmless
+	RegisterPayload("test", "test_payload", 2, &testPayload{})
+	require.Panics(t, func() { RegisterPayload("other", "test_payload", 1, testPayload{}) })
+
+	// the fields of the payloads must be numbered without duplicates
+	type unnumbered struct {
+		Height int64 `json:"height"`
+	}
+	type partlyNumbered struct {
+		Height int64 `json:"height" pb:"1"`
+		Time   int64 `json:"time"`
+	}
+	type sameNumbers struct {
+		Height int64 `json:"height" pb:"1"`
+		Time   int64 `json:"time" pb:"1"`
+	}
+	type nestedRecord struct {
+		Inner []partlyNumbered `json:"inner" pb:"1"`
+	}
+	require.Panics(t, func() { RegisterPayload("test", "unnumbered", 1, unnumbered{}) })
+	require.Panics(t, func() { RegisterPayload("test", "partly_numbered", 1, partlyNumbered{}) })
+	require.Panics(t, func() { RegisterPayload("test", "same_numbers", 1, sameNumbers{}) })
+	require.Panics(t, func() { RegisterPayload("test", "nested_record", 1, nestedRecord{}) })
+	_, ok := GetPayloadType("nested_record")
+	require.False(t, ok)
+
+	events := []abci.Event{
+		{Type: EventTypeMsgQueue, Attributes: []cmn.KVPair{
+			{Key: []byte("test_payload"), Value: []byte(`{"side":1,"height":5}`)},
+			{Key: []byte("unknown"), Value: []byte(`{}`)},
+		}},
+		{Type: "message", Attributes: []cmn.KVPair{{Key: []byte("module"), Value: []byte("test")}}},
+	}
+	envs := WrapEvents(events, 5, "ABCD", 3)
+	require.Equal(t, 2, len(envs))
+	require.Equal(t, Envelope{SchemaVersion: 2, Module: "test", Type: "test_payload", Height: 5,
+		TxHash: "ABCD", Index: 3, Payload: json.RawMessage(`{"side":1,"height":5}`)}, envs[0])
+	require.Equal(t, 4, envs[1].Index)
+	require.Equal(t, 0, envs[1].SchemaVersion)
+
+	env, err := ParseEnvelope(envs[0].Bytes())
+	require.NoError(t, err)
+	require.Equal(t, envs[0], env)
+	payload, err := env.DecodePayload()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, payload.(*testPayload).Side)
+	require.EqualValues(t, 5, payload.(*testPayload).Height)
+
+	env.SchemaVersion = 1
+	_, err = env.DecodePayload()
+	require.Error(t, err)
+	_, err = envs[1].DecodePayload()
+	require.Error(t, err)
+
+	// the messages of BeginBlock and EndBlock have no tx hash
+	bz := NewEnvelope(5, "", 0, "unknown", []byte(`{}`)).Bytes()
+	require.Equal(t, `{"schema_version":0,"module":"","type":"unknown","height":5,"index":0,"payload":{}}`, string(bz))
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchemaOf(reflect.TypeOf(testPayload{}))
+	bz, err := json.Marshal(schema)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"sender": {"type": "string"},
+			"price": {"type": "string"},
+			"amount": {"type": "array", "items": {
+				"type": "object",
+				"properties": {"denom": {"type": "string"}, "amount": {"type": "string"}},
+				"required": ["denom", "amount"]
+			}},
+			"side": {"type": "integer"},
+			"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+			"height": {"type": "integer"}
+		},
+		"required": ["sender", "price", "amount", "side", "height"]
+	}`, string(bz))
+
+	pt, ok := GetPayloadType("test_payload")
+	require.True(t, ok)
+	schema = pt.JSONSchema()
+	require.Equal(t, "test/test_payload/v2", schema["$id"])
+	require.Equal(t, JSONSchemaDraft, schema["$schema"])
+
+	schema = EnvelopeJSONSchema()
+	require.Equal(t, map[string]interface{}{}, schema["properties"].(map[string]interface{})["payload"])
+	require.Equal(t, []string{"schema_version", "module", "type", "height", "index", "payload"}, schema["required"])
+}
+
+type pairPayload struct {
+	TradingPair string `json:"trading_pair" pb:"1"`
+	Amount      int64  `json:"amount" pb:"2"`
+}
+
+// the messages sent by the app in envelopes are encoded, routed and filtered by their payloads
+func TestSendEventsToWriters(t *testing.T) {
+	RegisterPayload("pair", "pair_payload", 1, pairPayload{})
+
+	dir, err := ioutil.TempDir("", "envelope")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fileWriter, err := createMsgWriter("file:" + filepath.Join(dir, "messages.txt") + "?format=protobuf")
+	require.NoError(t, err)
+	kafka := &fakeSyncProducer{}
+	routes := DefaultKafkaRoutes()
+	routes.Modules = map[string]KafkaRoute{"pair": {Topic: "pair", PartitionFields: []string{"trading_pair"}}}
+	kafkaWriter := kafkaMsgWriter{SyncProducer: kafka, routes: routes, codec: protobufCodec{}}
+	pushWriter := newTestPushWriter(t, "")
+	defer pushWriter.Close()
+	conn := dialPush(t, pushWriter, "trading_pair=xyz/cet")
+	defer conn.Close()
+	waitForClients(t, pushWriter, 1)
+
+	p := producer{
+		toggle:     true,
+		subTopics:  map[string]struct{}{"pair": {}},
+		msgWriters: []MsgWriter{fileWriter, kafkaWriter, pushWriter},
+	}
+	events := []abci.Event{{Type: EventTypeMsgQueue, Attributes: []cmn.KVPair{
+		{Key: []byte("pair_payload"), Value: []byte(`{"trading_pair":"abc/cet","amount":3}`)},
+		{Key: []byte("pair_payload"), Value: []byte(`{"trading_pair":"xyz/cet","amount":4}`)},
+	}}}
+	require.Equal(t, 2, SendEvents(p, events, 9, "AB", 0))
+	require.NoError(t, fileWriter.Close())
+
+	payload := []byte{0x0a, 0x07, 'a', 'b', 'c', '/', 'c', 'e', 't', 0x10, 0x03}
+	encoded := []byte{
+		0x08, 0x01,
+		0x12, 0x04, 'p', 'a', 'i', 'r',
+		0x1a, 0x0c, 'p', 'a', 'i', 'r', '_', 'p', 'a', 'y', 'l', 'o', 'a', 'd',
+		0x20, 0x09,
+		0x2a, 0x02, 'A', 'B',
+		0x30, 0x00,
+		0x3a, byte(len(payload)),
+	}
+	encoded = append(encoded, payload...)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "messages.txt"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), "pair_payload#"+string(encoded)+"\r\n"))
+
+	require.Len(t, kafka.msgs, 2)
+	require.Equal(t, "pair", kafka.msgs[0].Topic)
+	require.Equal(t, []byte("abc/cet"), kafka.msgs[0].Metadata)
+	require.Equal(t, sarama.ByteEncoder(encoded), kafka.msgs[0].Value)
+	require.Equal(t, []byte("xyz/cet"), kafka.msgs[1].Metadata)
+
+	msg := readPush(t, conn)
+	require.Equal(t, int64(9), msg.Height)
+	env, err := ParseEnvelope(msg.Payload)
+	require.NoError(t, err)
+	require.Equal(t, 1, env.Index)
+	require.JSONEq(t, `{"trading_pair":"xyz/cet","amount":4}`, string(env.Payload))
+}
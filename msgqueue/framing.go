@@ -0,0 +1,183 @@
+package msgqueue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/url"
+	"os"
+)
+
+// the framings of the records written by the file, dir and pipe writers
+const (
+	// "key#value\r\n", which is ambiguous if the key has '#' or the value has "\r\n"
+	FramingLine = "line"
+	// a magic header at the beginning of each file, then for each record: the lengths of the key and
+	// the value as big-endian uint32, the key, the value, and the big-endian CRC32 (IEEE) of all these
+	FramingRecord = "record"
+)
+
+// the option of the file, dir and pipe writers, which is FramingLine if not given
+const OptFraming = "framing"
+
+var recordFileMagic = []byte("CETMSGQ\x01")
+
+const (
+	recordHeaderSize = 8
+	recordCRCSize    = 4
+	maxRecordKeySize = 1 << 16
+	maxRecordSize    = 1 << 30
+)
+
+var ErrCorruptRecord = errors.New("corrupt record")
+
+func parseFraming(opts url.Values) (string, error) {
+	framing := opts.Get(OptFraming)
+	switch framing {
+	case "":
+		return FramingLine, nil
+	case FramingLine, FramingRecord:
+		return framing, nil
+	default:
+		return "", fmt.Errorf("invalid %s: %s", OptFraming, framing)
+	}
+}
+
+// fileHeader is written at the beginning of each file
+func fileHeader(framing string) []byte {
+	if framing == FramingRecord {
+		return recordFileMagic
+	}
+	return nil
+}
+
+func encodeRecord(framing string, k, v []byte) []byte {
+	if framing != FramingRecord {
+		buffer := bytes.NewBuffer(nil)
+		buffer.Write(k)
+		buffer.Write([]byte("#"))
+		buffer.Write(v)
+		buffer.Write([]byte("\r\n"))
+		return buffer.Bytes()
+	}
+	buf := make([]byte, recordHeaderSize, recordHeaderSize+len(k)+len(v)+recordCRCSize)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(k)))
+	binary.BigEndian.PutUint32(buf[4:8], uint32(len(v)))
+	buf = append(append(buf, k...), v...)
+	var crc [recordCRCSize]byte
+	binary.BigEndian.PutUint32(crc[:], crc32.ChecksumIEEE(buf))
+	return append(buf, crc[:]...)
+}
+
+// readFramedRecord reads one record after the magic header. It returns io.EOF if there are no more
+// records, io.ErrUnexpectedEOF if the record is torn, and ErrCorruptRecord if its checksum is wrong.
+func readFramedRecord(r *bufio.Reader) (k, v []byte, size int, err error) {
+	var header [recordHeaderSize]byte
+	n, err := io.ReadFull(r, header[:])
+	if err == io.EOF {
+		return nil, nil, 0, io.EOF
+	} else if err != nil {
+		return nil, nil, n, err
+	}
+	keyLen := binary.BigEndian.Uint32(header[0:4])
+	valueLen := binary.BigEndian.Uint32(header[4:8])
+	if keyLen > maxRecordKeySize || valueLen > maxRecordSize {
+		return nil, nil, n, ErrCorruptRecord
+	}
+	body := make([]byte, int(keyLen)+int(valueLen)+recordCRCSize)
+	m, err := io.ReadFull(r, body)
+	n += m
+	if err == io.EOF {
+		return nil, nil, n, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, nil, n, err
+	}
+	crc := crc32.NewIEEE()
+	crc.Write(header[:])
+	crc.Write(body[:len(body)-recordCRCSize])
+	if crc.Sum32() != binary.BigEndian.Uint32(body[len(body)-recordCRCSize:]) {
+		return nil, nil, n, ErrCorruptRecord
+	}
+	return body[:keyLen], body[keyLen : keyLen+valueLen], n, nil
+}
+
+// skipMagic tells whether the magic header is next in the stream, which is consumed if it is
+func skipMagic(r *bufio.Reader) (bool, error) {
+	bz, err := r.Peek(len(recordFileMagic))
+	if err == io.EOF || (err == nil && !bytes.Equal(bz, recordFileMagic)) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	_, err = r.Discard(len(recordFileMagic))
+	return true, err
+}
+
+// fileFraming returns the framing of an existing file by its header, which is empty if the file is
+// empty or does not exist
+func fileFraming(path string) (string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	bz := make([]byte, len(recordFileMagic))
+	n, err := io.ReadFull(file, bz)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if n == 0 {
+		return "", nil
+	}
+	if bytes.Equal(bz[:n], recordFileMagic) {
+		return FramingRecord, nil
+	}
+	return FramingLine, nil
+}
+
+// recoverRecordFile checks the framing of an existing file before more records are appended to it.
+// If the file is framed by records, the torn record left at its end by a crash is truncated, but an
+// error is returned for a corrupt record, which can not be dropped without losing the records after it.
+// It returns the framing of the file, which is empty if the file is empty.
+func recoverRecordFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	if bz, err := r.Peek(len(recordFileMagic)); err == io.EOF && bytes.HasPrefix(recordFileMagic, bz) {
+		// the file is empty, or its header is torn
+		return "", os.Truncate(path, 0)
+	}
+	framed, err := skipMagic(r)
+	if err != nil {
+		return "", err
+	}
+	if !framed {
+		return FramingLine, nil
+	}
+	valid := int64(len(recordFileMagic))
+	for {
+		_, _, n, err := readFramedRecord(r)
+		if err == io.EOF {
+			return FramingRecord, nil
+		} else if err == io.ErrUnexpectedEOF {
+			return FramingRecord, os.Truncate(path, valid)
+		} else if err == ErrCorruptRecord {
+			return FramingRecord, fmt.Errorf("%s at offset %d of %s", err.Error(), valid, path)
+		} else if err != nil {
+			return FramingRecord, err
+		}
+		valid += int64(n)
+	}
+}
@@ -0,0 +1,120 @@
+package msgqueue
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordFraming(t *testing.T) {
+	dir, err := ioutil.TempDir("", "framing")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "messages.bin")
+
+	w, err := createMsgWriter("file:" + path + "?framing=record")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteKV([]byte("a#b"), []byte("{\"content\":\"x\"}\r\nk#v\r\n")))
+	require.NoError(t, w.WriteKV([]byte("k2"), nil))
+	require.NoError(t, w.Close())
+
+	bz, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(bz, recordFileMagic))
+	require.True(t, bytes.HasSuffix(bz, encodeRecord(FramingRecord, []byte("k2"), nil)))
+
+	r, err := OpenRecordFile(path)
+	require.NoError(t, err)
+	recs := readAll(t, r)
+	require.NoError(t, r.Close())
+	require.Equal(t, []Record{
+		{Key: []byte("a#b"), Value: []byte("{\"content\":\"x\"}\r\nk#v\r\n")},
+		{Key: []byte("k2"), Value: []byte{}},
+	}, recs)
+
+	// the records framed differently are not appended
+	_, err = createMsgWriter("file:" + path)
+	require.Error(t, err)
+	_, err = createMsgWriter("file:" + path + "?framing=json")
+	require.Error(t, err)
+	_, err = createMsgWriter("os:stdout?framing=record")
+	require.Error(t, err)
+
+	// the checksum is checked
+	bz[len(recordFileMagic)+recordHeaderSize] ^= 0xff
+	r = NewRecordReader(bytes.NewReader(bz))
+	_, err = r.Next()
+	require.Equal(t, ErrCorruptRecord, err)
+
+	// the headers in the middle of a stream are skipped, as a pipe is reopened by the writer
+	stream := append(append([]byte{}, recordFileMagic...), encodeRecord(FramingRecord, []byte("k1"), []byte("v1"))...)
+	stream = append(stream, recordFileMagic...)
+	stream = append(stream, encodeRecord(FramingRecord, []byte("k2"), []byte("v2"))...)
+	recs = readAll(t, NewRecordReader(bytes.NewReader(stream)))
+	require.Len(t, recs, 2)
+	require.Equal(t, "v2", string(recs[1].Value))
+}
+
+func TestDirMsgWriterRecovery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "framing")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cfg := "dir:" + dir + "?framing=record&max_size=1048576"
+
+	// the old segment framed by lines is kept
+	w, err := createMsgWriter("dir:" + dir + "?max_size=1048576")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteKV([]byte("k0"), []byte("v0")))
+	require.NoError(t, w.Close())
+
+	w, err = createMsgWriter(cfg)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteKV([]byte("k1"), []byte("v1")))
+	require.NoError(t, w.WriteKV([]byte("k2"), []byte("v2")))
+	require.NoError(t, w.Close())
+
+	// a crash leaves a torn record
+	segment := GetFileName(dir, 1)
+	size := GetFileSize(segment)
+	file, err := os.OpenFile(segment, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.Write(encodeRecord(FramingRecord, []byte("k3"), []byte("v3"))[:7])
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	w, err = createMsgWriter(cfg)
+	require.NoError(t, err)
+	require.Equal(t, size, GetFileSize(segment))
+	require.NoError(t, w.WriteKV([]byte("k4"), []byte("v4")))
+	require.NoError(t, w.Close())
+
+	r, err := OpenRecordDir(dir, 0)
+	require.NoError(t, err)
+	var keys []string
+	for _, rec := range readAll(t, r) {
+		keys = append(keys, string(rec.Key))
+	}
+	require.Equal(t, []string{"k0", "k1", "k2", "k4"}, keys)
+
+	// a corrupt record is not truncated with the records after it
+	bz, err := ioutil.ReadFile(segment)
+	require.NoError(t, err)
+	bz[len(recordFileMagic)+recordHeaderSize] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(segment, bz, 0644))
+	_, err = createMsgWriter(cfg)
+	require.Error(t, err)
+	_, err = recoverRecordFile(segment)
+	require.Error(t, err)
+	require.Equal(t, len(bz), GetFileSize(segment))
+
+	// the torn header of a new segment is truncated too
+	require.NoError(t, ioutil.WriteFile(GetFileName(dir, 1), recordFileMagic[:3], 0644))
+	framing, err := recoverRecordFile(GetFileName(dir, 1))
+	require.NoError(t, err)
+	require.Equal(t, "", framing)
+	require.Equal(t, 0, GetFileSize(GetFileName(dir, 1)))
+}
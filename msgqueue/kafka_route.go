@@ -0,0 +1,126 @@
+package msgqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/Shopify/sarama"
+)
+
+// KafkaRoute tells which topic the messages are published to, and how they are partitioned
+type KafkaRoute struct {
+	Topic string `json:"topic"`
+	// the first of these top-level fields found in the JSON payload is the partition key, such as
+	// "trading_pair" for the order events, or "address" for the bankx events. So the messages with
+	// the same value of the field are kept in order. The message key is the partition key if none
+	// of the fields is found.
+	PartitionFields []string `json:"partition_fields,omitempty"`
+}
+
+// KafkaRoutes is the routing table of a kafka writer, which is loaded from a JSON file like:
+//
+//	{
+//	  "default": {"topic": "coinex-dex"},
+//	  "modules": {
+//	    "market": {"topic": "coinex-market", "partition_fields": ["trading_pair"]},
+//	    "bankx": {"topic": "coinex-bankx", "partition_fields": ["from_address", "address"]}
+//	  },
+//	  "keys": {
+//	    "notify_unlock": {"topic": "coinex-bankx", "partition_fields": ["address"]}
+//	  }
+//	}
+//
+// A message is routed by its key first, then by the module which registers its payload, and then
+// by the default route.
+type KafkaRoutes struct {
+	Default KafkaRoute            `json:"default"`
+	Modules map[string]KafkaRoute `json:"modules,omitempty"`
+	Keys    map[string]KafkaRoute `json:"keys,omitempty"`
+}
+
+// DefaultKafkaRoutes publishes all the messages to KafkaPubTopic, partitioned by their keys
+func DefaultKafkaRoutes() KafkaRoutes {
+	return KafkaRoutes{Default: KafkaRoute{Topic: KafkaPubTopic}}
+}
+
+func LoadKafkaRoutes(path string) (KafkaRoutes, error) {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return KafkaRoutes{}, err
+	}
+	routes := DefaultKafkaRoutes()
+	if err := json.Unmarshal(bz, &routes); err != nil {
+		return KafkaRoutes{}, fmt.Errorf("invalid kafka routes in %s: %s", path, err.Error())
+	}
+	if err := routes.Validate(); err != nil {
+		return KafkaRoutes{}, err
+	}
+	return routes, nil
+}
+
+func (r KafkaRoutes) Validate() error {
+	if r.Default.Topic == "" {
+		return fmt.Errorf("the default kafka topic is empty")
+	}
+	for module, route := range r.Modules {
+		if route.Topic == "" {
+			return fmt.Errorf("the kafka topic of module %s is empty", module)
+		}
+	}
+	for key, route := range r.Keys {
+		if route.Topic == "" {
+			return fmt.Errorf("the kafka topic of key %s is empty", key)
+		}
+	}
+	return nil
+}
+
+// Route returns the topic and the partition key of a message, whose value is its JSON payload or
+// the envelope of the payload. The partition fields are looked up in the payload.
+func (r KafkaRoutes) Route(key, value []byte) (topic string, partitionKey []byte) {
+	route := r.lookup(string(key))
+	if len(route.PartitionFields) != 0 {
+		var fields map[string]interface{}
+		if json.Unmarshal(payloadOf(key, value), &fields) == nil {
+			for _, name := range route.PartitionFields {
+				if field, ok := fields[name].(string); ok && field != "" {
+					return route.Topic, []byte(field)
+				}
+			}
+		}
+	}
+	return route.Topic, key
+}
+
+func (r KafkaRoutes) lookup(key string) KafkaRoute {
+	if route, ok := r.Keys[key]; ok {
+		return route
+	}
+	if pt, ok := GetPayloadType(key); ok {
+		if route, ok := r.Modules[pt.Module]; ok {
+			return route
+		}
+	}
+	return r.Default
+}
+
+// partitionKeyPartitioner hashes the partition key kept in the metadata of a message, instead of
+// its key, such that the consumers still filter the messages by their keys
+type partitionKeyPartitioner struct {
+	sarama.Partitioner
+}
+
+func newPartitionKeyPartitioner(topic string) sarama.Partitioner {
+	return partitionKeyPartitioner{sarama.NewHashPartitioner(topic)}
+}
+
+func (p partitionKeyPartitioner) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if partitionKey, ok := message.Metadata.([]byte); ok {
+		return p.Partitioner.Partition(&sarama.ProducerMessage{
+			Topic: message.Topic,
+			Key:   sarama.ByteEncoder(partitionKey),
+		}, numPartitions)
+	}
+	return p.Partitioner.Partition(message, numPartitions)
+}
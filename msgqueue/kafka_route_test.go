@@ -0,0 +1,112 @@
+package msgqueue
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSyncProducer struct {
+	msgs []*sarama.ProducerMessage
+}
+
+func (p *fakeSyncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	p.msgs = append(p.msgs, msg)
+	return 0, int64(len(p.msgs)), nil
+}
+
+func (p *fakeSyncProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	p.msgs = append(p.msgs, msgs...)
+	return nil
+}
+
+func (p *fakeSyncProducer) Close() error {
+	return nil
+}
+
+func TestKafkaRoutes(t *testing.T) {
+	RegisterPayload("test", "codec_payload", 1, codecPayload{})
+
+	dir, err := ioutil.TempDir("", "routes")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "routes.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{
+		"modules": {"test": {"topic": "test", "partition_fields": ["pair", "addr"]}},
+		"keys": {"special": {"topic": "special"}}
+	}`), 0644))
+
+	routes, err := LoadKafkaRoutes(path)
+	require.NoError(t, err)
+	require.Equal(t, KafkaPubTopic, routes.Default.Topic)
+
+	topic, pk := routes.Route([]byte("codec_payload"), []byte(`{"pair":"abc/cet","addr":"coinex1"}`))
+	require.Equal(t, "test", topic)
+	require.Equal(t, "abc/cet", string(pk))
+	topic, pk = routes.Route([]byte("codec_payload"), []byte(`{"addr":"coinex1"}`))
+	require.Equal(t, "test", topic)
+	require.Equal(t, "coinex1", string(pk))
+	topic, pk = routes.Route([]byte("codec_payload"), []byte(`{"pair":1}`))
+	require.Equal(t, "test", topic)
+	require.Equal(t, "codec_payload", string(pk))
+	// the partition fields are looked up in the payloads of the envelopes
+	env := NewEnvelope(5, "AB", 0, "codec_payload", []byte(`{"pair":"abc/cet","addr":"coinex1"}`))
+	topic, pk = routes.Route([]byte("codec_payload"), env.Bytes())
+	require.Equal(t, "test", topic)
+	require.Equal(t, "abc/cet", string(pk))
+	topic, pk = routes.Route([]byte("special"), []byte(`{"pair":"abc/cet"}`))
+	require.Equal(t, "special", topic)
+	require.Equal(t, "special", string(pk))
+	topic, pk = routes.Route([]byte("unknown"), []byte(`{}`))
+	require.Equal(t, KafkaPubTopic, topic)
+	require.Equal(t, "unknown", string(pk))
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"keys": {"special": {}}}`), 0644))
+	_, err = LoadKafkaRoutes(path)
+	require.Error(t, err)
+	_, err = createMsgWriter("kafka:a,b,c?routes=" + path)
+	require.Error(t, err)
+}
+
+func TestKafkaMsgWriterRouting(t *testing.T) {
+	RegisterPayload("test", "codec_payload", 1, codecPayload{})
+
+	producer := &fakeSyncProducer{}
+	routes := DefaultKafkaRoutes()
+	routes.Modules = map[string]KafkaRoute{"test": {Topic: "test", PartitionFields: []string{"price"}}}
+	w := kafkaMsgWriter{SyncProducer: producer, routes: routes, codec: protobufCodec{}}
+	require.Equal(t, "kafka?format=protobuf", w.String())
+
+	require.NoError(t, w.WriteKV([]byte("codec_payload"), []byte(`{"price":"1.5"}`)))
+	require.NoError(t, w.WriteKV([]byte("unknown"), []byte(`{"price":"1.5"}`)))
+	// the registered payloads which can not be encoded are not sent as JSON
+	require.True(t, isEncodeError(w.WriteKV([]byte("codec_payload"), []byte(`{"buy":"yes"}`))))
+	require.Len(t, producer.msgs, 2)
+
+	msg := producer.msgs[0]
+	require.Equal(t, "test", msg.Topic)
+	require.Equal(t, sarama.ByteEncoder("codec_payload"), msg.Key)
+	require.Equal(t, sarama.ByteEncoder{0x12, 0x03, '1', '.', '5'}, msg.Value)
+	require.Equal(t, []byte("1.5"), msg.Metadata)
+	msg = producer.msgs[1]
+	require.Equal(t, KafkaPubTopic, msg.Topic)
+	require.Equal(t, sarama.ByteEncoder(`{"price":"1.5"}`), msg.Value)
+
+	// the messages are partitioned by their partition keys instead of their keys
+	hash := sarama.NewHashPartitioner("test")
+	partitioner := newPartitionKeyPartitioner("test")
+	for _, pair := range []string{"abc/cet", "xyz/cet", "eth/cet", "btc/cet"} {
+		expected, err := hash.Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder(pair)}, 16)
+		require.NoError(t, err)
+		partition, err := partitioner.Partition(&sarama.ProducerMessage{
+			Key:      sarama.StringEncoder("fill_order_info"),
+			Metadata: []byte(pair),
+		}, 16)
+		require.NoError(t, err)
+		require.Equal(t, expected, partition)
+	}
+}
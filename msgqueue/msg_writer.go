@@ -2,35 +2,147 @@ package msgqueue
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 )
 
+// the options of a writer follow a '?' in its config
+const (
+	OptFormat = "format"
+	// the name with which the outbox keeps the offset of the writer, and the config without the options
+	// is used if it is not given. It must be given if two writers have the same config without options.
+	OptName = "name"
+)
+
 type MsgWriter interface {
 	WriteKV(k, v []byte) error
 	Close() error
 	String() string
 }
 
+// BlockCommitter is implemented by the writers which need to know the heights of the messages.
+// CommitBlock is called after all the messages of the block at 'height' are written, and it may be
+// called again for the same height after the node restarts.
+type BlockCommitter interface {
+	CommitBlock(height int64) error
+}
+
+// MsgFlusher is implemented by the writers which keep the messages in memory before they are sent.
+// The messages written to them are acked in the outbox only after Flush has sent them.
+type MsgFlusher interface {
+	Flush() error
+}
+
+func commitBlock(w MsgWriter, height int64) error {
+	if bc, ok := w.(BlockCommitter); ok {
+		return bc.CommitBlock(height)
+	}
+	return nil
+}
+
 // kafka:broker1,broker2,broker3
 // file:path/to/file
 // os:stdout
+// kafka:broker1,broker2?format=protobuf&routes=path/to/routes.json
+// dir:path/to/dir?rotate_blocks=10000&compress=gzip&max_files=100&framing=record
+// ws:127.0.0.1:8800?buffer=256&replay_dir=path/to/dir
+// webhook:https://example.com/hook?batch_size=100&secret_file=path/to/secret (only with the outbox)
+// nats:host1:4222,host2:4222?subject=coinex-dex&format=protobuf
+// redis:127.0.0.1:6379?stream=coinex-dex&max_len=1000000
 func createMsgWriter(cfg string) (MsgWriter, error) {
+	cfg, opts, err := splitWriterOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(cfg, CfgPrefixKafka) {
+		brokers := strings.TrimPrefix(cfg, CfgPrefixKafka)
+		return createKafkaMsgWriter(brokers, opts)
+	}
+	if strings.HasPrefix(cfg, CfgPrefixWS) {
+		addr := strings.TrimPrefix(cfg, CfgPrefixWS)
+		return createPushMsgWriter(addr, opts)
+	}
+	if strings.HasPrefix(cfg, CfgPrefixWebhook) {
+		hookURL := strings.TrimPrefix(cfg, CfgPrefixWebhook)
+		return createWebhookMsgWriter(hookURL, opts)
+	}
+	w, err := createRawMsgWriter(cfg, opts)
+	if err != nil {
+		return w, err
+	}
+	return withCodec(w, opts.Get(OptFormat))
+}
+
+// NewMsgWriter creates a writer with the same config as the producer, such as kafka:broker1,broker2
+func NewMsgWriter(cfg string) (MsgWriter, error) {
+	return createMsgWriter(cfg)
+}
+
+func splitWriterOptions(cfg string) (string, url.Values, error) {
+	idx := strings.LastIndex(cfg, "?")
+	if idx < 0 {
+		return cfg, url.Values{}, nil
+	}
+	opts, err := url.ParseQuery(cfg[idx+1:])
+	if err != nil {
+		return cfg, nil, fmt.Errorf("invalid options in config %s: %s", cfg, err.Error())
+	}
+	return cfg[:idx], opts, nil
+}
+
+// writerName returns the name of the writer with the config, which does not change with the other options
+// and does not show the secrets in them
+func writerName(cfg string) string {
+	addr, opts, err := splitWriterOptions(cfg)
+	if err != nil {
+		return addr
+	}
+	if name := opts.Get(OptName); name != "" {
+		return name
+	}
+	return addr
+}
+
+func createRawMsgWriter(cfg string, opts url.Values) (MsgWriter, error) {
 	if cfg == "nop" {
 		return NewNopMsgWriter(), nil
-	} else if strings.HasPrefix(cfg, CfgPrefixKafka) {
-		brokers := strings.TrimPrefix(cfg, CfgPrefixKafka)
-		return NewKafkaMsgWriter(brokers)
-	} else if strings.HasPrefix(cfg, CfgPrefixFile) {
+	}
+	framing, err := parseFraming(opts)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(cfg, CfgPrefixFile) {
 		filePath := strings.TrimPrefix(cfg, CfgPrefixFile)
-		return NewFileMsgWriter(filePath)
+		return NewFileMsgWriterWithFraming(filePath, framing)
 	} else if strings.TrimPrefix(cfg, CfgPrefixOS) == "stdout" {
+		if framing != FramingLine {
+			return nil, fmt.Errorf("stdout is framed by %s only", FramingLine)
+		}
 		return NewStdOutMsgWriter(), nil
 	} else if strings.HasPrefix(cfg, CfgNamedPipe) {
 		pipeName := strings.TrimPrefix(cfg, CfgNamedPipe)
-		return NewPipeMsgWriter(pipeName)
+		return NewPipeMsgWriterWithFraming(pipeName, framing)
 	} else if strings.HasPrefix(cfg, CfgPrefixDir) {
 		dirPath := strings.TrimPrefix(cfg, CfgPrefixDir)
-		return NewDirMsgWriter(dirPath)
+		dirOpts, err := ParseDirWriterOptions(opts)
+		if err != nil {
+			return nil, err
+		}
+		return NewDirMsgWriterWithOptions(dirPath, dirOpts)
+	} else if strings.HasPrefix(cfg, CfgPrefixNATS) {
+		servers := strings.TrimPrefix(cfg, CfgPrefixNATS)
+		natsOpts, err := ParseNATSWriterOptions(opts)
+		if err != nil {
+			return nil, err
+		}
+		return NewNATSMsgWriter(servers, natsOpts)
+	} else if strings.HasPrefix(cfg, CfgPrefixRedis) {
+		addr := strings.TrimPrefix(cfg, CfgPrefixRedis)
+		redisOpts, err := ParseRedisWriterOptions(opts)
+		if err != nil {
+			return nil, err
+		}
+		return NewRedisMsgWriter(addr, redisOpts)
 	} else {
 		return nil, fmt.Errorf("unsupported config: %s", cfg)
 	}
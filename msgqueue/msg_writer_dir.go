@@ -1,28 +1,174 @@
 package msgqueue
 
 import (
-	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DataDog/zstd"
 )
 
 const (
 	filePrefix = "backup-"
 )
 
+const (
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
+var compressionSuffixes = map[string]string{
+	CompressGzip: ".gz",
+	CompressZstd: ".zst",
+}
+
+// the options of the dir writer
+const (
+	OptDirMaxSize        = "max_size"
+	OptDirRotateInterval = "rotate_interval"
+	OptDirRotateBlocks   = "rotate_blocks"
+	OptDirCompress       = "compress"
+	OptDirMaxFiles       = "max_files"
+	OptDirMaxAge         = "max_age"
+	OptDirMaxBytes       = "max_bytes"
+)
+
+// MaxFileSize is the size of the segments if the dir writer is not given one
 var MaxFileSize = 1024 * 1024 * 100
 
+// DirWriterOptions tells when the dir writer rotates the segments, and how it keeps the closed ones.
+// They are given in the config like dir:path/to/dir?rotate_interval=1h&compress=zstd&max_age=720h
+type DirWriterOptions struct {
+	// a segment is closed before it grows beyond MaxSize bytes, or MaxFileSize if it is zero
+	MaxSize int
+	// a segment is closed when a block is committed RotateInterval after the segment is created
+	RotateInterval time.Duration
+	// a segment is closed when RotateBlocks blocks are committed since its first message
+	RotateBlocks int64
+	// the closed segments are compressed with gzip or zstd, or not compressed if it is empty
+	Compress string
+	// the oldest closed segments are deleted if there are more than MaxFiles of them, or if they
+	// are older than MaxAge, or if all the segments take more than MaxBytes. Zero means no limit.
+	MaxFiles int
+	MaxAge   time.Duration
+	MaxBytes int64
+	// FramingLine if it is empty
+	Framing string
+}
+
+func ParseDirWriterOptions(opts url.Values) (DirWriterOptions, error) {
+	var (
+		o   DirWriterOptions
+		err error
+	)
+	if v := opts.Get(OptDirMaxSize); v != "" {
+		if o.MaxSize, err = strconv.Atoi(v); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptDirMaxSize, v)
+		}
+	}
+	if v := opts.Get(OptDirRotateInterval); v != "" {
+		if o.RotateInterval, err = time.ParseDuration(v); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptDirRotateInterval, v)
+		}
+	}
+	if v := opts.Get(OptDirRotateBlocks); v != "" {
+		if o.RotateBlocks, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptDirRotateBlocks, v)
+		}
+	}
+	o.Compress = opts.Get(OptDirCompress)
+	if _, ok := compressionSuffixes[o.Compress]; !ok && o.Compress != "" {
+		return o, fmt.Errorf("invalid %s: %s", OptDirCompress, o.Compress)
+	}
+	if v := opts.Get(OptDirMaxFiles); v != "" {
+		if o.MaxFiles, err = strconv.Atoi(v); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptDirMaxFiles, v)
+		}
+	}
+	if v := opts.Get(OptDirMaxAge); v != "" {
+		if o.MaxAge, err = time.ParseDuration(v); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptDirMaxAge, v)
+		}
+	}
+	if v := opts.Get(OptDirMaxBytes); v != "" {
+		if o.MaxBytes, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptDirMaxBytes, v)
+		}
+	}
+	if o.Framing, err = parseFraming(opts); err != nil {
+		return o, err
+	}
+	return o, nil
+}
+
+func (o DirWriterOptions) framing() string {
+	if o.Framing == "" {
+		return FramingLine
+	}
+	return o.Framing
+}
+
+func (o DirWriterOptions) maxSize() int {
+	if o.MaxSize > 0 {
+		return o.MaxSize
+	}
+	return MaxFileSize
+}
+
 var _ MsgWriter = (*dirMsgWriter)(nil)
+var _ BlockCommitter = (*dirMsgWriter)(nil)
 
 type dirMsgWriter struct {
 	io.WriteCloser
 	haveWriteSize int
 	fileIndex     int
 	dir           string
+
+	opts      DirWriterOptions
+	index     *SegmentIndex
+	createdAt time.Time
+	// the segments with the messages of the block not committed yet
+	uncommitted []int
+	now         func() time.Time
+
+	// the closed segments are compressed and deleted in the background, one job at a time
+	archiveMtx sync.Mutex
+	archiveWg  sync.WaitGroup
+	archiveErr error
 }
 
 func NewDirMsgWriter(dir string) (MsgWriter, error) {
-	filePath, fileIndex, err := GetFilePathAndFileIndexFromDir(dir, MaxFileSize)
+	return NewDirMsgWriterWithOptions(dir, DirWriterOptions{})
+}
+
+// NewDirMsgWriterWithOptions appends to the last segment if it is not full. If the segment is framed by
+// records, the torn record at its end is truncated, and if it is framed differently, a new segment is
+// opened instead.
+func NewDirMsgWriterWithOptions(dir string, opts DirWriterOptions) (MsgWriter, error) {
+	filePath, fileIndex, err := GetFilePathAndFileIndexFromDir(dir, opts.maxSize())
+	if err != nil {
+		return &dirMsgWriter{}, err
+	}
+	var framing string
+	if opts.framing() == FramingRecord {
+		framing, err = recoverRecordFile(filePath)
+	} else {
+		framing, err = fileFraming(filePath)
+	}
+	if err != nil {
+		return &dirMsgWriter{}, err
+	}
+	if framing != "" && framing != opts.framing() {
+		fileIndex++
+		filePath = GetFileName(dir, fileIndex)
+	}
+	index, err := LoadSegmentIndex(dir)
 	if err != nil {
 		return &dirMsgWriter{}, err
 	}
@@ -34,41 +180,233 @@ func NewDirMsgWriter(dir string) (MsgWriter, error) {
 	if fileSize < 0 {
 		return &dirMsgWriter{}, fmt.Errorf("The parameter passed in is not the correct file path. ")
 	}
-	return &dirMsgWriter{
+	if fileSize == 0 {
+		header := fileHeader(opts.framing())
+		if _, err := file.Write(header); err != nil {
+			file.Close()
+			return &dirMsgWriter{}, err
+		}
+		fileSize = len(header)
+	}
+	w := &dirMsgWriter{
 		WriteCloser:   file,
 		fileIndex:     fileIndex,
 		dir:           dir,
 		haveWriteSize: fileSize,
-	}, nil
+		opts:          opts,
+		index:         index,
+		now:           time.Now,
+	}
+	seg := index.open(fileIndex, filepath.Base(filePath), w.now().Unix())
+	w.createdAt = time.Unix(seg.CreatedAt, 0)
+	if err := index.Save(); err != nil {
+		file.Close()
+		return &dirMsgWriter{}, err
+	}
+	// the segments closed before the node stopped may not be archived yet
+	w.archive()
+	return w, nil
 }
 
 func (w *dirMsgWriter) WriteKV(k, v []byte) error {
-	if len(k)+len(v)+3+w.haveWriteSize > MaxFileSize {
-		if err := w.Close(); err != nil {
+	record := encodeRecord(w.opts.framing(), k, v)
+	if w.haveWriteSize > len(fileHeader(w.opts.framing())) && len(record)+w.haveWriteSize > w.opts.maxSize() {
+		if err := w.rotate(); err != nil {
 			return err
 		}
-		file, err := openFile(GetFileName(w.dir, w.fileIndex+1))
-		if err != nil {
-			return err
+	}
+	if _, err := w.WriteCloser.Write(record); err != nil {
+		return err
+	}
+	w.haveWriteSize += len(record)
+	if len(w.uncommitted) == 0 || w.uncommitted[len(w.uncommitted)-1] != w.fileIndex {
+		w.uncommitted = append(w.uncommitted, w.fileIndex)
+	}
+	return nil
+}
+
+// CommitBlock records the height of the messages written since the last block in the index,
+// and rotates the segment by time or by blocks. The blocks without messages count towards the
+// rotation too, but a segment without messages is not rotated.
+func (w *dirMsgWriter) CommitBlock(height int64) error {
+	saveIndex := false
+	for _, i := range w.uncommitted {
+		seg := w.index.commit(i, height)
+		// the index is saved when a segment gets its first block, or a closed segment gets its last one
+		saveIndex = saveIndex || seg.FirstHeight == height || i != w.fileIndex
+	}
+	w.uncommitted = w.uncommitted[:0]
+
+	seg, ok := w.index.segment(w.fileIndex)
+	if ok && seg.FirstHeight != 0 &&
+		((w.opts.RotateBlocks > 0 && height-seg.FirstHeight+1 >= w.opts.RotateBlocks) ||
+			(w.opts.RotateInterval > 0 && w.now().Sub(w.createdAt) >= w.opts.RotateInterval)) {
+		return w.rotate()
+	}
+	if saveIndex {
+		return w.index.Save()
+	}
+	return nil
+}
+
+// rotate closes the current segment and opens the next one
+func (w *dirMsgWriter) rotate() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	file, err := openFile(GetFileName(w.dir, w.fileIndex+1))
+	if err != nil {
+		return err
+	}
+	w.WriteCloser = file
+	w.fileIndex++
+	header := fileHeader(w.opts.framing())
+	if _, err := file.Write(header); err != nil {
+		return err
+	}
+	w.haveWriteSize = len(header)
+	w.createdAt = w.now()
+	w.index.open(w.fileIndex, filepath.Base(GetFileName(w.dir, w.fileIndex)), w.createdAt.Unix())
+	if err := w.index.Save(); err != nil {
+		return err
+	}
+	w.archive()
+	return nil
+}
+
+// archive compresses the closed segments and applies the retention policy in the background
+func (w *dirMsgWriter) archive() {
+	if w.opts.Compress == "" && w.opts.MaxFiles == 0 && w.opts.MaxAge == 0 && w.opts.MaxBytes == 0 {
+		return
+	}
+	active := w.fileIndex
+	now := w.now()
+	w.archiveWg.Add(1)
+	go func() {
+		defer w.archiveWg.Done()
+		w.archiveMtx.Lock()
+		defer w.archiveMtx.Unlock()
+		if err := w.archiveSegments(active, now); err != nil && w.archiveErr == nil {
+			w.archiveErr = err
 		}
-		w.WriteCloser = file
-		w.fileIndex++
-		w.haveWriteSize = 0
-	}
-	buferr := bytes.NewBuffer(nil)
-	buferr.Write(k)
-	buferr.Write([]byte("#"))
-	buferr.Write(v)
-	buferr.Write([]byte("\r\n"))
-	if _, err := w.WriteCloser.Write(buferr.Bytes()); err != nil {
+	}()
+}
+
+func (w *dirMsgWriter) archiveSegments(active int, now time.Time) error {
+	segments, err := listSegments(w.dir)
+	if err != nil {
 		return err
 	}
-	w.haveWriteSize += len(k) + len(v) + 3
+	if w.opts.Compress != "" {
+		for i, s := range segments {
+			if s.Index >= active || s.Compression != "" {
+				continue
+			}
+			if segments[i], err = w.compressSegment(s); err != nil {
+				return err
+			}
+		}
+	}
+
+	var totalSize int64
+	closed := 0
+	for _, s := range segments {
+		totalSize += s.Size
+		if s.Index < active {
+			closed++
+		}
+	}
+	removed := false
+	for _, s := range segments {
+		if s.Index >= active {
+			break
+		}
+		if (w.opts.MaxFiles > 0 && closed > w.opts.MaxFiles) ||
+			(w.opts.MaxAge > 0 && now.Sub(s.ModTime) > w.opts.MaxAge) ||
+			(w.opts.MaxBytes > 0 && totalSize > w.opts.MaxBytes) {
+			if err := os.Remove(filepath.Join(w.dir, s.Name)); err != nil {
+				return err
+			}
+			w.index.remove(s.Index)
+			removed = true
+			totalSize -= s.Size
+			closed--
+		}
+	}
+	if removed {
+		return w.index.Save()
+	}
 	return nil
 }
 
+// compressSegment writes the compressed segment, and removes the original after the index is saved
+func (w *dirMsgWriter) compressSegment(s segmentFile) (segmentFile, error) {
+	path := filepath.Join(w.dir, s.Name)
+	name := s.Name + compressionSuffixes[w.opts.Compress]
+	tmpPath := filepath.Join(w.dir, name+".tmp")
+	if err := compressFile(path, tmpPath, w.opts.Compress); err != nil {
+		os.Remove(tmpPath)
+		return s, err
+	}
+	if err := os.Rename(tmpPath, filepath.Join(w.dir, name)); err != nil {
+		return s, err
+	}
+	w.index.rename(s.Index, name)
+	if err := w.index.Save(); err != nil {
+		return s, err
+	}
+	if err := os.Remove(path); err != nil {
+		return s, err
+	}
+	info, err := os.Stat(filepath.Join(w.dir, name))
+	if err != nil {
+		return s, err
+	}
+	s.Name, s.Compression, s.Size = name, w.opts.Compress, info.Size()
+	return s, nil
+}
+
+func compressFile(src, dst, compression string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	var cw io.WriteCloser
+	if compression == CompressZstd {
+		cw = zstd.NewWriter(out)
+	} else {
+		cw = gzip.NewWriter(out)
+	}
+	if _, err := io.Copy(cw, in); err != nil {
+		cw.Close()
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// Close waits for the segments being archived, and returns the first error in archiving them
 func (w *dirMsgWriter) Close() error {
-	return w.WriteCloser.Close()
+	err := w.WriteCloser.Close()
+	w.archiveWg.Wait()
+	if w.index != nil {
+		if saveErr := w.index.Save(); err == nil {
+			err = saveErr
+		}
+	}
+	if err == nil {
+		err = w.archiveErr
+	}
+	return err
 }
 
 func (w *dirMsgWriter) String() string {
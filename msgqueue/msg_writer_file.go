@@ -1,7 +1,7 @@
 package msgqueue
 
 import (
-	"bytes"
+	"fmt"
 	"io"
 	"os"
 )
@@ -18,13 +18,20 @@ func SetMkFifoFunc(mkFifo MkFifoFunc) {
 
 type fileMsgWriter struct {
 	io.WriteCloser
+	framing string
 }
 
 func NewStdOutMsgWriter() MsgWriter {
-	return fileMsgWriter{os.Stdout}
+	return fileMsgWriter{WriteCloser: os.Stdout, framing: FramingLine}
 }
 
 func NewPipeMsgWriter(pipe string) (MsgWriter, error) {
+	return NewPipeMsgWriterWithFraming(pipe, FramingLine)
+}
+
+// NewPipeMsgWriterWithFraming writes the header of the framing whenever the pipe is opened, so the
+// readers skip the headers between the records
+func NewPipeMsgWriterWithFraming(pipe string, framing string) (MsgWriter, error) {
 	file, err := os.OpenFile(pipe, os.O_RDWR, 0666)
 	if os.IsNotExist(err) {
 		err := mkFifoFunc(pipe, 0666)
@@ -38,24 +45,61 @@ func NewPipeMsgWriter(pipe string) (MsgWriter, error) {
 	} else if err != nil {
 		return fileMsgWriter{}, err
 	}
-	return fileMsgWriter{file}, nil
+	if _, err := file.Write(fileHeader(framing)); err != nil {
+		file.Close()
+		return fileMsgWriter{}, err
+	}
+	return fileMsgWriter{WriteCloser: file, framing: framing}, nil
 }
 
 func NewFileMsgWriter(filePath string) (MsgWriter, error) {
+	return NewFileMsgWriterWithFraming(filePath, FramingLine)
+}
+
+// NewFileMsgWriterWithFraming appends the records to the file, which must be framed in the same way.
+// The torn record at the end of a file framed by records is truncated.
+func NewFileMsgWriterWithFraming(filePath string, framing string) (MsgWriter, error) {
+	if err := checkFileFraming(filePath, framing); err != nil {
+		return fileMsgWriter{}, err
+	}
 	file, err := openFile(filePath)
 	if err != nil {
 		return fileMsgWriter{}, err
 	}
-	return fileMsgWriter{file}, nil
+	if GetFileSize(filePath) == 0 {
+		if _, err := file.Write(fileHeader(framing)); err != nil {
+			file.Close()
+			return fileMsgWriter{}, err
+		}
+	}
+	return fileMsgWriter{WriteCloser: file, framing: framing}, nil
+}
+
+// checkFileFraming makes sure the records appended to a file are framed like those in it
+func checkFileFraming(filePath string, framing string) error {
+	if s, err := os.Stat(filePath); err == nil && s.IsDir() {
+		return fmt.Errorf("Need to give the file path ")
+	}
+	var (
+		existing string
+		err      error
+	)
+	if framing == FramingRecord {
+		existing, err = recoverRecordFile(filePath)
+	} else {
+		existing, err = fileFraming(filePath)
+	}
+	if err != nil {
+		return err
+	}
+	if existing != "" && existing != framing {
+		return fmt.Errorf("%s is framed by %s, but not %s", filePath, existing, framing)
+	}
+	return nil
 }
 
 func (w fileMsgWriter) WriteKV(k, v []byte) error {
-	buffer := bytes.NewBuffer(nil)
-	buffer.Write(k)
-	buffer.Write([]byte("#"))
-	buffer.Write(v)
-	buffer.Write([]byte("\r\n"))
-	if _, err := w.WriteCloser.Write(buffer.Bytes()); err != nil {
+	if _, err := w.WriteCloser.Write(encodeRecord(w.framing, k, v)); err != nil {
 		return err
 	}
 	return nil
@@ -1,32 +1,72 @@
 package msgqueue
 
 import (
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/Shopify/sarama"
 )
 
+// the options of the kafka writer
+const (
+	// the path of the JSON file with the KafkaRoutes
+	OptKafkaRoutes = "routes"
+	// the default topic, which is KafkaPubTopic if not given
+	OptKafkaTopic = "topic"
+)
+
 var _ MsgWriter = kafkaMsgWriter{}
 
+// kafkaMsgWriter routes the messages by their JSON payloads, so it encodes the payloads by itself
 type kafkaMsgWriter struct {
 	sarama.SyncProducer
+	routes KafkaRoutes
+	codec  Codec
 }
 
 func NewKafkaMsgWriter(brokers string) (MsgWriter, error) {
+	return NewRoutedKafkaMsgWriter(brokers, DefaultKafkaRoutes(), jsonCodec{})
+}
+
+func NewRoutedKafkaMsgWriter(brokers string, routes KafkaRoutes, codec Codec) (MsgWriter, error) {
 	bs := strings.Split(brokers, ",")
 	config := sarama.NewConfig()
 	config.Producer.Return.Successes = true
 	config.Producer.Timeout = 5 * time.Second
+	config.Producer.Partitioner = newPartitionKeyPartitioner
 	producer, err := sarama.NewSyncProducer(bs, config)
-	return kafkaMsgWriter{producer}, err
+	return kafkaMsgWriter{SyncProducer: producer, routes: routes, codec: codec}, err
+}
+
+func createKafkaMsgWriter(brokers string, opts url.Values) (MsgWriter, error) {
+	codec, err := NewCodec(opts.Get(OptFormat))
+	if err != nil {
+		return nil, err
+	}
+	routes := DefaultKafkaRoutes()
+	if path := opts.Get(OptKafkaRoutes); path != "" {
+		if routes, err = LoadKafkaRoutes(path); err != nil {
+			return nil, err
+		}
+	}
+	if topic := opts.Get(OptKafkaTopic); topic != "" {
+		routes.Default.Topic = topic
+	}
+	return NewRoutedKafkaMsgWriter(brokers, routes, codec)
 }
 
 func (w kafkaMsgWriter) WriteKV(k, v []byte) error {
-	_, _, err := w.SyncProducer.SendMessage(&sarama.ProducerMessage{
-		Topic: KafkaPubTopic,
-		Key:   sarama.ByteEncoder(k),
-		Value: sarama.ByteEncoder(v),
+	topic, partitionKey := w.routes.Route(k, v)
+	bz, err := encodePayload(w.codec, k, v)
+	if err != nil {
+		return err
+	}
+	_, _, err = w.SyncProducer.SendMessage(&sarama.ProducerMessage{
+		Topic:    topic,
+		Key:      sarama.ByteEncoder(k),
+		Value:    sarama.ByteEncoder(bz),
+		Metadata: partitionKey,
 	})
 	return err
 }
@@ -36,5 +76,8 @@ func (w kafkaMsgWriter) Close() error {
 }
 
 func (w kafkaMsgWriter) String() string {
+	if w.codec.Name() != FormatJSON {
+		return "kafka?format=" + w.codec.Name()
+	}
 	return "kafka"
 }
@@ -0,0 +1,111 @@
+package msgqueue
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// the options of the NATS writer
+const (
+	// the messages are published to the subject "<subject>.<key>", where the subject is KafkaPubTopic
+	// if not given, such that the consumers may subscribe to "coinex-dex.>" or to some of the keys
+	OptNATSSubject  = "subject"
+	OptNATSToken    = "token"
+	OptNATSUser     = "user"
+	OptNATSPassword = "password"
+	OptNATSTimeout  = "timeout"
+)
+
+// NATSWriterOptions are given in the config like nats:host1:4222,host2:4222?subject=coinex-dex
+type NATSWriterOptions struct {
+	Subject  string
+	Token    string
+	User     string
+	Password string
+	// the timeout to connect, and to wait for the server to acknowledge a message
+	Timeout time.Duration
+}
+
+func ParseNATSWriterOptions(opts url.Values) (NATSWriterOptions, error) {
+	o := NATSWriterOptions{
+		Subject:  KafkaPubTopic,
+		Token:    opts.Get(OptNATSToken),
+		User:     opts.Get(OptNATSUser),
+		Password: opts.Get(OptNATSPassword),
+		Timeout:  5 * time.Second,
+	}
+	if v := opts.Get(OptNATSSubject); v != "" {
+		if strings.ContainsAny(v, " \t\r\n*>") {
+			return o, fmt.Errorf("invalid %s: %s", OptNATSSubject, v)
+		}
+		o.Subject = v
+	}
+	if v := opts.Get(OptNATSTimeout); v != "" {
+		var err error
+		if o.Timeout, err = time.ParseDuration(v); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptNATSTimeout, v)
+		}
+	}
+	return o, nil
+}
+
+var _ MsgWriter = (*natsMsgWriter)(nil)
+
+// natsMsgWriter publishes the messages with the NATS client, and flushes the connection after each
+// of them, so a message is written once the server has received it. The client reconnects to the
+// servers after the connection fails, and the messages are not buffered while it is reconnecting.
+type natsMsgWriter struct {
+	servers []string
+	opts    NATSWriterOptions
+	conn    *nats.Conn
+}
+
+func NewNATSMsgWriter(servers string, opts NATSWriterOptions) (MsgWriter, error) {
+	w := &natsMsgWriter{servers: strings.Split(servers, ","), opts: opts}
+	urls := make([]string, len(w.servers))
+	for i, server := range w.servers {
+		if !strings.Contains(server, "://") {
+			server = "nats://" + server
+		}
+		urls[i] = server
+	}
+	natsOpts := []nats.Option{
+		nats.Name("cet-sdk"),
+		nats.Timeout(opts.Timeout),
+		nats.DontRandomize(),
+		nats.MaxReconnects(-1),
+		nats.ReconnectBufSize(-1),
+	}
+	if opts.Token != "" {
+		natsOpts = append(natsOpts, nats.Token(opts.Token))
+	}
+	if opts.User != "" {
+		natsOpts = append(natsOpts, nats.UserInfo(opts.User, opts.Password))
+	}
+	conn, err := nats.Connect(strings.Join(urls, ","), natsOpts...)
+	if err != nil {
+		return nil, err
+	}
+	w.conn = conn
+	return w, nil
+}
+
+func (w *natsMsgWriter) WriteKV(k, v []byte) error {
+	if err := w.conn.Publish(w.opts.Subject+"."+string(k), v); err != nil {
+		return err
+	}
+	return w.conn.FlushTimeout(w.opts.Timeout)
+}
+
+func (w *natsMsgWriter) Close() error {
+	w.conn.Close()
+	return nil
+}
+
+func (w *natsMsgWriter) String() string {
+	return CfgPrefixNATS + strings.Join(w.servers, ",")
+}
@@ -0,0 +1,77 @@
+package msgqueue
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+)
+
+func runNATSServer(port int) *server.Server {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = port
+	opts.Authorization = "tk"
+	opts.MaxPayload = 64
+	return natsserver.RunServer(&opts)
+}
+
+func TestNATSMsgWriter(t *testing.T) {
+	s := runNATSServer(-1)
+	addr := s.Addr().String()
+
+	_, err := createMsgWriter("nats:" + addr + "?token=wrong")
+	require.Error(t, err)
+	_, err = createMsgWriter("nats:" + addr + "?token=tk&subject=a.*")
+	require.Error(t, err)
+
+	sub, err := nats.Connect("nats://"+addr, nats.Token("tk"), nats.MaxReconnects(-1),
+		nats.ReconnectWait(50*time.Millisecond))
+	require.NoError(t, err)
+	defer sub.Close()
+	msgs, err := sub.SubscribeSync("dex.>")
+	require.NoError(t, err)
+	require.NoError(t, sub.Flush())
+	nextMsg := func() *nats.Msg {
+		msg, err := msgs.NextMsg(5 * time.Second)
+		require.NoError(t, err)
+		return msg
+	}
+
+	// the servers which are not available are skipped
+	down, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	downAddr := down.Addr().String()
+	require.NoError(t, down.Close())
+	w, err := createMsgWriter("nats:" + downAddr + "," + addr + "?token=tk&subject=dex")
+	require.NoError(t, err)
+	require.Equal(t, "nats:"+downAddr+","+addr, w.String())
+
+	require.NoError(t, w.WriteKV([]byte("fill_order_info"), []byte(`{"order_id":"a-1"}`)))
+	require.NoError(t, w.WriteKV([]byte("send_lock_coins"), []byte("")))
+	require.Error(t, w.WriteKV([]byte("k"), []byte(strings.Repeat("x", 65))))
+	msg := nextMsg()
+	require.Equal(t, "dex.fill_order_info", msg.Subject)
+	require.Equal(t, `{"order_id":"a-1"}`, string(msg.Data))
+	msg = nextMsg()
+	require.Equal(t, "dex.send_lock_coins", msg.Subject)
+	require.Equal(t, "", string(msg.Data))
+
+	// the messages fail while the server is down, and the client reconnects after it restarts
+	port := s.Addr().(*net.TCPAddr).Port
+	s.Shutdown()
+	require.Error(t, w.WriteKV([]byte("k3"), []byte("v3")))
+	s = runNATSServer(port)
+	defer s.Shutdown()
+	waitUntil(t, func() bool { return sub.IsConnected() }, 10*time.Second)
+	waitUntil(t, func() bool { return w.WriteKV([]byte("k4"), []byte("v4")) == nil }, 10*time.Second)
+	require.NoError(t, w.Close())
+
+	msg = nextMsg()
+	require.Equal(t, "dex.k4", msg.Subject)
+	require.Equal(t, "v4", string(msg.Data))
+}
@@ -0,0 +1,106 @@
+package msgqueue
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+)
+
+// the options of the Redis Streams writer
+const (
+	// the stream which the messages are added to, which is KafkaPubTopic if not given
+	OptRedisStream = "stream"
+	// the stream is trimmed to about this many messages, or not trimmed if it is not given
+	OptRedisMaxLen   = "max_len"
+	OptRedisPassword = "password"
+	OptRedisDB       = "db"
+	OptRedisTimeout  = "timeout"
+)
+
+// the fields of each entry added to the stream
+const (
+	RedisFieldKey   = "key"
+	RedisFieldValue = "value"
+)
+
+// RedisWriterOptions are given in the config like redis:127.0.0.1:6379?stream=coinex-dex&max_len=1000000
+type RedisWriterOptions struct {
+	Stream   string
+	MaxLen   int64
+	Password string
+	DB       int
+	Timeout  time.Duration
+}
+
+func ParseRedisWriterOptions(opts url.Values) (RedisWriterOptions, error) {
+	o := RedisWriterOptions{
+		Stream:   KafkaPubTopic,
+		Password: opts.Get(OptRedisPassword),
+		Timeout:  5 * time.Second,
+	}
+	var err error
+	if v := opts.Get(OptRedisStream); v != "" {
+		o.Stream = v
+	}
+	if v := opts.Get(OptRedisMaxLen); v != "" {
+		if o.MaxLen, err = strconv.ParseInt(v, 10, 64); err != nil || o.MaxLen < 0 {
+			return o, fmt.Errorf("invalid %s: %s", OptRedisMaxLen, v)
+		}
+	}
+	if v := opts.Get(OptRedisDB); v != "" {
+		if o.DB, err = strconv.Atoi(v); err != nil || o.DB < 0 {
+			return o, fmt.Errorf("invalid %s: %s", OptRedisDB, v)
+		}
+	}
+	if v := opts.Get(OptRedisTimeout); v != "" {
+		if o.Timeout, err = time.ParseDuration(v); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptRedisTimeout, v)
+		}
+	}
+	return o, nil
+}
+
+var _ MsgWriter = (*redisMsgWriter)(nil)
+
+// redisMsgWriter adds the messages to a stream with XADD, each with the fields RedisFieldKey and
+// RedisFieldValue. The client makes the connection again after it fails.
+type redisMsgWriter struct {
+	addr   string
+	opts   RedisWriterOptions
+	client *redis.Client
+}
+
+func NewRedisMsgWriter(addr string, opts RedisWriterOptions) (MsgWriter, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:         addr,
+		Password:     opts.Password,
+		DB:           opts.DB,
+		DialTimeout:  opts.Timeout,
+		ReadTimeout:  opts.Timeout,
+		WriteTimeout: opts.Timeout,
+	})
+	if err := client.Ping().Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &redisMsgWriter{addr: addr, opts: opts, client: client}, nil
+}
+
+func (w *redisMsgWriter) WriteKV(k, v []byte) error {
+	return w.client.XAdd(&redis.XAddArgs{
+		Stream:       w.opts.Stream,
+		MaxLenApprox: w.opts.MaxLen,
+		Values:       map[string]interface{}{RedisFieldKey: k, RedisFieldValue: v},
+	}).Err()
+}
+
+func (w *redisMsgWriter) Close() error {
+	return w.client.Close()
+}
+
+func (w *redisMsgWriter) String() string {
+	return CfgPrefixRedis + w.addr
+}
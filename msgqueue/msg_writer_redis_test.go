@@ -0,0 +1,69 @@
+package msgqueue
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// the fields of the entries in a stream
+func streamEntries(t *testing.T, s *miniredis.Miniredis, db int, stream string) []map[string]string {
+	entries, err := s.DB(db).Stream(stream)
+	require.NoError(t, err)
+	var result []map[string]string
+	for _, e := range entries {
+		fields := make(map[string]string)
+		for i := 0; i+1 < len(e.Values); i += 2 {
+			fields[e.Values[i]] = e.Values[i+1]
+		}
+		result = append(result, fields)
+	}
+	return result
+}
+
+func TestRedisMsgWriter(t *testing.T) {
+	s, err := miniredis.Run()
+	require.NoError(t, err)
+	defer s.Close()
+	s.RequireAuth("pw")
+	addr := s.Addr()
+
+	_, err = createMsgWriter("redis:" + addr + "?password=wrong")
+	require.Error(t, err)
+	_, err = createMsgWriter("redis:" + addr + "?max_len=-1")
+	require.Error(t, err)
+
+	w, err := createMsgWriter("redis:" + addr + "?password=pw&db=2&stream=dex&max_len=2")
+	require.NoError(t, err)
+	require.Equal(t, "redis:"+addr, w.String())
+	require.NoError(t, w.WriteKV([]byte("create_order_info"), []byte(`{"a":0}`)))
+	require.NoError(t, w.WriteKV([]byte("fill_order_info"), []byte("{\"a\":\r\n1}")))
+	require.NoError(t, w.WriteKV([]byte("send_lock_coins"), []byte("")))
+	// the stream is trimmed
+	require.Equal(t, []map[string]string{
+		{"key": "fill_order_info", "value": "{\"a\":\r\n1}"},
+		{"key": "send_lock_coins", "value": ""},
+	}, streamEntries(t, s, 2, "dex"))
+
+	// the writer fails while the server is down, and connects again after it restarts
+	s.Close()
+	require.Error(t, w.WriteKV([]byte("k"), []byte("v")))
+	require.NoError(t, s.Restart())
+	require.NoError(t, w.WriteKV([]byte("k"), []byte("v")))
+	require.NoError(t, w.Close())
+	require.Equal(t, map[string]string{"key": "k", "value": "v"}, streamEntries(t, s, 2, "dex")[1])
+
+	// the writer is still usable after the server replies an error
+	require.NoError(t, s.Set("bad", "v"))
+	w, err = createMsgWriter("redis:" + addr + "?password=pw&stream=bad")
+	require.NoError(t, err)
+	err = w.WriteKV([]byte("k"), []byte("v"))
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "WRONGTYPE"))
+	s.Del("bad")
+	require.NoError(t, w.WriteKV([]byte("k"), []byte("v")))
+	require.NoError(t, w.Close())
+	require.Len(t, streamEntries(t, s, 0, "bad"), 1)
+}
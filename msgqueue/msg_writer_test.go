@@ -2,12 +2,18 @@ package msgqueue
 
 import (
 	"bufio"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 
+	"github.com/DataDog/zstd"
 	"github.com/stretchr/testify/require"
 )
 
@@ -138,3 +144,173 @@ func TestNewPipeMsgWriter(t *testing.T) {
 		}
 	}()
 }
+
+func TestParseDirWriterOptions(t *testing.T) {
+	_, opts, err := splitWriterOptions("dir:tmp?max_size=1024&rotate_interval=1h&rotate_blocks=100&compress=zstd&max_files=3&max_age=24h&max_bytes=4096&framing=record")
+	require.NoError(t, err)
+	o, err := ParseDirWriterOptions(opts)
+	require.NoError(t, err)
+	require.Equal(t, DirWriterOptions{
+		MaxSize:        1024,
+		RotateInterval: time.Hour,
+		RotateBlocks:   100,
+		Compress:       CompressZstd,
+		MaxFiles:       3,
+		MaxAge:         24 * time.Hour,
+		MaxBytes:       4096,
+		Framing:        FramingRecord,
+	}, o)
+
+	_, err = createMsgWriter("dir:tmp?compress=rar")
+	require.Error(t, err)
+	_, err = createMsgWriter("dir:tmp?rotate_interval=1")
+	require.Error(t, err)
+}
+
+func readSegment(t *testing.T, path string) string {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gr, err := gzip.NewReader(file)
+		require.NoError(t, err)
+		r = gr
+	} else if strings.HasSuffix(path, ".zst") {
+		zr := zstd.NewReader(file)
+		defer zr.Close()
+		r = zr
+	}
+	bz, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return string(bz)
+}
+
+func TestDirMsgWriterRotateByBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dirwriter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w, err := createMsgWriter("dir:" + dir + "?rotate_blocks=2&compress=gzip&max_files=2&max_size=1048576")
+	require.NoError(t, err)
+	for h := int64(1); h <= 6; h++ {
+		require.NoError(t, w.WriteKV([]byte(fmt.Sprintf("k%d", h)), []byte(fmt.Sprintf("v%d", h))))
+		require.NoError(t, commitBlock(w, h))
+	}
+	// the block without messages does not open an empty segment
+	require.NoError(t, commitBlock(w, 7))
+	require.NoError(t, w.Close())
+
+	// the first segment is deleted, and the other closed ones are compressed
+	segments, err := listSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 3)
+	require.Equal(t, "backup-1.gz", segments[0].Name)
+	require.Equal(t, "backup-2.gz", segments[1].Name)
+	require.Equal(t, "backup-3", segments[2].Name)
+	require.Equal(t, "k3#v3\r\nk4#v4\r\n", readSegment(t, filepath.Join(dir, "backup-1.gz")))
+	require.Equal(t, "k5#v5\r\nk6#v6\r\n", readSegment(t, filepath.Join(dir, "backup-2.gz")))
+
+	index, err := LoadSegmentIndex(dir)
+	require.NoError(t, err)
+	infos := index.Segments()
+	require.Len(t, infos, 3)
+	require.Equal(t, SegmentInfo{Index: 1, File: "backup-1.gz", FirstHeight: 3, LastHeight: 4, CreatedAt: infos[0].CreatedAt}, infos[0])
+	require.Equal(t, SegmentInfo{Index: 2, File: "backup-2.gz", FirstHeight: 5, LastHeight: 6, CreatedAt: infos[1].CreatedAt}, infos[1])
+	require.EqualValues(t, 0, infos[2].FirstHeight)
+	seg, ok := index.Lookup(6)
+	require.True(t, ok)
+	require.Equal(t, "backup-2.gz", seg.File)
+	_, ok = index.Lookup(1)
+	require.False(t, ok)
+
+	// the writer continues with the open segment after restart
+	w, err = createMsgWriter("dir:" + dir + "?rotate_blocks=2&compress=gzip&max_files=2&max_size=1048576")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteKV([]byte("k8"), []byte("v8")))
+	require.NoError(t, commitBlock(w, 8))
+	require.NoError(t, w.Close())
+	require.Equal(t, "k8#v8\r\n", readSegment(t, filepath.Join(dir, "backup-3")))
+}
+
+func TestDirMsgWriterRotateByTimeAndSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dirwriter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mw, err := NewDirMsgWriterWithOptions(dir, DirWriterOptions{
+		MaxSize:        16,
+		RotateInterval: time.Minute,
+		Compress:       CompressZstd,
+	})
+	require.NoError(t, err)
+	w := mw.(*dirMsgWriter)
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	// the block is split into two segments by size
+	require.NoError(t, w.WriteKV([]byte("k1"), []byte("v1")))
+	require.NoError(t, w.WriteKV([]byte("k1"), []byte("v1-xx")))
+	require.NoError(t, w.CommitBlock(1))
+	require.NoError(t, w.WriteKV([]byte("k"), []byte("2")))
+	// the segment is rotated by time when the block is committed
+	now = now.Add(time.Minute)
+	require.NoError(t, w.CommitBlock(2))
+	require.NoError(t, w.WriteKV([]byte("k"), []byte("3")))
+	require.NoError(t, w.CommitBlock(3))
+	require.NoError(t, w.Close())
+
+	infos := w.index.Segments()
+	require.Len(t, infos, 3)
+	require.Equal(t, "backup-0.zst", infos[0].File)
+	require.EqualValues(t, [2]int64{1, 1}, [2]int64{infos[0].FirstHeight, infos[0].LastHeight})
+	require.Equal(t, "backup-1.zst", infos[1].File)
+	require.EqualValues(t, [2]int64{1, 2}, [2]int64{infos[1].FirstHeight, infos[1].LastHeight})
+	require.Equal(t, "k1#v1\r\n", readSegment(t, filepath.Join(dir, "backup-0.zst")))
+	require.Equal(t, "k1#v1-xx\r\nk#2\r\n", readSegment(t, filepath.Join(dir, "backup-1.zst")))
+	require.EqualValues(t, [2]int64{3, 3}, [2]int64{infos[2].FirstHeight, infos[2].LastHeight})
+	require.Equal(t, "backup-2", infos[2].File)
+}
+
+func TestDirMsgWriterRotateInQuietBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dirwriter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mw, err := NewDirMsgWriterWithOptions(dir, DirWriterOptions{
+		RotateBlocks:   3,
+		RotateInterval: time.Minute,
+	})
+	require.NoError(t, err)
+	w := mw.(*dirMsgWriter)
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	// the blocks without messages count towards the rotation by blocks
+	require.NoError(t, w.WriteKV([]byte("k"), []byte("1")))
+	require.NoError(t, w.CommitBlock(1))
+	require.NoError(t, w.CommitBlock(2))
+	require.Len(t, w.index.Segments(), 1)
+	require.NoError(t, w.CommitBlock(3))
+	require.Len(t, w.index.Segments(), 2)
+	// the segment without messages is not rotated
+	now = now.Add(time.Minute)
+	for h := int64(4); h <= 10; h++ {
+		require.NoError(t, w.CommitBlock(h))
+	}
+	require.Len(t, w.index.Segments(), 2)
+
+	// the segment is rotated by time in the blocks without messages
+	require.NoError(t, w.WriteKV([]byte("k"), []byte("11")))
+	require.NoError(t, w.CommitBlock(11))
+	now = now.Add(time.Minute)
+	require.NoError(t, w.CommitBlock(12))
+	require.NoError(t, w.Close())
+
+	infos := w.index.Segments()
+	require.Len(t, infos, 3)
+	require.EqualValues(t, [2]int64{1, 1}, [2]int64{infos[0].FirstHeight, infos[0].LastHeight})
+	require.EqualValues(t, [2]int64{11, 11}, [2]int64{infos[1].FirstHeight, infos[1].LastHeight})
+	require.Equal(t, "k#11\r\n", readSegment(t, filepath.Join(dir, "backup-1")))
+	require.EqualValues(t, 0, infos[2].FirstHeight)
+}
@@ -0,0 +1,257 @@
+package msgqueue
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the options of the webhook writer
+const (
+	// the number of messages posted in a request
+	OptWebhookBatchSize = "batch_size"
+	// a batch which is not full is posted after this duration, or when a block is committed
+	OptWebhookBatchInterval = "batch_interval"
+	// the attempts to post a batch, and the interval before the second attempt, which is doubled
+	// before each of the attempts after it
+	OptWebhookRetries       = "retries"
+	OptWebhookRetryInterval = "retry_interval"
+	OptWebhookTimeout       = "timeout"
+	// the file with the key to sign the requests, which are not signed if it is not given
+	OptWebhookSecretFile = "secret_file"
+)
+
+// WebhookSignatureHeader has "sha256=" followed by the hex of the HMAC-SHA256 of the request body
+const WebhookSignatureHeader = "X-CET-Signature"
+
+// WebhookWriterOptions tells how the webhook writer posts the messages. They are given in the config
+// like webhook:https://example.com/hook?batch_size=100&secret_file=path/to/secret
+type WebhookWriterOptions struct {
+	BatchSize     int
+	BatchInterval time.Duration
+	Retries       int
+	RetryInterval time.Duration
+	Timeout       time.Duration
+	Secret        []byte
+}
+
+func DefaultWebhookWriterOptions() WebhookWriterOptions {
+	return WebhookWriterOptions{
+		BatchSize:     100,
+		BatchInterval: time.Second,
+		Retries:       5,
+		RetryInterval: 100 * time.Millisecond,
+		Timeout:       10 * time.Second,
+	}
+}
+
+func ParseWebhookWriterOptions(opts url.Values) (WebhookWriterOptions, error) {
+	o := DefaultWebhookWriterOptions()
+	var err error
+	if v := opts.Get(OptWebhookBatchSize); v != "" {
+		if o.BatchSize, err = strconv.Atoi(v); err != nil || o.BatchSize <= 0 {
+			return o, fmt.Errorf("invalid %s: %s", OptWebhookBatchSize, v)
+		}
+	}
+	if v := opts.Get(OptWebhookBatchInterval); v != "" {
+		if o.BatchInterval, err = time.ParseDuration(v); err != nil || o.BatchInterval <= 0 {
+			return o, fmt.Errorf("invalid %s: %s", OptWebhookBatchInterval, v)
+		}
+	}
+	if v := opts.Get(OptWebhookRetries); v != "" {
+		if o.Retries, err = strconv.Atoi(v); err != nil || o.Retries <= 0 {
+			return o, fmt.Errorf("invalid %s: %s", OptWebhookRetries, v)
+		}
+	}
+	if v := opts.Get(OptWebhookRetryInterval); v != "" {
+		if o.RetryInterval, err = time.ParseDuration(v); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptWebhookRetryInterval, v)
+		}
+	}
+	if v := opts.Get(OptWebhookTimeout); v != "" {
+		if o.Timeout, err = time.ParseDuration(v); err != nil {
+			return o, fmt.Errorf("invalid %s: %s", OptWebhookTimeout, v)
+		}
+	}
+	if path := opts.Get(OptWebhookSecretFile); path != "" {
+		bz, err := ioutil.ReadFile(path)
+		if err != nil {
+			return o, err
+		}
+		o.Secret = bytes.TrimSpace(bz)
+	}
+	return o, nil
+}
+
+// WebhookMessage is a message in the body of a webhook request
+type WebhookMessage struct {
+	Key string `json:"key"`
+	// the JSON payload, or a JSON string if the payload is not JSON
+	Value json.RawMessage `json:"value"`
+}
+
+// WebhookBatch is the body of a webhook request
+type WebhookBatch struct {
+	Messages []WebhookMessage `json:"messages"`
+}
+
+// SignWebhook returns the value of WebhookSignatureHeader for a request body
+func SignWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var _ MsgWriter = (*webhookMsgWriter)(nil)
+var _ BlockCommitter = (*webhookMsgWriter)(nil)
+var _ MsgFlusher = (*webhookMsgWriter)(nil)
+
+// webhookMsgWriter posts the messages in batches. A batch which can not be posted is kept and
+// posted again later, and WriteKV fails if a full batch can not be posted. Since posting a batch may
+// take as long as all its retries, the writer is only used behind the outbox, whose dispatcher calls
+// it out of the consensus. The messages are acked after Flush has posted them, so they are not lost
+// if the node stops before.
+type webhookMsgWriter struct {
+	url    string
+	opts   WebhookWriterOptions
+	client *http.Client
+
+	mtx   sync.Mutex
+	batch []WebhookMessage
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+func NewWebhookMsgWriter(hookURL string, opts WebhookWriterOptions) (MsgWriter, error) {
+	if !strings.HasPrefix(hookURL, "http://") && !strings.HasPrefix(hookURL, "https://") {
+		return nil, fmt.Errorf("invalid webhook url: %s", hookURL)
+	}
+	w := &webhookMsgWriter{
+		url:    hookURL,
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go w.flushPeriodically()
+	return w, nil
+}
+
+func createWebhookMsgWriter(hookURL string, opts url.Values) (MsgWriter, error) {
+	if format := opts.Get(OptFormat); format != "" && format != FormatJSON {
+		return nil, fmt.Errorf("%s writer supports %s only", strings.TrimSuffix(CfgPrefixWebhook, ":"), FormatJSON)
+	}
+	webhookOpts, err := ParseWebhookWriterOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewWebhookMsgWriter(hookURL, webhookOpts)
+}
+
+func (w *webhookMsgWriter) WriteKV(k, v []byte) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.batch = append(w.batch, WebhookMessage{Key: string(k), Value: jsonPayload(v)})
+	if len(w.batch) < w.opts.BatchSize {
+		return nil
+	}
+	if err := w.flush(); err != nil {
+		// the message is written again by the caller
+		w.batch = w.batch[:len(w.batch)-1]
+		return err
+	}
+	return nil
+}
+
+func (w *webhookMsgWriter) Flush() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.flush()
+}
+
+func (w *webhookMsgWriter) CommitBlock(height int64) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.flush()
+}
+
+func (w *webhookMsgWriter) Close() error {
+	close(w.quit)
+	<-w.done
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.flush()
+}
+
+func (w *webhookMsgWriter) String() string {
+	return CfgPrefixWebhook + w.url
+}
+
+func (w *webhookMsgWriter) flushPeriodically() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.BatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mtx.Lock()
+			// the batch is kept if it fails, and posted again at the next tick
+			_ = w.flush()
+			w.mtx.Unlock()
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// flush posts the batch, which must be done with the lock held
+func (w *webhookMsgWriter) flush() error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(WebhookBatch{Messages: w.batch})
+	if err != nil {
+		return err
+	}
+	err = Retry(w.opts.Retries, w.opts.RetryInterval, func() error {
+		return w.post(body)
+	})
+	if err != nil {
+		return err
+	}
+	w.batch = nil
+	return nil
+}
+
+func (w *webhookMsgWriter) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.opts.Secret) != 0 {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(w.opts.Secret, body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded %s", w.url, resp.Status)
+	}
+	return nil
+}
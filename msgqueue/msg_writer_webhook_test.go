@@ -0,0 +1,134 @@
+package msgqueue
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type webhookRecorder struct {
+	mtx      sync.Mutex
+	secret   []byte
+	failures int
+	batches  []WebhookBatch
+}
+
+func (rec *webhookRecorder) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+	if rec.secret != nil && r.Header.Get(WebhookSignatureHeader) != SignWebhook(rec.secret, body) {
+		http.Error(rw, "bad signature", http.StatusUnauthorized)
+		return
+	}
+	if rec.failures > 0 {
+		rec.failures--
+		http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var batch WebhookBatch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rec.batches = append(rec.batches, batch)
+}
+
+func (rec *webhookRecorder) fail(n int) {
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+	rec.failures = n
+}
+
+func (rec *webhookRecorder) keys() [][]string {
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+	var keys [][]string
+	for _, batch := range rec.batches {
+		var ks []string
+		for _, msg := range batch.Messages {
+			ks = append(ks, msg.Key)
+		}
+		keys = append(keys, ks)
+	}
+	return keys
+}
+
+func TestWebhookMsgWriter(t *testing.T) {
+	secretFile, err := ioutil.TempFile("", "secret")
+	require.NoError(t, err)
+	defer os.Remove(secretFile.Name())
+	_, err = secretFile.WriteString("s3cret\n")
+	require.NoError(t, err)
+	require.NoError(t, secretFile.Close())
+
+	rec := &webhookRecorder{secret: []byte("s3cret")}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	w, err := createMsgWriter("webhook:" + server.URL + "/hook?batch_size=2&batch_interval=1h&retry_interval=1ms&secret_file=" + secretFile.Name())
+	require.NoError(t, err)
+	require.Equal(t, "webhook:"+server.URL+"/hook", w.String())
+
+	// a full batch is posted at once, and the rest when the block is committed
+	require.NoError(t, w.WriteKV([]byte("k1"), []byte(`{"a":1}`)))
+	require.NoError(t, w.WriteKV([]byte("k2"), []byte("plain")))
+	require.NoError(t, w.WriteKV([]byte("k3"), []byte(`{}`)))
+	require.Equal(t, [][]string{{"k1", "k2"}}, rec.keys())
+	rec.mtx.Lock()
+	require.Equal(t, json.RawMessage(`"plain"`), rec.batches[0].Messages[1].Value)
+	rec.mtx.Unlock()
+	require.NoError(t, commitBlock(w, 1))
+	require.Equal(t, [][]string{{"k1", "k2"}, {"k3"}}, rec.keys())
+
+	// the failures are retried
+	rec.fail(2)
+	require.NoError(t, w.WriteKV([]byte("k4"), []byte(`{}`)))
+	require.NoError(t, w.WriteKV([]byte("k5"), []byte(`{}`)))
+	require.Equal(t, [][]string{{"k1", "k2"}, {"k3"}, {"k4", "k5"}}, rec.keys())
+
+	// the last message of a full batch which can not be posted is written again by the caller
+	rec.fail(100)
+	require.NoError(t, w.WriteKV([]byte("k6"), []byte(`{}`)))
+	require.Error(t, w.WriteKV([]byte("k7"), []byte(`{}`)))
+	require.Error(t, commitBlock(w, 2))
+	rec.fail(0)
+	require.NoError(t, w.WriteKV([]byte("k7"), []byte(`{}`)))
+	require.NoError(t, w.WriteKV([]byte("k8"), []byte(`{}`)))
+	require.NoError(t, w.Close())
+	require.Equal(t, [][]string{{"k1", "k2"}, {"k3"}, {"k4", "k5"}, {"k6", "k7"}, {"k8"}}, rec.keys())
+}
+
+func TestWebhookMsgWriterFlushPeriodically(t *testing.T) {
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	w, err := NewWebhookMsgWriter(server.URL, WebhookWriterOptions{
+		BatchSize: 100, BatchInterval: 10 * time.Millisecond, Retries: 1, Timeout: time.Second,
+	})
+	require.NoError(t, err)
+	defer w.Close()
+	require.NoError(t, w.WriteKV([]byte("k"), []byte(`{}`)))
+	waitUntil(t, func() bool {
+		return len(rec.keys()) == 1
+	}, 5*time.Second)
+}
+
+func TestParseWebhookWriterOptions(t *testing.T) {
+	_, err := createMsgWriter("webhook:ftp://example.com")
+	require.Error(t, err)
+	_, err = createMsgWriter("webhook:http://example.com?format=protobuf")
+	require.Error(t, err)
+	_, err = createMsgWriter("webhook:http://example.com?batch_size=0")
+	require.Error(t, err)
+	_, err = createMsgWriter("webhook:http://example.com?secret_file=/no/such/file")
+	require.Error(t, err)
+}
@@ -0,0 +1,470 @@
+package msgqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// the options of the push writer
+const (
+	// the number of messages buffered for each client, beyond which the client is dropped
+	OptPushBuffer = "buffer"
+	// the dir written by a dir writer, from which the clients may ask for the messages of the past blocks
+	OptPushReplayDir = "replay_dir"
+	// the origins of the pages which may subscribe, separated by commas, such as https://dex.example.com,
+	// or "*" for all the pages. Only the pages of the same origin may subscribe if it is not given.
+	// The clients which are not browsers send no origin, and they may always subscribe.
+	OptPushOrigins = "origins"
+)
+
+// the endpoints of the push writer, where the clients subscribe with the query parameters
+// PushParamKey, PushParamTradingPair, PushParamAddress and PushParamFromHeight
+const (
+	PushPathWebSocket = "/ws"
+	PushPathSSE       = "/sse"
+)
+
+// the query parameters of the endpoints. Each of them may be repeated or have values separated by
+// commas. A message is pushed if it matches all the parameters given.
+const (
+	// the keys of the messages, such as fill_order_info
+	PushParamKey = "key"
+	// the "trading_pair" field of the payloads, such as abc/cet
+	PushParamTradingPair = "trading_pair"
+	// an address in any field of the payloads, or the address in the "order_id" field
+	PushParamAddress = "address"
+	// the messages are replayed from this height in the replay dir before the live ones
+	PushParamFromHeight = "from_height"
+)
+
+// DefaultPushBuffer is the size of the buffers of the clients if the push writer is not given one
+const DefaultPushBuffer = 1024
+
+const pushWriteTimeout = 10 * time.Second
+
+// PushMessage is the JSON text of each WebSocket message and each SSE event's data
+type PushMessage struct {
+	Key string `json:"key"`
+	// the height of the block the message is sent in, or zero if it is not known yet
+	Height  int64           `json:"height"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+func newPushMessage(k, v []byte, height int64) PushMessage {
+	return PushMessage{Key: string(k), Height: height, Payload: jsonPayload(v)}
+}
+
+// jsonPayload embeds a payload in the JSON sent to the clients, as a JSON string if it is not JSON
+func jsonPayload(v []byte) json.RawMessage {
+	if !json.Valid(v) {
+		bz, _ := json.Marshal(string(v))
+		return bz
+	}
+	return json.RawMessage(v)
+}
+
+var _ MsgWriter = (*pushMsgWriter)(nil)
+var _ BlockCommitter = (*pushMsgWriter)(nil)
+
+// pushMsgWriter serves the messages to the browsers and other local clients over WebSocket and
+// Server-Sent Events. It never blocks the node: a client whose buffer is full is dropped, and it
+// has to subscribe again, from the height it has got if the replay dir is given.
+type pushMsgWriter struct {
+	server    *http.Server
+	listener  net.Listener
+	buffer    int
+	replayDir string
+	origins   []string
+	upgrader  websocket.Upgrader
+
+	mtx     sync.Mutex
+	clients map[*pushClient]struct{}
+	// the height of the last committed block
+	height int64
+	closed bool
+}
+
+// NewPushMsgWriter listens on addr, such as 127.0.0.1:8800, and serves the endpoints PushPathWebSocket
+// and PushPathSSE. If replayDir is not empty, the clients may ask for the messages in it. The pages of
+// the origins, besides those of the same origin, may subscribe, and "*" allows all the pages.
+func NewPushMsgWriter(addr string, buffer int, replayDir string, origins []string) (MsgWriter, error) {
+	if buffer <= 0 {
+		buffer = DefaultPushBuffer
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	w := &pushMsgWriter{
+		listener:  listener,
+		buffer:    buffer,
+		replayDir: replayDir,
+		origins:   origins,
+		clients:   make(map[*pushClient]struct{}),
+	}
+	w.upgrader.CheckOrigin = w.allowOrigin
+	mux := http.NewServeMux()
+	mux.HandleFunc(PushPathWebSocket, w.serveWebSocket)
+	mux.HandleFunc(PushPathSSE, w.serveSSE)
+	w.server = &http.Server{Handler: mux}
+	go func() {
+		_ = w.server.Serve(listener)
+	}()
+	return w, nil
+}
+
+func createPushMsgWriter(addr string, opts url.Values) (MsgWriter, error) {
+	if format := opts.Get(OptFormat); format != "" && format != FormatJSON {
+		return nil, fmt.Errorf("%s writer supports %s only", strings.TrimSuffix(CfgPrefixWS, ":"), FormatJSON)
+	}
+	buffer := 0
+	if v := opts.Get(OptPushBuffer); v != "" {
+		var err error
+		if buffer, err = strconv.Atoi(v); err != nil || buffer <= 0 {
+			return nil, fmt.Errorf("invalid %s: %s", OptPushBuffer, v)
+		}
+	}
+	var origins []string
+	if v := opts.Get(OptPushOrigins); v != "" {
+		origins = strings.Split(v, ",")
+	}
+	return NewPushMsgWriter(addr, buffer, opts.Get(OptPushReplayDir), origins)
+}
+
+// allowOrigin tells whether the page sending the request may subscribe
+func (w *pushMsgWriter) allowOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, o := range w.origins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// Addr returns the address the writer listens on, which has the actual port if port 0 is given
+func (w *pushMsgWriter) Addr() net.Addr {
+	return w.listener.Addr()
+}
+
+func (w *pushMsgWriter) WriteKV(k, v []byte) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if len(w.clients) == 0 {
+		return nil
+	}
+	height := heightOfValue(v)
+	if height == 0 && w.height != 0 {
+		height = w.height + 1
+	}
+	msg := newPushMessage(k, v, height)
+	fields := lazyFields{key: k, value: v}
+	for c := range w.clients {
+		if !c.filter.match(msg.Key, &fields) {
+			continue
+		}
+		select {
+		case c.ch <- msg:
+		default:
+			w.drop(c)
+		}
+	}
+	return nil
+}
+
+func (w *pushMsgWriter) CommitBlock(height int64) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.height = height
+	return nil
+}
+
+func (w *pushMsgWriter) Close() error {
+	w.mtx.Lock()
+	w.closed = true
+	for c := range w.clients {
+		w.drop(c)
+	}
+	w.mtx.Unlock()
+	return w.server.Close()
+}
+
+func (w *pushMsgWriter) String() string {
+	return CfgPrefixWS + w.listener.Addr().String()
+}
+
+// subscribe adds a client, and returns the height from which it gets the live messages
+func (w *pushMsgWriter) subscribe(c *pushClient) (int64, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.closed {
+		return 0, fmt.Errorf("writer is closed")
+	}
+	w.clients[c] = struct{}{}
+	return w.height + 1, nil
+}
+
+func (w *pushMsgWriter) unsubscribe(c *pushClient) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.drop(c)
+}
+
+// drop removes a client and closes its channel, which must be done with the lock held
+func (w *pushMsgWriter) drop(c *pushClient) {
+	if _, ok := w.clients[c]; ok {
+		delete(w.clients, c)
+		close(c.ch)
+	}
+}
+
+// pushClient is a subscriber with its own buffer of messages
+type pushClient struct {
+	filter     pushFilter
+	fromHeight int64
+	ch         chan PushMessage
+}
+
+func (w *pushMsgWriter) newClient(r *http.Request) (*pushClient, error) {
+	query := r.URL.Query()
+	c := &pushClient{filter: parsePushFilter(query), ch: make(chan PushMessage, w.buffer)}
+	from := query.Get(PushParamFromHeight)
+	if from == "" && w.replayDir != "" {
+		// the browsers reconnect with the id of the last event they got, which is its height,
+		// and the id is ignored if it is not a height
+		if h, err := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64); err == nil && h > 0 {
+			from = strconv.FormatInt(h, 10)
+		}
+	}
+	if from != "" {
+		var err error
+		if c.fromHeight, err = strconv.ParseInt(from, 10, 64); err != nil || c.fromHeight <= 0 {
+			return nil, fmt.Errorf("invalid %s: %s", PushParamFromHeight, from)
+		}
+		if w.replayDir == "" {
+			return nil, fmt.Errorf("%s is not supported without %s", PushParamFromHeight, OptPushReplayDir)
+		}
+	}
+	return c, nil
+}
+
+// serve sends the replayed messages, then the live ones until the client is dropped or it goes away
+func (w *pushMsgWriter) serve(c *pushClient, send func(PushMessage) error, done <-chan struct{}) error {
+	liveFrom, err := w.subscribe(c)
+	if err != nil {
+		return err
+	}
+	defer w.unsubscribe(c)
+	if c.fromHeight != 0 {
+		if err := w.replay(c, liveFrom, send); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case msg, ok := <-c.ch:
+			if !ok {
+				return fmt.Errorf("the client is too slow, or the writer is closed")
+			}
+			if err := send(msg); err != nil {
+				return err
+			}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// replay sends the messages in the replay dir from the client's height up to the block before
+// 'liveFrom', after which the client gets the live messages. If no block has been committed since
+// the writer started, the messages are replayed to the end of the dir, and some of them may be
+// sent twice.
+func (w *pushMsgWriter) replay(c *pushClient, liveFrom int64, send func(PushMessage) error) error {
+	r, err := OpenRecordDir(w.replayDir, c.fromHeight)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	r = SeekHeights(r, c.fromHeight, liveFrom-1)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fields := lazyFields{key: rec.Key, value: rec.Value}
+		if !c.filter.match(string(rec.Key), &fields) {
+			continue
+		}
+		if err := send(newPushMessage(rec.Key, rec.Value, rec.Height)); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *pushMsgWriter) serveWebSocket(rw http.ResponseWriter, r *http.Request) {
+	c, err := w.newClient(r)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	conn, err := w.upgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// the clients do not send anything, but the control frames must be read
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	err = w.serve(c, func(msg PushMessage) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(pushWriteTimeout))
+		return conn.WriteJSON(msg)
+	}, done)
+	if err != nil {
+		_ = conn.SetWriteDeadline(time.Now().Add(pushWriteTimeout))
+		_ = conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error()))
+	}
+}
+
+func (w *pushMsgWriter) serveSSE(rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	if !w.allowOrigin(r) {
+		http.Error(rw, "origin is not allowed", http.StatusForbidden)
+		return
+	}
+	c, err := w.newClient(r)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	if origin := r.Header.Get("Origin"); origin != "" {
+		rw.Header().Set("Access-Control-Allow-Origin", origin)
+		rw.Header().Set("Vary", "Origin")
+	}
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	_ = w.serve(c, func(msg PushMessage) error {
+		bz, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(rw, "id: %d\nevent: %s\ndata: %s\n\n", msg.Height, msg.Key, bz); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}, r.Context().Done())
+}
+
+// pushFilter is empty for the parameters not given, which match all the messages
+type pushFilter struct {
+	keys         map[string]bool
+	tradingPairs map[string]bool
+	addresses    map[string]bool
+}
+
+func parsePushFilter(query url.Values) pushFilter {
+	return pushFilter{
+		keys:         paramSet(query[PushParamKey]),
+		tradingPairs: paramSet(query[PushParamTradingPair]),
+		addresses:    paramSet(query[PushParamAddress]),
+	}
+}
+
+func paramSet(values []string) map[string]bool {
+	var set map[string]bool
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			if set == nil {
+				set = make(map[string]bool)
+			}
+			set[s] = true
+		}
+	}
+	return set
+}
+
+func (f pushFilter) match(key string, fields *lazyFields) bool {
+	if f.keys != nil && !f.keys[key] {
+		return false
+	}
+	if f.tradingPairs != nil {
+		pair, _ := fields.get()["trading_pair"].(string)
+		if !f.tradingPairs[pair] {
+			return false
+		}
+	}
+	if f.addresses != nil && !f.matchAddress(fields.get()) {
+		return false
+	}
+	return true
+}
+
+// an order id is the address of its sender followed by '-' and a sequence number
+func (f pushFilter) matchAddress(fields map[string]interface{}) bool {
+	for name, field := range fields {
+		s, ok := field.(string)
+		if !ok {
+			continue
+		}
+		if f.addresses[s] {
+			return true
+		}
+		if name == "order_id" {
+			if idx := strings.LastIndex(s, "-"); idx > 0 && f.addresses[s[:idx]] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lazyFields decodes the top-level fields of the payload in a value only if a filter needs them
+type lazyFields struct {
+	key     []byte
+	value   []byte
+	fields  map[string]interface{}
+	decoded bool
+}
+
+func (lf *lazyFields) get() map[string]interface{} {
+	if !lf.decoded {
+		lf.decoded = true
+		_ = json.Unmarshal(payloadOf(lf.key, lf.value), &lf.fields)
+	}
+	return lf.fields
+}
@@ -0,0 +1,228 @@
+package msgqueue
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPushWriter(t *testing.T, opts string) *pushMsgWriter {
+	w, err := createMsgWriter("ws:127.0.0.1:0" + opts)
+	require.NoError(t, err)
+	return w.(*pushMsgWriter)
+}
+
+func waitForClients(t *testing.T, w *pushMsgWriter, n int) {
+	waitUntil(t, func() bool {
+		w.mtx.Lock()
+		defer w.mtx.Unlock()
+		return len(w.clients) == n
+	}, 5*time.Second)
+}
+
+func dialPush(t *testing.T, w *pushMsgWriter, query string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://%s%s?%s", w.Addr(), PushPathWebSocket, query), nil)
+	require.NoError(t, err)
+	return conn
+}
+
+func readPush(t *testing.T, conn *websocket.Conn) PushMessage {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var msg PushMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestPushMsgWriterWebSocket(t *testing.T) {
+	w := newTestPushWriter(t, "")
+	defer w.Close()
+	require.Equal(t, "ws:"+w.Addr().String(), w.String())
+
+	pairConn := dialPush(t, w, "trading_pair=abc/cet,xyz/cet")
+	defer pairConn.Close()
+	addrConn := dialPush(t, w, "key=fill_order_info&address=coinex1alice")
+	defer addrConn.Close()
+	waitForClients(t, w, 2)
+
+	// the filters match the payloads in the envelopes sent by the app
+	writeEnvelope := func(key, payload string) []byte {
+		value := NewEnvelope(9, "", 0, key, []byte(payload)).Bytes()
+		require.NoError(t, w.WriteKV([]byte(key), value))
+		return value
+	}
+	writeEnvelope("create_order_info", `{"order_id":"coinex1alice-1","sender":"coinex1alice","trading_pair":"abc/cet"}`)
+	writeEnvelope("fill_order_info", `{"order_id":"coinex1bob-3","trading_pair":"def/cet"}`)
+	filled := writeEnvelope("fill_order_info", `{"order_id":"coinex1alice-1","trading_pair":"xyz/cet"}`)
+	require.NoError(t, w.CommitBlock(9))
+	writeEnvelope("send_lock_coins", `{"from_address":"coinex1alice"}`)
+	// the bare payloads are filtered too
+	require.NoError(t, w.WriteKV([]byte("fill_order_info"), []byte(`{"order_id":"coinex1bob-4","trading_pair":"abc/cet"}`)))
+
+	msg := readPush(t, pairConn)
+	require.Equal(t, "create_order_info", msg.Key)
+	require.Equal(t, int64(9), msg.Height)
+	msg = readPush(t, pairConn)
+	require.Equal(t, PushMessage{Key: "fill_order_info", Height: 9, Payload: filled}, msg)
+	msg = readPush(t, pairConn)
+	require.Equal(t, int64(10), msg.Height)
+	require.Contains(t, string(msg.Payload), "coinex1bob-4")
+
+	// the address is matched in the order id, and the other keys are filtered out
+	msg = readPush(t, addrConn)
+	require.Equal(t, "fill_order_info", msg.Key)
+	require.Contains(t, string(msg.Payload), "coinex1alice-1")
+
+	addrConn.Close()
+	waitForClients(t, w, 1)
+	require.NoError(t, w.Close())
+	_, _, err := pairConn.ReadMessage()
+	require.Error(t, err)
+}
+
+func TestPushMsgWriterSSE(t *testing.T) {
+	w := newTestPushWriter(t, "?buffer=16")
+	defer w.Close()
+
+	resp, err := http.Get(fmt.Sprintf("http://%s%s?key=k1", w.Addr(), PushPathSSE))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	waitForClients(t, w, 1)
+
+	require.NoError(t, w.WriteKV([]byte("k0"), []byte(`{"height":3}`)))
+	require.NoError(t, w.WriteKV([]byte("k1"), []byte("not json")))
+	r := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 4 {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	require.Equal(t, []string{
+		"id: 0",
+		"event: k1",
+		`data: {"key":"k1","height":0,"payload":"not json"}`,
+		"",
+	}, lines)
+}
+
+func TestPushMsgWriterOrigins(t *testing.T) {
+	w := newTestPushWriter(t, "")
+	defer w.Close()
+	url := fmt.Sprintf("ws://%s%s", w.Addr(), PushPathWebSocket)
+
+	// only the pages of the same origin may subscribe by default
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"http://evil.example.com"}})
+	require.Error(t, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"http://" + w.Addr().String()}})
+	require.NoError(t, err)
+	conn.Close()
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("http://%s%s", w.Addr(), PushPathSSE), nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "http://evil.example.com")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// the pages of the given origins may subscribe
+	w2 := newTestPushWriter(t, "?origins=https://dex.example.com,http://localhost:3000")
+	defer w2.Close()
+	url = fmt.Sprintf("ws://%s%s", w2.Addr(), PushPathWebSocket)
+	conn, _, err = websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"http://localhost:3000"}})
+	require.NoError(t, err)
+	conn.Close()
+	_, _, err = websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"http://evil.example.com"}})
+	require.Error(t, err)
+
+	req, err = http.NewRequest("GET", fmt.Sprintf("http://%s%s", w2.Addr(), PushPathSSE), nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "https://dex.example.com")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "https://dex.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	// all the pages may subscribe with "*"
+	w3 := newTestPushWriter(t, "?origins=*")
+	defer w3.Close()
+	url = fmt.Sprintf("ws://%s%s", w3.Addr(), PushPathWebSocket)
+	conn, _, err = websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"http://evil.example.com"}})
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestPushMsgWriterDropSlowClient(t *testing.T) {
+	w := newTestPushWriter(t, "?buffer=2")
+	defer w.Close()
+
+	c := &pushClient{ch: make(chan PushMessage, w.buffer)}
+	_, err := w.subscribe(c)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, w.WriteKV([]byte("k"), []byte(`{}`)))
+	}
+	waitForClients(t, w, 0)
+	require.Len(t, c.ch, 2)
+	<-c.ch
+	<-c.ch
+	_, ok := <-c.ch
+	require.False(t, ok)
+
+	// the writer is not blocked by the slow client, and unsubscribing it again is harmless
+	w.unsubscribe(c)
+	require.NoError(t, w.WriteKV([]byte("k"), []byte(`{}`)))
+}
+
+func TestPushMsgWriterReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dw, err := createMsgWriter("dir:" + dir + "?max_size=1048576")
+	require.NoError(t, err)
+	for h := int64(1); h <= 3; h++ {
+		require.NoError(t, dw.WriteKV([]byte("k"), []byte(fmt.Sprintf(`{"height":%d}`, h))))
+		require.NoError(t, dw.WriteKV([]byte("other"), []byte(fmt.Sprintf(`{"height":%d}`, h))))
+		require.NoError(t, commitBlock(dw, h))
+	}
+	require.NoError(t, dw.Close())
+
+	_, err = createMsgWriter("ws:127.0.0.1:0?format=avro")
+	require.Error(t, err)
+	_, err = createMsgWriter("ws:127.0.0.1:0?buffer=0")
+	require.Error(t, err)
+
+	// replaying needs the replay dir
+	w := newTestPushWriter(t, "")
+	resp, err := http.Get(fmt.Sprintf("http://%s%s?from_height=2", w.Addr(), PushPathSSE))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NoError(t, w.Close())
+
+	w = newTestPushWriter(t, "?replay_dir="+dir)
+	defer w.Close()
+	require.NoError(t, w.CommitBlock(3))
+	conn := dialPush(t, w, "key=k&from_height=2")
+	defer conn.Close()
+	waitForClients(t, w, 1)
+	require.NoError(t, w.WriteKV([]byte("k"), []byte(`{"height":4}`)))
+
+	for h := int64(2); h <= 4; h++ {
+		msg := readPush(t, conn)
+		require.Equal(t, "k", msg.Key)
+		require.Equal(t, h, msg.Height)
+	}
+}
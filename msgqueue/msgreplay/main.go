@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/coinexchain/cet-sdk/msgqueue"
+)
+
+// msgreplay reads back the messages written by the file, dir and pipe writers of the message queue:
+//
+//	msgreplay cat -from 100 -to 200 path/to/dir > messages.jsonl
+//	msgreplay replay -from 100 -writer kafka:broker1,broker2 path/to/dir
+//
+// The path is a dir of segments, a file, a named pipe, or "-" for the standard input. The cat command
+// prints each message as a JSON line with its height, key and value, and the replay command writes the
+// messages to any writer configured like the brokers of the node, for example to publish them to kafka
+// again after an outage.
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "cat":
+		err = runCat(os.Args[2:], os.Stdout)
+	case "replay":
+		err = runReplay(os.Args[2:], os.Stderr)
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: msgreplay cat|replay [flags] path\n")
+	os.Exit(2)
+}
+
+type rangeFlags struct {
+	from, to *int64
+}
+
+func addRangeFlags(fs *flag.FlagSet) rangeFlags {
+	return rangeFlags{
+		from: fs.Int64("from", 0, "the lowest height of the messages"),
+		to:   fs.Int64("to", 0, "the highest height of the messages, not limited if it is zero"),
+	}
+}
+
+func openRecords(fs *flag.FlagSet, rf rangeFlags) (msgqueue.RecordReader, error) {
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("expect one path, but got %d", fs.NArg())
+	}
+	r, err := msgqueue.OpenRecords(fs.Arg(0), *rf.from)
+	if err != nil {
+		return nil, err
+	}
+	return msgqueue.SeekHeights(r, *rf.from, *rf.to), nil
+}
+
+// the values are printed as JSON if they are, or as strings otherwise
+type catLine struct {
+	Height int64           `json:"height"`
+	Key    string          `json:"key"`
+	Value  json.RawMessage `json:"value"`
+}
+
+func runCat(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	rf := addRangeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r, err := openRecords(fs, rf)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+	enc := json.NewEncoder(w)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		value := json.RawMessage(rec.Value)
+		if !json.Valid(value) {
+			value, _ = json.Marshal(string(rec.Value))
+		}
+		if err := enc.Encode(catLine{Height: rec.Height, Key: string(rec.Key), Value: value}); err != nil {
+			return err
+		}
+	}
+}
+
+func runReplay(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	rf := addRangeFlags(fs)
+	writerCfg := fs.String("writer", "os:stdout", "the writer to replay the messages to, such as kafka:broker1,broker2")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r, err := openRecords(fs, rf)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := msgqueue.NewMsgWriter(*writerCfg)
+	if err != nil {
+		return err
+	}
+	n, err := msgqueue.Replay(r, w)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "replayed %d messages to %s\n", n, w.String())
+	return nil
+}
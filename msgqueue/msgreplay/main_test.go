@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "msgreplay")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "messages.txt")
+	require.NoError(t, ioutil.WriteFile(src, []byte("k1#{\"height\":1}\r\nk2#text\r\nk3#{\"height\":2}\r\n"), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, runCat([]string{"-from", "1", "-to", "1", src}, &out))
+	require.Equal(t, `{"height":1,"key":"k1","value":{"height":1}}`+"\n"+
+		`{"height":1,"key":"k2","value":"text"}`+"\n", out.String())
+
+	dst := filepath.Join(dir, "replayed.txt")
+	out.Reset()
+	require.NoError(t, runReplay([]string{"-from", "2", "-writer", "file:" + dst, src}, &out))
+	require.Equal(t, "replayed 1 messages to file\n", out.String())
+	bz, err := ioutil.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, "k3#{\"height\":2}\r\n", string(bz))
+
+	require.Error(t, runCat([]string{}, &out))
+	require.Error(t, runCat([]string{filepath.Join(dir, "missing")}, &out))
+}
@@ -0,0 +1,198 @@
+package msgqueue
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	dbm "github.com/tendermint/tm-db"
+)
+
+const OutboxDBName = "msgqueue_outbox"
+
+var (
+	// the messages are stored in the order of their positions
+	outboxMsgPrefix = []byte{0x01}
+	// the committed offsets of the writers, i.e. the positions of the last messages they have written
+	outboxOffsetPrefix = []byte{0x02}
+	// the height of the last stored block, which is kept after its messages are pruned
+	outboxLastHeightKey = []byte{0x03}
+)
+
+// Position locates a message in the outbox, by the height of the block it was sent in
+// and its index among the messages of that block
+type Position struct {
+	Height int64
+	Index  int64
+}
+
+func (p Position) Before(other Position) bool {
+	return p.Height < other.Height || (p.Height == other.Height && p.Index < other.Index)
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Height, p.Index)
+}
+
+func (p Position) bytes() []byte {
+	var bz [16]byte
+	binary.BigEndian.PutUint64(bz[:8], uint64(p.Height))
+	binary.BigEndian.PutUint64(bz[8:], uint64(p.Index))
+	return bz[:]
+}
+
+func positionFromBytes(bz []byte) Position {
+	return Position{
+		Height: int64(binary.BigEndian.Uint64(bz[:8])),
+		Index:  int64(binary.BigEndian.Uint64(bz[8:16])),
+	}
+}
+
+// OutboxMsg is one message kept in the outbox
+type OutboxMsg struct {
+	Position
+	Key   []byte
+	Value []byte
+}
+
+// the key is prefixed by its length, and the value follows it
+func encodeOutboxMsg(k, v []byte) []byte {
+	buf := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(k)+len(v))
+	n := binary.PutUvarint(buf, uint64(len(k)))
+	buf = append(append(buf[:n], k...), v...)
+	return buf
+}
+
+func decodeOutboxMsg(bz []byte) (k, v []byte, err error) {
+	size, n := binary.Uvarint(bz)
+	if n <= 0 || uint64(len(bz)-n) < size {
+		return nil, nil, fmt.Errorf("invalid message in the outbox")
+	}
+	return bz[n : n+int(size)], bz[n+int(size):], nil
+}
+
+// Outbox is a durable queue of the messages in a LevelDB. The messages of a block are written
+// synchronously when the block is committed, and every writer reads them from its own committed
+// offset, so the messages are neither lost nor repeated after the node restarts.
+type Outbox struct {
+	db         dbm.DB
+	mtx        sync.Mutex
+	lastHeight int64
+}
+
+func NewOutbox(dir string) (*Outbox, error) {
+	db, err := dbm.NewGoLevelDB(OutboxDBName, dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewOutboxWithDB(db), nil
+}
+
+func NewOutboxWithDB(db dbm.DB) *Outbox {
+	o := &Outbox{db: db}
+	if bz := db.Get(outboxLastHeightKey); len(bz) == 8 {
+		o.lastHeight = int64(binary.BigEndian.Uint64(bz))
+	}
+	return o
+}
+
+// LastHeight returns the height of the last block whose messages are in the outbox
+func (o *Outbox) LastHeight() int64 {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	return o.lastHeight
+}
+
+// AppendBlock stores the messages sent in a block with one synchronous write. A block which is
+// not higher than the last stored one is ignored, because it is replayed after a restart and its
+// messages are already stored. It returns false if the block is ignored.
+func (o *Outbox) AppendBlock(height int64, msgs [][2][]byte) bool {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if height <= o.lastHeight {
+		return false
+	}
+	batch := o.db.NewBatch()
+	defer batch.Close()
+	for i, msg := range msgs {
+		key := append(append([]byte{}, outboxMsgPrefix...), Position{height, int64(i)}.bytes()...)
+		batch.Set(key, encodeOutboxMsg(msg[0], msg[1]))
+	}
+	var heightBytes [8]byte
+	binary.BigEndian.PutUint64(heightBytes[:], uint64(height))
+	batch.Set(outboxLastHeightKey, heightBytes[:])
+	batch.WriteSync()
+	o.lastHeight = height
+	return true
+}
+
+// Read returns at most 'limit' messages after the position 'from'
+func (o *Outbox) Read(from Position, limit int) ([]OutboxMsg, error) {
+	start := append(append([]byte{}, outboxMsgPrefix...), Position{from.Height, from.Index + 1}.bytes()...)
+	iter := o.db.Iterator(start, outboxOffsetPrefix)
+	defer iter.Close()
+	var msgs []OutboxMsg
+	for ; iter.Valid() && len(msgs) < limit; iter.Next() {
+		k, v, err := decodeOutboxMsg(iter.Value())
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, OutboxMsg{Position: positionFromBytes(iter.Key()[1:]), Key: k, Value: v})
+	}
+	return msgs, nil
+}
+
+func offsetKey(writer string) []byte {
+	return append(append([]byte{}, outboxOffsetPrefix...), writer...)
+}
+
+// Offset returns the position of the last message written by the writer, and a new writer
+// begins from the oldest message kept in the outbox
+func (o *Outbox) Offset(writer string) Position {
+	bz := o.db.Get(offsetKey(writer))
+	if len(bz) != 16 {
+		return Position{Index: -1}
+	}
+	return positionFromBytes(bz)
+}
+
+// Ack commits the offset of the writer after it has written the message at 'pos'
+func (o *Outbox) Ack(writer string, pos Position) {
+	o.db.Set(offsetKey(writer), pos.bytes())
+}
+
+// Prune removes the messages which have been written by all the writers
+func (o *Outbox) Prune(writers []string) {
+	if len(writers) == 0 {
+		return
+	}
+	end := o.Offset(writers[0])
+	for _, w := range writers[1:] {
+		if pos := o.Offset(w); pos.Before(end) {
+			end = pos
+		}
+	}
+	if end.Index < 0 {
+		return
+	}
+	endKey := append(append([]byte{}, outboxMsgPrefix...), Position{end.Height, end.Index + 1}.bytes()...)
+	iter := o.db.Iterator(outboxMsgPrefix, endKey)
+	var keys [][]byte
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	iter.Close()
+	if len(keys) == 0 {
+		return
+	}
+	batch := o.db.NewBatch()
+	defer batch.Close()
+	for _, key := range keys {
+		batch.Delete(key)
+	}
+	batch.Write()
+}
+
+func (o *Outbox) Close() {
+	o.db.Close()
+}
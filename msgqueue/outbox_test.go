@@ -0,0 +1,244 @@
+package msgqueue
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestOutbox(t *testing.T) {
+	db := dbm.NewMemDB()
+	o := NewOutboxWithDB(db)
+	require.EqualValues(t, 0, o.LastHeight())
+	require.True(t, o.AppendBlock(10, [][2][]byte{{[]byte("k1"), []byte("v1")}, {[]byte("k2"), []byte("")}}))
+	require.True(t, o.AppendBlock(11, nil))
+	require.True(t, o.AppendBlock(12, [][2][]byte{{[]byte(""), []byte("v3")}}))
+	// a replayed block is ignored
+	require.False(t, o.AppendBlock(12, [][2][]byte{{[]byte("k4"), []byte("v4")}}))
+	require.EqualValues(t, 12, o.LastHeight())
+
+	// a new writer begins from the oldest message
+	require.Equal(t, Position{0, -1}, o.Offset("a"))
+	msgs, err := o.Read(o.Offset("a"), 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(msgs))
+	require.Equal(t, Position{10, 1}, msgs[1].Position)
+	require.Equal(t, "k2", string(msgs[1].Key))
+	require.Equal(t, "", string(msgs[1].Value))
+	require.Equal(t, Position{12, 0}, msgs[2].Position)
+	require.Equal(t, "v3", string(msgs[2].Value))
+
+	o.Ack("a", msgs[1].Position)
+	msgs, err = o.Read(o.Offset("a"), 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(msgs))
+	require.Equal(t, Position{12, 0}, msgs[0].Position)
+
+	// only the messages written by all the writers are pruned
+	o.Prune([]string{"a", "b"})
+	msgs, _ = o.Read(Position{0, -1}, 10)
+	require.Equal(t, 3, len(msgs))
+	o.Ack("b", Position{10, 0})
+	o.Prune([]string{"a", "b"})
+	msgs, _ = o.Read(Position{0, -1}, 10)
+	require.Equal(t, 2, len(msgs))
+	o.Ack("b", Position{12, 0})
+	o.Ack("a", Position{12, 0})
+	o.Prune([]string{"a", "b"})
+	msgs, _ = o.Read(Position{0, -1}, 10)
+	require.Equal(t, 0, len(msgs))
+
+	// the last height is kept after the messages are pruned
+	o = NewOutboxWithDB(db)
+	require.EqualValues(t, 12, o.LastHeight())
+	require.False(t, o.AppendBlock(12, [][2][]byte{{[]byte("k4"), []byte("v4")}}))
+	require.Equal(t, Position{12, 0}, o.Offset("a"))
+}
+
+// the writer fails before it has written 'failures' messages
+type flakyMsgWriter struct {
+	mtx      sync.Mutex
+	failures int
+	msgs     []string
+	heights  []int64
+}
+
+func (w *flakyMsgWriter) WriteKV(k, v []byte) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if string(k) == "bad" {
+		return EncodeError{Key: "bad", Err: errors.New("invalid payload")}
+	}
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("broker is down")
+	}
+	w.msgs = append(w.msgs, string(k)+"#"+string(v))
+	return nil
+}
+
+func (w *flakyMsgWriter) written() []string {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]string{}, w.msgs...)
+}
+
+func (w *flakyMsgWriter) CommitBlock(height int64) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.heights = append(w.heights, height)
+	return nil
+}
+
+func (w *flakyMsgWriter) committed() []int64 {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]int64{}, w.heights...)
+}
+
+func (w *flakyMsgWriter) Close() error   { return nil }
+func (w *flakyMsgWriter) String() string { return "flaky" }
+
+func TestDispatcher(t *testing.T) {
+	db := dbm.NewMemDB()
+	o := NewOutboxWithDB(db)
+	w := &flakyMsgWriter{failures: 3}
+	d := newDispatcher("flaky", w, o, nil)
+	d.start()
+	o.AppendBlock(1, [][2][]byte{{[]byte("k1"), []byte("v1")}, {[]byte("k2"), []byte("v2")}})
+	d.wake()
+	waitUntil(t, func() bool { return len(w.written()) == 2 }, time.Second)
+	waitUntil(t, func() bool { return len(w.committed()) == 1 }, time.Second)
+	d.stop()
+	require.Equal(t, []string{"k1#v1", "k2#v2"}, w.written())
+	require.Equal(t, []int64{1}, w.committed())
+
+	// the dispatcher resumes from its offset after restart, and skips the payloads which can not be encoded
+	o.AppendBlock(2, [][2][]byte{{[]byte("bad"), []byte("v")}, {[]byte("k3"), []byte("v3")}})
+	w = &flakyMsgWriter{}
+	d = newDispatcher("flaky", w, NewOutboxWithDB(db), nil)
+	d.start()
+	waitUntil(t, func() bool { return len(w.written()) == 1 }, time.Second)
+	waitUntil(t, func() bool { return len(w.committed()) == 2 }, time.Second)
+	d.stop()
+	require.Equal(t, []string{"k3#v3"}, w.written())
+	// the block at the offset may be committed again
+	require.Equal(t, []int64{1, 2}, w.committed())
+
+	// a stopped dispatcher gives up the message being retried
+	w = &flakyMsgWriter{failures: 1000}
+	d = newDispatcher("flaky", w, o, nil)
+	d.start()
+	o.AppendBlock(3, [][2][]byte{{[]byte("k4"), []byte("v4")}})
+	d.wake()
+	d.stop()
+	require.Equal(t, Position{2, 1}, o.Offset("flaky"))
+}
+
+// the writer keeps the messages until they are flushed, and the flushes fail while it is down
+type bufferedMsgWriter struct {
+	flakyMsgWriter
+	down     bool
+	buffered []string
+}
+
+func (w *bufferedMsgWriter) WriteKV(k, v []byte) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.buffered = append(w.buffered, string(k)+"#"+string(v))
+	return nil
+}
+
+func (w *bufferedMsgWriter) Flush() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.down {
+		return errors.New("webhook is down")
+	}
+	w.msgs = append(w.msgs, w.buffered...)
+	w.buffered = nil
+	return nil
+}
+
+func (w *bufferedMsgWriter) setDown(down bool) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.down = down
+}
+
+func TestDispatcherWithFlusher(t *testing.T) {
+	o := NewOutboxWithDB(dbm.NewMemDB())
+	w := &bufferedMsgWriter{down: true}
+	d := newDispatcher("buffered", w, o, nil)
+	d.start()
+	o.AppendBlock(1, [][2][]byte{{[]byte("k1"), []byte("v1")}, {[]byte("k2"), []byte("v2")}})
+	d.wake()
+	// the messages are not acked before they are flushed
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, Position{0, -1}, o.Offset("buffered"))
+	require.Empty(t, w.committed())
+
+	w.setDown(false)
+	waitUntil(t, func() bool { return len(w.written()) == 2 }, 5*time.Second)
+	waitUntil(t, func() bool { return len(w.committed()) == 1 }, time.Second)
+	require.Equal(t, Position{1, 1}, o.Offset("buffered"))
+
+	// a stopped dispatcher does not ack the messages which are not flushed
+	w.setDown(true)
+	o.AppendBlock(2, [][2][]byte{{[]byte("k3"), []byte("v3")}})
+	d.wake()
+	time.Sleep(50 * time.Millisecond)
+	d.stop()
+	require.Equal(t, Position{1, 1}, o.Offset("buffered"))
+}
+
+func TestDurableProducer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	msgFile := filepath.Join(dir, "messages.txt")
+
+	p, err := NewDurableProducerFromConfig([]string{"file:" + msgFile}, "bank", true, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"file"}, p.GetMode())
+	p.SendMsg([]byte("k1"), []byte("v1"))
+	p.SendMsg([]byte("k2"), []byte("v2"))
+	// nothing is written before the block is committed
+	require.Equal(t, 0, GetFileSize(msgFile))
+	p.CommitBlock(1)
+	waitUntil(t, func() bool { return GetFileSize(msgFile) == 14 }, time.Second)
+	p.Close()
+
+	// the replayed block is not written again after restart, and the offset of the writer is kept
+	// when its options are changed
+	p, err = NewDurableProducerFromConfig([]string{"file:" + msgFile + "?framing=line"}, "bank", true, dir, nil)
+	require.NoError(t, err)
+	p.SendMsg([]byte("k1"), []byte("v1"))
+	p.CommitBlock(1)
+	p.SendMsg([]byte("k3"), []byte("v3"))
+	p.CommitBlock(2)
+	waitUntil(t, func() bool { return GetFileSize(msgFile) == 21 }, time.Second)
+	p.Close()
+
+	data, err := ioutil.ReadFile(msgFile)
+	require.NoError(t, err)
+	require.Equal(t, "k1#v1\r\nk2#v2\r\nk3#v3\r\n", string(data))
+
+	// the writers with the same config without options must be named
+	_, err = NewDurableProducerFromConfig([]string{"file:" + msgFile, "file:" + msgFile + "?framing=line"}, "bank", true, dir, nil)
+	require.Error(t, err)
+}
+
+func TestWriterName(t *testing.T) {
+	require.Equal(t, "kafka:b1,b2", writerName("kafka:b1,b2"))
+	require.Equal(t, "webhook:https://example.com/hook", writerName("webhook:https://example.com/hook?secret_file=/etc/secret"))
+	require.Equal(t, "hook1", writerName("webhook:https://example.com/hook?name=hook1&batch_size=10"))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tendermint/tendermint/libs/log"
@@ -15,21 +16,29 @@ const (
 	FlagBrokers       = "brokers"
 	FlagTopics        = "subscribe-modules"
 	FlagFeatureToggle = "feature-toggle"
+	FlagOutboxDir     = "msgqueue-outbox-dir"
 	KafkaPubTopic     = "coinex-dex"
 )
 
 const (
-	CfgPrefixFile  = "file:"
-	CfgPrefixKafka = "kafka:"
-	CfgPrefixOS    = "os:"
-	CfgPrefixDir   = "dir:"
-	CfgNamedPipe   = "pipe:"
+	CfgPrefixFile    = "file:"
+	CfgPrefixKafka   = "kafka:"
+	CfgPrefixOS      = "os:"
+	CfgPrefixDir     = "dir:"
+	CfgNamedPipe     = "pipe:"
+	CfgPrefixWS      = "ws:"
+	CfgPrefixWebhook = "webhook:"
+	CfgPrefixNATS    = "nats:"
+	CfgPrefixRedis   = "redis:"
 )
 
 const RetryNum = math.MaxInt64
 
 type MsgSender interface {
 	SendMsg(key []byte, v []byte)
+	// CommitBlock is called after all the messages of the block at 'height' are sent. The app must call it
+	// in its Commit, otherwise the messages are never written when the outbox is used.
+	CommitBlock(height int64)
 	IsSubscribed(topic string) bool
 	IsOpenToggle() bool
 	GetMode() []string
@@ -37,16 +46,49 @@ type MsgSender interface {
 }
 
 type producer struct {
-	toggle     bool
-	subTopics  map[string]struct{}
-	msgWriters []MsgWriter
-	log        log.Logger
+	toggle      bool
+	subTopics   map[string]struct{}
+	msgWriters  []MsgWriter
+	writerNames []string
+	log         log.Logger
+
+	// the messages are written by the dispatchers from the outbox, if the outbox is used
+	outbox      *Outbox
+	pending     *pendingMsgs
+	dispatchers []*dispatcher
+}
+
+// the messages sent in the current block, which are stored in the outbox when the block is committed
+type pendingMsgs struct {
+	mtx  sync.Mutex
+	msgs [][2][]byte
+}
+
+func (p *pendingMsgs) add(k, v []byte) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.msgs = append(p.msgs, [2][]byte{k, v})
+}
+
+func (p *pendingMsgs) take() [][2][]byte {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	msgs := p.msgs
+	p.msgs = nil
+	return msgs
 }
 
 func NewProducer(log log.Logger) MsgSender {
 	brokers := viper.GetStringSlice(FlagBrokers)
 	topics := viper.GetString(FlagTopics)
 	featureToggle := viper.GetBool(FlagFeatureToggle)
+	if outboxDir := viper.GetString(FlagOutboxDir); outboxDir != "" {
+		p, err := NewDurableProducerFromConfig(brokers, topics, featureToggle, outboxDir, log)
+		if err != nil {
+			panic(err)
+		}
+		return p
+	}
 	return NewProducerFromConfig(brokers, topics, featureToggle, log)
 }
 
@@ -57,11 +99,50 @@ func NewProducerFromConfig(brokers []string, topics string, featureToggle bool,
 		log:        log,
 	}
 
-	p.init(brokers, topics, featureToggle)
+	p.init(brokers, topics, featureToggle, false)
 	return p
 }
 
-func (p *producer) init(brokers []string, topics string, featureToggle bool) {
+// NewDurableProducerFromConfig returns a producer which stores the messages of every block in a
+// LevelDB under outboxDir, and writes them to the writers in the background. So the consensus is
+// not blocked by the writers, and the stored messages are delivered after the node restarts.
+func NewDurableProducerFromConfig(brokers []string, topics string, featureToggle bool, outboxDir string, log log.Logger) (MsgSender, error) {
+	p := producer{
+		subTopics:  make(map[string]struct{}),
+		msgWriters: nil,
+		log:        log,
+	}
+	p.init(brokers, topics, featureToggle, true)
+	if len(p.msgWriters) == 0 {
+		return p, nil
+	}
+	names := make(map[string]struct{}, len(p.writerNames))
+	for _, name := range p.writerNames {
+		if _, ok := names[name]; ok {
+			p.Close()
+			return nil, fmt.Errorf("more than one writer is named %s, give them different %s options", name, OptName)
+		}
+		names[name] = struct{}{}
+	}
+
+	outbox, err := NewOutbox(outboxDir)
+	if err != nil {
+		p.Close()
+		return nil, err
+	}
+	p.outbox = outbox
+	p.pending = &pendingMsgs{}
+	for i, w := range p.msgWriters {
+		d := newDispatcher(p.writerNames[i], w, outbox, log)
+		d.start()
+		p.dispatchers = append(p.dispatchers, d)
+	}
+	return p, nil
+}
+
+// The writers which keep the messages in memory, such as the webhook writer, may block the consensus
+// while they send the messages, so they are only created for the durable producer.
+func (p *producer) init(brokers []string, topics string, featureToggle bool, durable bool) {
 	if len(brokers) == 0 || len(topics) == 0 {
 		return
 	}
@@ -85,8 +166,14 @@ func (p *producer) init(brokers []string, topics string, featureToggle bool) {
 			if p.log != nil {
 				p.log.Error(fmt.Sprintf("create msgWrite : %s failed, err : %s\n", broker, err.Error()))
 			}
+		} else if _, ok := msgWriter.(MsgFlusher); ok && !durable {
+			msgWriter.Close()
+			if p.log != nil {
+				p.log.Error(fmt.Sprintf("msgWrite : %s needs the outbox, set --%s\n", msgWriter.String(), FlagOutboxDir))
+			}
 		} else {
 			p.msgWriters = append(p.msgWriters, msgWriter)
+			p.writerNames = append(p.writerNames, writerName(broker))
 			if p.log != nil {
 				p.log.Info(fmt.Sprintf("create write : %s succueed", msgWriter.String()))
 			}
@@ -100,6 +187,9 @@ func (p *producer) init(brokers []string, topics string, featureToggle bool) {
 }
 
 func (p producer) Close() {
+	for _, d := range p.dispatchers {
+		d.stop()
+	}
 	for _, w := range p.msgWriters {
 		if err := w.Close(); err != nil {
 			if p.log != nil {
@@ -107,12 +197,27 @@ func (p producer) Close() {
 			}
 		}
 	}
+	if p.outbox != nil {
+		p.outbox.Close()
+	}
 }
 
 func (p producer) SendMsg(k []byte, v []byte) {
+	if p.outbox != nil {
+		p.pending.add(k, v)
+		return
+	}
 	for _, w := range p.msgWriters {
 		if err := Retry(RetryNum, time.Millisecond, func() error {
-			return w.WriteKV(k, v)
+			err := w.WriteKV(k, v)
+			if isEncodeError(err) {
+				// the message is skipped, for writing it again fails too
+				if p.log != nil {
+					p.log.Error(fmt.Sprintf("write msg to %s failed, err : %s\n", w.String(), err.Error()))
+				}
+				return nil
+			}
+			return err
 		}); err != nil {
 			if p.log != nil {
 				p.log.Error(fmt.Sprintf("write msg to %s failed, err : %s\n", w.String(), err.Error()))
@@ -121,6 +226,27 @@ func (p producer) SendMsg(k []byte, v []byte) {
 	}
 }
 
+// CommitBlock stores the messages of the block in the outbox, and wakes up the dispatchers.
+// The messages written by all the writers are removed from the outbox.
+// Without the outbox, the writers are told that the block is committed.
+func (p producer) CommitBlock(height int64) {
+	if p.outbox == nil {
+		for _, w := range p.msgWriters {
+			if err := commitBlock(w, height); err != nil && p.log != nil {
+				p.log.Error(fmt.Sprintf("commit block %d to %s failed, err : %s\n", height, w.String(), err.Error()))
+			}
+		}
+		return
+	}
+	if !p.outbox.AppendBlock(height, p.pending.take()) && p.log != nil {
+		p.log.Info(fmt.Sprintf("the messages of block %d are already in the outbox", height))
+	}
+	for _, d := range p.dispatchers {
+		d.wake()
+	}
+	p.outbox.Prune(p.writerNames)
+}
+
 func (p producer) IsSubscribed(topic string) bool {
 	if !p.toggle {
 		return false
@@ -24,3 +24,20 @@ func TestNewProducer(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "foo#bar\r\n", string(data))
 }
+
+func TestWebhookNeedsOutbox(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the webhook writer is not created without the outbox, for it would block the consensus
+	hook := "webhook:http://127.0.0.1:1/hook"
+	p := NewProducerFromConfig([]string{hook, "os:stdout"}, "bank", true, nil)
+	require.Equal(t, []string{"stdout"}, p.GetMode())
+	p.Close()
+
+	p, err = NewDurableProducerFromConfig([]string{hook}, "bank", true, dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{hook}, p.GetMode())
+	p.Close()
+}
@@ -0,0 +1,337 @@
+package msgqueue
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/zstd"
+)
+
+// Record is a message read back from the files written by the file, dir and pipe writers
+type Record struct {
+	Key   []byte
+	Value []byte
+	// the height of the block the message is sent in, which is taken from the "height" or
+	// "block_height" field of the JSON value, as the envelopes and most payloads have one.
+	// Otherwise it is the height of the record before it, or zero if no height is known yet.
+	Height int64
+}
+
+// RecordReader iterates the records in the order they are written
+type RecordReader interface {
+	// Next returns io.EOF after the last record, and io.ErrUnexpectedEOF if the last record is torn,
+	// and ErrCorruptRecord if the checksum of a record framed by records is wrong
+	Next() (Record, error)
+	Close() error
+}
+
+var _ RecordReader = (*streamRecordReader)(nil)
+
+// streamRecordReader reads the records framed by records if the stream begins with the magic header,
+// or otherwise in the format "key#value\r\n". As a value framed by lines may contain "\r\n", a value
+// which begins like a JSON object or array is read until it is a complete JSON value.
+type streamRecordReader struct {
+	r       *bufio.Reader
+	closer  io.Closer
+	height  int64
+	framing string
+}
+
+// NewRecordReader reads the records from a stream, such as a pipe or the standard input
+func NewRecordReader(r io.Reader) RecordReader {
+	return &streamRecordReader{r: bufio.NewReader(r)}
+}
+
+// OpenRecordFile reads the records in a file, which is decompressed if its name ends with .gz or .zst
+func OpenRecordFile(path string) (RecordReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := decompressReader(file, path)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if rc == io.ReadCloser(file) {
+		return &streamRecordReader{r: bufio.NewReader(file), closer: file}, nil
+	}
+	return &streamRecordReader{r: bufio.NewReader(rc), closer: multiCloser{rc, file}}, nil
+}
+
+func decompressReader(file *os.File, path string) (io.ReadCloser, error) {
+	switch {
+	case strings.HasSuffix(path, compressionSuffixes[CompressGzip]):
+		return gzip.NewReader(file)
+	case strings.HasSuffix(path, compressionSuffixes[CompressZstd]):
+		return zstd.NewReader(file), nil
+	default:
+		return file, nil
+	}
+}
+
+type multiCloser []io.Closer
+
+func (mc multiCloser) Close() error {
+	var err error
+	for _, c := range mc {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (r *streamRecordReader) Next() (Record, error) {
+	if r.framing == "" {
+		framed, err := skipMagic(r.r)
+		if err != nil {
+			return Record{}, err
+		}
+		r.framing = FramingLine
+		if framed {
+			r.framing = FramingRecord
+		}
+	}
+	var (
+		key, value []byte
+		err        error
+	)
+	if r.framing == FramingRecord {
+		key, value, err = r.nextFramedRecord()
+	} else {
+		key, value, err = r.nextLine()
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	if height := heightOfValue(value); height != 0 {
+		r.height = height
+	}
+	return Record{Key: key, Value: value, Height: r.height}, nil
+}
+
+// the headers written when the writer reopens a pipe are skipped
+func (r *streamRecordReader) nextFramedRecord() (key, value []byte, err error) {
+	if _, err := skipMagic(r.r); err != nil {
+		return nil, nil, err
+	}
+	key, value, _, err = readFramedRecord(r.r)
+	return key, value, err
+}
+
+func (r *streamRecordReader) nextLine() (key, value []byte, err error) {
+	key, err = r.r.ReadBytes('#')
+	if err == io.EOF && len(key) == 0 {
+		return nil, nil, io.EOF
+	} else if err == io.EOF {
+		return nil, nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, nil, err
+	}
+	key = key[:len(key)-1]
+
+	for {
+		line, err := r.r.ReadBytes('\n')
+		value = append(value, line...)
+		if err == io.EOF {
+			return nil, nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if bytes.HasSuffix(value, []byte("\r\n")) && isCompleteValue(value[:len(value)-2]) {
+			return key, value[:len(value)-2], nil
+		}
+	}
+}
+
+func (r *streamRecordReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+func isCompleteValue(value []byte) bool {
+	if len(value) != 0 && (value[0] == '{' || value[0] == '[') {
+		return json.Valid(value)
+	}
+	return true
+}
+
+func heightOfValue(value []byte) int64 {
+	if len(value) == 0 || value[0] != '{' {
+		return 0
+	}
+	var heights struct {
+		Height      int64 `json:"height"`
+		BlockHeight int64 `json:"block_height"`
+	}
+	if json.Unmarshal(value, &heights) != nil {
+		return 0
+	}
+	if heights.Height != 0 {
+		return heights.Height
+	}
+	return heights.BlockHeight
+}
+
+var _ RecordReader = (*dirRecordReader)(nil)
+
+// dirRecordReader reads the segments written by the dir writer one after another
+type dirRecordReader struct {
+	dir      string
+	segments []segmentFile
+	index    *SegmentIndex
+	current  RecordReader
+	height   int64
+}
+
+// OpenRecordDir reads the records in the segments of a dir, skipping the segments which are known
+// by the index to have only the blocks lower than 'fromHeight'
+func OpenRecordDir(dir string, fromHeight int64) (RecordReader, error) {
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	index, err := LoadSegmentIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &dirRecordReader{dir: dir, index: index}
+	infos := make(map[int]SegmentInfo)
+	for _, s := range index.Segments() {
+		infos[s.Index] = s
+	}
+	for _, s := range segments {
+		if info, ok := infos[s.Index]; ok && info.LastHeight != 0 && info.LastHeight < fromHeight {
+			continue
+		}
+		r.segments = append(r.segments, s)
+	}
+	return r, nil
+}
+
+func (r *dirRecordReader) Next() (Record, error) {
+	for {
+		if r.current == nil {
+			if len(r.segments) == 0 {
+				return Record{}, io.EOF
+			}
+			s := r.segments[0]
+			r.segments = r.segments[1:]
+			current, err := OpenRecordFile(filepath.Join(r.dir, s.Name))
+			if err != nil {
+				return Record{}, err
+			}
+			r.current = current
+			// the records before the first one with a height belong to the first block of the segment
+			for _, info := range r.index.Segments() {
+				if info.Index == s.Index && info.FirstHeight != 0 {
+					r.height = info.FirstHeight
+				}
+			}
+		}
+		rec, err := r.current.Next()
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			continue
+		} else if err != nil {
+			return rec, err
+		}
+		if rec.Height == 0 {
+			rec.Height = r.height
+		}
+		r.height = rec.Height
+		return rec, nil
+	}
+}
+
+func (r *dirRecordReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
+
+// OpenRecords reads the records in a dir of segments, or in a file or pipe, or in the standard
+// input if the path is "-"
+func OpenRecords(path string, fromHeight int64) (RecordReader, error) {
+	if path == "-" {
+		return NewRecordReader(os.Stdin), nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return OpenRecordDir(path, fromHeight)
+	}
+	return OpenRecordFile(path)
+}
+
+var _ RecordReader = (*heightRangeReader)(nil)
+
+// heightRangeReader skips the records out of the range of heights
+type heightRangeReader struct {
+	RecordReader
+	from, to int64
+}
+
+// SeekHeights returns the records whose heights are in [from, to], where 'to' is not limited if it is zero
+func SeekHeights(r RecordReader, from, to int64) RecordReader {
+	return &heightRangeReader{RecordReader: r, from: from, to: to}
+}
+
+func (r *heightRangeReader) Next() (Record, error) {
+	for {
+		rec, err := r.RecordReader.Next()
+		if err != nil {
+			return rec, err
+		}
+		if rec.Height < r.from || (r.to != 0 && rec.Height > r.to) {
+			continue
+		}
+		return rec, nil
+	}
+}
+
+// Replay writes all the records to the writer, and tells it when the height of the records changes,
+// such that the messages can be published again after an outage. It returns the number of records
+// written.
+func Replay(r RecordReader, w MsgWriter) (int, error) {
+	n := 0
+	height := int64(0)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return n, err
+		}
+		if rec.Height != height && height != 0 {
+			if err := commitBlock(w, height); err != nil {
+				return n, err
+			}
+		}
+		height = rec.Height
+		if err := w.WriteKV(rec.Key, rec.Value); err != nil {
+			return n, fmt.Errorf("write record %d failed: %s", n, err.Error())
+		}
+		n++
+	}
+	if height != 0 {
+		if err := commitBlock(w, height); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,85 @@
+package msgqueue
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, r RecordReader) []Record {
+	var recs []Record
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return recs
+		}
+		require.NoError(t, err)
+		recs = append(recs, rec)
+	}
+}
+
+func TestStreamRecordReader(t *testing.T) {
+	r := NewRecordReader(strings.NewReader("k0#v0\r\n" +
+		"k1#{\"a\":\r\n1,\"height\":7}\r\n" +
+		"k2#plain\r\n" +
+		"k3#{\"block_height\":8}\r\n"))
+	recs := readAll(t, r)
+	require.Equal(t, []Record{
+		{Key: []byte("k0"), Value: []byte("v0"), Height: 0},
+		{Key: []byte("k1"), Value: []byte("{\"a\":\r\n1,\"height\":7}"), Height: 7},
+		{Key: []byte("k2"), Value: []byte("plain"), Height: 7},
+		{Key: []byte("k3"), Value: []byte("{\"block_height\":8}"), Height: 8},
+	}, recs)
+	require.NoError(t, r.Close())
+
+	// the torn records at the end
+	r = NewRecordReader(strings.NewReader("k0#v0\r\nk1#v"))
+	_, err := r.Next()
+	require.NoError(t, err)
+	_, err = r.Next()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	r = NewRecordReader(strings.NewReader("k0"))
+	_, err = r.Next()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestReplayDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w, err := createMsgWriter("dir:" + dir + "?rotate_blocks=2&compress=gzip&max_size=1048576")
+	require.NoError(t, err)
+	for h := int64(1); h <= 5; h++ {
+		require.NoError(t, w.WriteKV([]byte("k"), []byte(fmt.Sprintf(`{"height":%d}`, h))))
+		// the record without a height belongs to the block of the record before it
+		require.NoError(t, w.WriteKV([]byte("x"), []byte("no-height")))
+		require.NoError(t, commitBlock(w, h))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := OpenRecords(dir, 0)
+	require.NoError(t, err)
+	recs := readAll(t, r)
+	require.Len(t, recs, 10)
+	require.EqualValues(t, 5, recs[9].Height)
+	require.Equal(t, "no-height", string(recs[9].Value))
+
+	// the first segment is skipped by the index
+	r, err = OpenRecordDir(dir, 3)
+	require.NoError(t, err)
+	require.Len(t, r.(*dirRecordReader).segments, 2)
+	r = SeekHeights(r, 3, 4)
+	fw := &flakyMsgWriter{}
+	n, err := Replay(r, fw)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, 4, n)
+	require.Equal(t, []string{`k#{"height":3}`, "x#no-height", `k#{"height":4}`, "x#no-height"}, fw.written())
+	require.Equal(t, []int64{3, 4}, fw.committed())
+}
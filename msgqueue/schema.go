@@ -0,0 +1,155 @@
+package msgqueue
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// the coins have their own JSON encoding only to marshal nil as an empty array
+	coinsType    = reflect.TypeOf(sdk.Coins{})
+	decCoinsType = reflect.TypeOf(sdk.DecCoins{})
+)
+
+// JSONSchema describes the payloads of this type, which are validated against the schema
+// inside the envelopes' "payload" field
+func (pt PayloadType) JSONSchema() map[string]interface{} {
+	schema := JSONSchemaOf(pt.Type)
+	schema["$schema"] = JSONSchemaDraft
+	schema["$id"] = fmt.Sprintf("%s/%s/v%d", pt.Module, pt.Key, pt.Version)
+	schema["title"] = pt.Key
+	return schema
+}
+
+// EnvelopeJSONSchema describes the envelopes, whose payloads may be of any registered type
+func EnvelopeJSONSchema() map[string]interface{} {
+	schema := JSONSchemaOf(reflect.TypeOf(Envelope{}))
+	schema["$schema"] = JSONSchemaDraft
+	schema["$id"] = "envelope"
+	schema["title"] = "envelope"
+	return schema
+}
+
+// JSONSchemaOf generates the JSON Schema of a Go type according to how encoding/json marshals it.
+// Except the coins, the types with their own JSON encoding, such as sdk.Dec, sdk.Int and sdk.AccAddress,
+// are strings.
+func JSONSchemaOf(t reflect.Type) map[string]interface{} {
+	return jsonSchemaOf(t, make(map[reflect.Type]bool))
+}
+
+func jsonSchemaOf(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return map[string]interface{}{}
+	}
+	if t == coinsType || t == decCoinsType {
+		return map[string]interface{}{"type": "array", "items": jsonSchemaOf(t.Elem(), visiting)}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return map[string]interface{}{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Ptr:
+		return nullable(jsonSchemaOf(t.Elem(), visiting))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(map[string]interface{}{"type": "string", "contentEncoding": "base64"})
+		}
+		return nullable(map[string]interface{}{"type": "array", "items": jsonSchemaOf(t.Elem(), visiting)})
+	case reflect.Array:
+		return map[string]interface{}{
+			"type":     "array",
+			"items":    jsonSchemaOf(t.Elem(), visiting),
+			"minItems": t.Len(),
+			"maxItems": t.Len(),
+		}
+	case reflect.Map:
+		return nullable(map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": jsonSchemaOf(t.Elem(), visiting),
+		})
+	case reflect.Struct:
+		if visiting[t] {
+			// a recursive type is not described any further
+			return map[string]interface{}{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+		addStructFields(t, visiting, properties, &required)
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		}
+	default:
+		// interfaces may hold any value
+		return map[string]interface{}{}
+	}
+}
+
+func addStructFields(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructFields(ft, visiting, properties, required)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := jsonSchemaOf(field.Type, visiting)
+		if strings.Contains(opts, "string") {
+			schema = map[string]interface{}{"type": "string"}
+		}
+		properties[name] = schema
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// nil pointers, slices and maps are marshaled as null
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+	}
+	return schema
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/coinexchain/cet-sdk/msgqueue"
+
+	_ "github.com/coinexchain/cet-sdk/modules/authx"
+	_ "github.com/coinexchain/cet-sdk/modules/bancorlite"
+	_ "github.com/coinexchain/cet-sdk/modules/bankx"
+	_ "github.com/coinexchain/cet-sdk/modules/comment"
+	_ "github.com/coinexchain/cet-sdk/modules/market"
+)
+
+// schemagen generates the JSON Schemas of the envelope and all the registered payloads from their
+// Go types, such that the consumers of the message queue can validate the messages:
+//
+//	schemagen -out ./schemas
+//
+// The envelope is written to envelope.json, and each payload to <module>.<key>.v<version>.json.
+// The protobuf and Avro schemas of each payload are written to <module>.<key>.v<version>.proto and
+// <module>.<key>.v<version>.avsc, for the writers configured with "?format=protobuf" or "?format=avro".
+// The envelopes encoded by these writers, with the encoded payloads inside, are described by
+// envelope.proto and envelope.avsc.
+//
+// The schemas should be kept under version control. schemagen fails if the schema of a payload version
+// in the directory differs from the generated one, for the consumers can not decode the payloads of
+// the same version with two schemas, so the version of a payload must be increased when it is changed.
+func main() {
+	outDir := flag.String("out", "schemas", "the directory to write the schemas to")
+	flag.Parse()
+
+	if err := writeSchemas(*outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func writeSchemas(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := writeSchema(filepath.Join(dir, "envelope.json"), msgqueue.EnvelopeJSONSchema()); err != nil {
+		return err
+	}
+	envelopeAvro, err := marshalSchema(msgqueue.EnvelopeAvroSchema())
+	if err != nil {
+		return err
+	}
+	if err := writeCheckedSchemas(dir, map[string][]byte{
+		"envelope.proto": []byte(msgqueue.EnvelopeProtoSchema()),
+		"envelope.avsc":  envelopeAvro,
+	}); err != nil {
+		return err
+	}
+	for _, pt := range msgqueue.GetPayloadTypes() {
+		name := fmt.Sprintf("%s.%s.v%d", pt.Module, pt.Key, pt.Version)
+		jsonSchema, err := marshalSchema(pt.JSONSchema())
+		if err != nil {
+			return err
+		}
+		avroSchema, err := marshalSchema(pt.AvroSchema())
+		if err != nil {
+			return err
+		}
+		if err := writeCheckedSchemas(dir, map[string][]byte{
+			name + ".json":  jsonSchema,
+			name + ".avsc":  avroSchema,
+			name + ".proto": []byte(pt.ProtoSchema()),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCheckedSchemas writes the schemas if none of them is changed
+func writeCheckedSchemas(dir string, schemas map[string][]byte) error {
+	for file, bz := range schemas {
+		if err := checkSchema(filepath.Join(dir, file), bz); err != nil {
+			return err
+		}
+	}
+	for file, bz := range schemas {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), bz, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSchema returns an error if the schema at path exists and is different from bz
+func checkSchema(path string, bz []byte) error {
+	old, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !bytes.Equal(old, bz) {
+		return fmt.Errorf("the schema in %s is changed, increase the version of the payload", path)
+	}
+	return nil
+}
+
+func writeSchema(path string, schema map[string]interface{}) error {
+	bz, err := marshalSchema(schema)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bz, 0644)
+}
+
+func marshalSchema(schema map[string]interface{}) ([]byte, error) {
+	bz, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(bz, '\n'), nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinexchain/cet-sdk/msgqueue"
+)
+
+func TestRegisteredPayloads(t *testing.T) {
+	modules := make(map[string]string)
+	for _, pt := range msgqueue.GetPayloadTypes() {
+		modules[pt.Key] = pt.Module
+	}
+	require.Equal(t, map[string]string{
+		"create_market_info": "market",
+		"create_order_info":  "market",
+		"fill_order_info":    "market",
+		"del_order_info":     "market",
+		"trigger_order_info": "market",
+		"modify_order_info":  "market",
+		"market_status_info": "market",
+		"bancor_create":      "bancorlite",
+		"bancor_trade":       "bancorlite",
+		"bancor_info":        "bancorlite",
+		"send_lock_coins":    "bankx",
+		"notify_unlock":      "authx",
+		"token_comment":      "comment",
+	}, modules)
+}
+
+func TestWriteSchemas(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schemagen")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, writeSchemas(dir))
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, 3*len(msgqueue.GetPayloadTypes())+3, len(files))
+
+	bz, err := ioutil.ReadFile(filepath.Join(dir, "market.fill_order_info.v1.json"))
+	require.NoError(t, err)
+	var schema struct {
+		ID         string                     `json:"$id"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(bz, &schema))
+	require.Equal(t, "market/fill_order_info/v1", schema.ID)
+	require.JSONEq(t, `{"type":"string"}`, string(schema.Properties["price"]))
+	require.JSONEq(t, `{"type":"integer"}`, string(schema.Properties["deal_stock"]))
+
+	bz, err = ioutil.ReadFile(filepath.Join(dir, "market.fill_order_info.v1.proto"))
+	require.NoError(t, err)
+	require.Contains(t, string(bz), "package coinexchain.market.v1;")
+	require.Contains(t, string(bz), "message FillOrderInfo {")
+
+	bz, err = ioutil.ReadFile(filepath.Join(dir, "market.fill_order_info.v1.avsc"))
+	require.NoError(t, err)
+	var avro struct {
+		Name   string `json:"name"`
+		Fields []struct {
+			Name string      `json:"name"`
+			Type interface{} `json:"type"`
+		} `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal(bz, &avro))
+	require.Equal(t, "FillOrderInfo", avro.Name)
+	for _, f := range avro.Fields {
+		if f.Name == "deal_stock" {
+			require.Equal(t, "long", f.Type)
+		}
+	}
+}
+
+func TestChangedSchemas(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schemagen")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the unchanged schemas can be generated again
+	require.NoError(t, writeSchemas(dir))
+	require.NoError(t, writeSchemas(dir))
+
+	path := filepath.Join(dir, "market.fill_order_info.v1.proto")
+	bz, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	changed := strings.Replace(string(bz), "= 1;", "= 10;", 1)
+	require.NotEqual(t, string(bz), changed)
+	require.NoError(t, ioutil.WriteFile(path, []byte(changed), 0644))
+	err = writeSchemas(dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "increase the version")
+	// the old schema is not overwritten
+	bz, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, changed, string(bz))
+}
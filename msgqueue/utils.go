@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
@@ -16,44 +18,91 @@ func GetFileName(dir string, fileIndex int) string {
 }
 
 func GetFilePathAndFileIndexFromDir(dir string, maxFileSize int) (filePath string, fileIndex int, err error) {
-	files, err := ioutil.ReadDir(dir)
+	segments, err := listSegments(dir)
 	if os.IsNotExist(err) {
 		if err := os.Mkdir(dir, os.ModePerm); err != nil {
 			return "", -1, err
 		}
 		return GetFileName(dir, 0), 0, nil
+	} else if err != nil {
+		return "", -1, err
+	}
+	if len(segments) == 0 {
+		return GetFileName(dir, 0), 0, nil
 	}
 
-	fileNames := make([]string, 0, len(files))
-	for _, file := range files {
-		if !file.IsDir() {
-			name := file.Name()
-			if strings.HasPrefix(name, filePrefix) {
-				fileNames = append(fileNames, name)
-			}
+	// the compressed segment is closed
+	last := segments[len(segments)-1]
+	if last.Compression == "" && GetFileSize(GetFileName(dir, last.Index)) < maxFileSize {
+		return GetFileName(dir, last.Index), last.Index, nil
+	}
+	return GetFileName(dir, last.Index+1), last.Index + 1, nil
+}
+
+// segmentFile is a segment file written by the dir writer
+type segmentFile struct {
+	Index       int
+	Name        string
+	Compression string
+	Size        int64
+	ModTime     time.Time
+}
+
+// parseSegmentName parses the names like "backup-3", "backup-3.gz" and "backup-3.zst"
+func parseSegmentName(name string) (index int, compression string, ok bool) {
+	if !strings.HasPrefix(name, filePrefix) {
+		return 0, "", false
+	}
+	name = strings.TrimPrefix(name, filePrefix)
+	for c, suffix := range compressionSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			name, compression = strings.TrimSuffix(name, suffix), c
+			break
 		}
 	}
-	if len(fileNames) == 0 {
-		return GetFileName(dir, 0), 0, nil
+	index, err := strconv.Atoi(name)
+	if err != nil || index < 0 {
+		return 0, "", false
 	}
+	return index, compression, true
+}
 
-	fileIndex = 0
-	for _, fileName := range fileNames {
-		vals := strings.Split(fileName, "-")
-		if len(vals) == 2 {
-			if index, err := strconv.Atoi(vals[1]); err == nil {
-				if index > fileIndex {
-					fileIndex = index
-				}
-			}
+// listSegments returns the segment files in the dir in the order of their indexes. If a segment is
+// both compressed and not, which happens when the node crashes while compressing it, only the one
+// not compressed is returned.
+func listSegments(dir string) ([]segmentFile, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	byIndex := make(map[int]segmentFile)
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		index, compression, ok := parseSegmentName(file.Name())
+		if !ok {
+			continue
+		}
+		if old, ok := byIndex[index]; ok && old.Compression == "" {
+			continue
+		}
+		byIndex[index] = segmentFile{
+			Index:       index,
+			Name:        file.Name(),
+			Compression: compression,
+			Size:        file.Size(),
+			ModTime:     file.ModTime(),
 		}
 	}
-
-	fileSize := GetFileSize(GetFileName(dir, fileIndex))
-	if fileSize < maxFileSize {
-		return GetFileName(dir, fileIndex), fileIndex, nil
+	segments := make([]segmentFile, 0, len(byIndex))
+	for _, s := range byIndex {
+		segments = append(segments, s)
 	}
-	return GetFileName(dir, fileIndex+1), fileIndex + 1, nil
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].Index < segments[j].Index
+	})
+	return segments, nil
 }
 
 func GetFileSize(filePath string) int {
@@ -3,10 +3,23 @@ package msgqueue
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
+// waitUntil polls the condition in the calling goroutine. require.Eventually of testify v1.4.0 is
+// not used, as it panics if a check finishes after another one has succeeded.
+func waitUntil(t *testing.T, condition func() bool, waitFor time.Duration) {
+	deadline := time.Now().Add(waitFor)
+	for !condition() {
+		if time.Now().After(deadline) {
+			require.FailNow(t, "condition never satisfied")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func TestGetFileSize(t *testing.T) {
 	filePath := "tmp.out"
 	require.EqualValues(t, -1, GetFileSize(filePath))
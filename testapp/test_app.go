@@ -98,6 +98,9 @@ type TestApp struct {
 	MsgQueProducer  msgqueue.MsgSender
 	AliasKeeper     alias.Keeper
 	CommentKeeper   comment.Keeper
+
+	// the index of the next message sent in the current block
+	msgIndex int
 }
 
 func NewTestApp() *TestApp {
@@ -379,6 +382,21 @@ func (app *TestApp) mountStores() {
 	app.Cms = cms
 }
 
+// SendMsgQueueEvents sends the messages in the events of a transaction, or of BeginBlock and EndBlock
+// if txHash is empty, in envelopes like the app does
+func (app *TestApp) SendMsgQueueEvents(ctx sdk.Context, txHash string) {
+	events := ctx.EventManager().Events().ToABCIEvents()
+	app.msgIndex = msgqueue.SendEvents(app.MsgQueProducer, events, ctx.BlockHeight(), txHash, app.msgIndex)
+}
+
+// Commit commits the stores like the Commit of the app, and then tells the message producer that the
+// block is committed, which must be done by every app for the messages to be written with the outbox
+func (app *TestApp) Commit() {
+	id := app.Cms.(store.CommitMultiStore).Commit()
+	app.MsgQueProducer.CommitBlock(id.Version)
+	app.msgIndex = 0
+}
+
 func (app *TestApp) NewCtx() sdk.Context {
 	return sdk.NewContext(app.Cms,
 		abci.Header{ChainID: "test-chain-id", Time: time.Now()},
@@ -0,0 +1,61 @@
+package testapp_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinexchain/cet-sdk/msgqueue"
+	"github.com/coinexchain/cet-sdk/testapp"
+)
+
+func TestCommitWritesMessages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testapp")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	msgFile := filepath.Join(dir, "messages.txt")
+
+	viper.Set(msgqueue.FlagBrokers, []string{"file:" + msgFile})
+	viper.Set(msgqueue.FlagTopics, "bankx")
+	viper.Set(msgqueue.FlagFeatureToggle, true)
+	viper.Set(msgqueue.FlagOutboxDir, filepath.Join(dir, "outbox"))
+	defer viper.Reset()
+
+	app := testapp.NewTestApp()
+	defer app.MsgQueProducer.Close()
+	ctx := app.NewCtx().WithBlockHeight(1)
+	msgqueue.FillMsgs(ctx, "send_lock_coins", map[string]int64{"unlock_time": 100})
+	msgqueue.FillMsgs(ctx, "unknown", map[string]int64{})
+	app.SendMsgQueueEvents(ctx, "ABCD")
+	require.Equal(t, 0, msgqueue.GetFileSize(msgFile))
+
+	// the messages of the block are written in envelopes after it is committed
+	app.Commit()
+	var lines []string
+	deadline := time.Now().Add(5 * time.Second)
+	for len(lines) < 2 {
+		require.True(t, time.Now().Before(deadline), "the messages are not written")
+		time.Sleep(10 * time.Millisecond)
+		data, err := ioutil.ReadFile(msgFile)
+		if err == nil && strings.Count(string(data), "\r\n") == 2 {
+			lines = strings.Split(strings.TrimSuffix(string(data), "\r\n"), "\r\n")
+		}
+	}
+	kv := strings.SplitN(lines[0], "#", 2)
+	require.Equal(t, "send_lock_coins", kv[0])
+	env, err := msgqueue.ParseEnvelope([]byte(kv[1]))
+	require.NoError(t, err)
+	require.Equal(t, msgqueue.Envelope{SchemaVersion: 1, Module: "bankx", Type: "send_lock_coins", Height: 1,
+		TxHash: "ABCD", Index: 0, Payload: []byte(`{"unlock_time":100}`)}, env)
+	kv = strings.SplitN(lines[1], "#", 2)
+	env, err = msgqueue.ParseEnvelope([]byte(kv[1]))
+	require.NoError(t, err)
+	require.Equal(t, "unknown", env.Type)
+	require.Equal(t, 1, env.Index)
+}